@@ -2,11 +2,17 @@ package analyzer
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"golang.org/x/xerrors"
 
+	aos "github.com/knqyf263/fanal/analyzer/os"
 	"github.com/knqyf263/fanal/extractor"
 	"github.com/knqyf263/go-dep-parser/pkg/types"
 	"github.com/pkg/errors"
@@ -16,13 +22,40 @@ var (
 	osAnalyzers  []OSAnalyzer
 	pkgAnalyzers []PkgAnalyzer
 	libAnalyzers []LibraryAnalyzer
+	srcAnalyzers []SourceAnalyzer
+	middlewares  []Middleware
+
+	// ErrNoAnalyzerMatch occurs when no analyzer detects the target.
+	ErrNoAnalyzerMatch = xerrors.New("no analyzer matched")
+	// ErrAnalyzerPanic occurs when an analyzer panics.
+	ErrAnalyzerPanic = xerrors.New("analyzer panicked")
+	// ErrMalformedFile occurs when an analyzer detects the target but fails to parse the file.
+	ErrMalformedFile = xerrors.New("malformed file")
+	// ErrPluginNotSupported occurs when analyzer plugins are loaded in the build without cgo.
+	ErrPluginNotSupported = xerrors.New("analyzer plugins are not supported without cgo")
 
 	// ErrUnknownOS occurs when unknown OS is analyzed.
-	ErrUnknownOS = errors.New("Unknown OS")
+	// It is kept for backward compatibility and is the same as ErrNoAnalyzerMatch.
+	ErrUnknownOS = ErrNoAnalyzerMatch
 	// ErrPkgAnalysis occurs when the analysis of packages is failed.
 	ErrPkgAnalysis = errors.New("Failed to analyze packages")
 )
 
+// AnalyzerError is returned when an analyzer fails. Cause wraps one of the sentinel errors above
+// when the reason is known, so it can be checked with xerrors.Is.
+type AnalyzerError struct {
+	AnalyzerName string
+	Cause        error
+}
+
+func (e *AnalyzerError) Error() string {
+	return fmt.Sprintf("%s: %s", e.AnalyzerName, e.Cause)
+}
+
+func (e *AnalyzerError) Unwrap() error {
+	return e.Cause
+}
+
 type OSAnalyzer interface {
 	Analyze(extractor.FileMap) (OS, error)
 	RequiredFiles() []string
@@ -40,6 +73,58 @@ type LibraryAnalyzer interface {
 	RequiredFiles() []string
 }
 
+// LibraryFinding is a library with how reliably it was identified.
+type LibraryFinding struct {
+	Library types.Library
+	// Confidence is between 0 and 1. Libraries in lockfiles have 1.
+	Confidence float64
+	// Source is the parsing method such as "pom.properties", "MANIFEST.MF" and "filename".
+	Source string
+	// Dev is true for development and test dependencies, e.g. "dev": true in package-lock.json.
+	Dev bool
+	// ID is referred to by DependsOn, e.g. "express@4.17.1".
+	// It is empty when the analyzer doesn't know the dependency graph, and then Direct and DependsOn are not set.
+	ID string
+	// Direct is true for the dependencies of the project itself.
+	Direct bool
+	// DependsOn is the sorted IDs of the libraries this library depends on.
+	DependsOn []string
+	// Revision is the commit pinned by a dependency on a VCS repository, e.g. a git dependency in Cargo.lock.
+	// The version is the version of the library declared in the repository.
+	Revision string
+}
+
+// LibraryFindingAnalyzer is implemented by library analyzers relying on heuristics
+// or telling dev dependencies.
+// GetLibraryFindings calls AnalyzeFindings instead of Analyze.
+type LibraryFindingAnalyzer interface {
+	LibraryAnalyzer
+	AnalyzeFindings(extractor.FileMap) (map[FilePath][]LibraryFinding, error)
+}
+
+// PinningWarning is a dependency declared without pinning the version, e.g. "requests>=2.0" in requirements.txt.
+// Such a dependency may be silently updated when the image is rebuilt.
+type PinningWarning struct {
+	FilePath   string
+	Dependency string
+	// Constraint is the version constraint as declared. It is empty when any version is allowed.
+	Constraint string
+}
+
+// PinningAnalyzer is implemented by library analyzers parsing files which are not lockfiles,
+// e.g. requirements.txt and package.json.
+// Files which can't be parsed are reported by Analyze, so AnalyzePinning skips them.
+type PinningAnalyzer interface {
+	LibraryAnalyzer
+	AnalyzePinning(extractor.FileMap) []PinningWarning
+}
+
+// SourceAnalyzer maps binary package names to the source packages they are built from.
+type SourceAnalyzer interface {
+	AnalyzeSource(extractor.FileMap) (map[string]SrcPackage, error)
+	RequiredFiles() []string
+}
+
 type OS struct {
 	Name   string
 	Family string
@@ -50,12 +135,43 @@ type Package struct {
 	Version string
 	Release string
 	Epoch   int
+	Arch    string
 	Type    string
+	// InstalledAt is nil if the analyzer can't determine when the package was installed.
+	InstalledAt *time.Time
+	// License is the license as declared in the package metadata. See NormalizeLicense for SPDX.
+	License string
+	// Homepage is the upstream URL stored verbatim, e.g. for SBOMs and linking findings to upstream projects.
+	Homepage string
+	// Maintainer is who built the package, e.g. to distinguish third-party rebuilds from the distribution.
+	Maintainer string
+	// Scripts is the maintainer scripts keyed by "prein", "postin", "preun" and "postun".
+	// Only non-empty scripts are stored, and it is nil if the analyzer doesn't support them.
+	Scripts map[string]string
+	// ConfigFiles is the absolute paths of the config files owned by the package, e.g. "/etc/bash.bashrc".
+	// It is nil if the analyzer doesn't support them.
+	ConfigFiles []string
+}
+
+// VersionString returns the version in the form of "<epoch>:<version>-<release>".
+// The epoch is omitted when it is zero, and the release is omitted when it is empty.
+func (p Package) VersionString() string {
+	v := p.Version
+	if p.Release != "" {
+		v = fmt.Sprintf("%s-%s", v, p.Release)
+	}
+	if p.Epoch > 0 {
+		v = fmt.Sprintf("%d:%s", p.Epoch, v)
+	}
+	return v
 }
 
 var (
-	TypeBinary = "binary"
-	TypeSource = "source"
+	TypeBinary  = "binary"
+	TypeSource  = "source"
+	TypeSnap    = "snap"
+	TypeFlatpak = "flatpak"
+	TypeAppx    = "appx"
 )
 
 type SrcPackage struct {
@@ -64,6 +180,42 @@ type SrcPackage struct {
 	BinaryNames []string `json:"binaryNames"`
 }
 
+// AnalyzeFunc is the Analyze method of an analyzer.
+type AnalyzeFunc func(extractor.FileMap) (interface{}, error)
+
+// Middleware wraps every analyzer call, e.g. for logging and metrics.
+// name is the type name of the analyzer such as "alpine.alpineOSAnalyzer".
+type Middleware func(name string, next AnalyzeFunc) AnalyzeFunc
+
+// RegisterMiddleware adds the middleware to the chain.
+// The middleware registered first is the outermost.
+func RegisterMiddleware(m Middleware) {
+	middlewares = append(middlewares, m)
+}
+
+// runAnalyzer runs the analyzer through the middlewares. A panic of the analyzer or the middlewares is recovered
+// and returned as an AnalyzerError wrapping ErrAnalyzerPanic, which is also appended to panics unless nil.
+func runAnalyzer(analyzer interface{}, fn AnalyzeFunc, filesMap extractor.FileMap, panics *[]AnalyzerError) (result interface{}, err error) {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", analyzer), "*")
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		fn = middlewares[i](name, fn)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			aErr := AnalyzerError{AnalyzerName: name, Cause: xerrors.Errorf("panic: %v: %w", r, ErrAnalyzerPanic)}
+			if panics != nil {
+				*panics = append(*panics, aErr)
+			}
+			result, err = nil, &aErr
+		}
+	}()
+	result, err = fn(filesMap)
+	if err != nil {
+		return nil, &AnalyzerError{AnalyzerName: name, Cause: err}
+	}
+	return result, nil
+}
+
 func RegisterOSAnalyzer(analyzer OSAnalyzer) {
 	osAnalyzers = append(osAnalyzers, analyzer)
 }
@@ -76,76 +228,776 @@ func RegisterLibraryAnalyzer(analyzer LibraryAnalyzer) {
 	libAnalyzers = append(libAnalyzers, analyzer)
 }
 
+func RegisterSourceAnalyzer(analyzer SourceAnalyzer) {
+	srcAnalyzers = append(srcAnalyzers, analyzer)
+}
+
+// Types of analyzers for RequiredFilenamesFor
+const (
+	AnalyzerTypeOS      = "os"
+	AnalyzerTypePkg     = "pkg"
+	AnalyzerTypeLibrary = "library"
+	AnalyzerTypeSource  = "source"
+)
+
+// DirAnalyzer is implemented by analyzers requiring all the files under directories in addition to RequiredFiles,
+// e.g. var/lib/pacman/local of pacman, so that they don't have to know the pattern syntax of the extractor.
+type DirAnalyzer interface {
+	RequiredDirs() []string
+}
+
+// FileSizeLimiter is implemented by analyzers skipping the large files, e.g. Java archives, which are not
+// extracted not to hold them in memory. The limits are given to the extractor as DockerOption.FileSizeLimits.
+type FileSizeLimiter interface {
+	FileSizeLimits() map[string]int64
+}
+
+// fileSizeLimits returns FileSizeLimits of all the analyzers, where the smaller limit wins for the same pattern.
+func fileSizeLimits() map[string]int64 {
+	limits := map[string]int64{}
+	for _, analyzerType := range []string{AnalyzerTypeOS, AnalyzerTypePkg, AnalyzerTypeLibrary, AnalyzerTypeSource} {
+		for _, a := range analyzersOf(analyzerType) {
+			l, ok := a.(FileSizeLimiter)
+			if !ok {
+				continue
+			}
+			for pattern, size := range l.FileSizeLimits() {
+				if max, ok := limits[pattern]; !ok || size < max {
+					limits[pattern] = size
+				}
+			}
+		}
+	}
+	return limits
+}
+
+// requirements is the files required by analyzers, which are given to the extractor as the patterns.
+type requirements struct {
+	// filenames are the paths and the file names without wildcards, e.g. etc/os-release and Gemfile.lock
+	filenames []string
+	// globs are the patterns with wildcards, e.g. **/*.deps.json
+	globs []string
+	// dirs are the directories whose files are all required, e.g. var/lib/pacman/local
+	dirs []string
+}
+
+// add adds RequiredFiles of the analyzer, and RequiredDirs if it is a DirAnalyzer.
+// The required files ending with "/" are the directories as well.
+func (r *requirements) add(a interface{ RequiredFiles() []string }) {
+	for _, filename := range a.RequiredFiles() {
+		switch {
+		case strings.HasSuffix(filename, "/"):
+			r.dirs = append(r.dirs, strings.TrimSuffix(filename, "/"))
+		case strings.ContainsAny(filename, `*?[`):
+			r.globs = append(r.globs, filename)
+		default:
+			r.filenames = append(r.filenames, filename)
+		}
+	}
+	if d, ok := a.(DirAnalyzer); ok {
+		for _, dir := range d.RequiredDirs() {
+			r.dirs = append(r.dirs, strings.TrimSuffix(dir, "/"))
+		}
+	}
+}
+
+// patterns returns the patterns of the extractor without duplicates, where the directories end with "/".
+// See extractor.Match.
+func (r requirements) patterns() []string {
+	patterns := append(append([]string{}, r.filenames...), r.globs...)
+	for _, dir := range r.dirs {
+		patterns = append(patterns, dir+"/")
+	}
+	return uniqueStrings(patterns)
+}
+
+// RequiredFilenames returns the patterns of the files required by all the analyzers without duplicates,
+// the file names followed by the globs and the directories ending with "/" in the order of first appearance.
 func RequiredFilenames() []string {
-	filenames := []string{}
-	for _, analyzer := range osAnalyzers {
-		filenames = append(filenames, analyzer.RequiredFiles()...)
+	var r requirements
+	for _, analyzerType := range []string{AnalyzerTypeOS, AnalyzerTypePkg, AnalyzerTypeLibrary, AnalyzerTypeSource} {
+		for _, a := range analyzersOf(analyzerType) {
+			r.add(a)
+		}
 	}
-	for _, analyzer := range pkgAnalyzers {
-		filenames = append(filenames, analyzer.RequiredFiles()...)
+	return r.patterns()
+}
+
+// RequiredFilenamesFor returns the patterns of the files required by the analyzers of the type,
+// e.g. AnalyzerTypeOS, in the same way as RequiredFilenames. nil is returned for an unknown type.
+func RequiredFilenamesFor(analyzerType string) []string {
+	analyzers := analyzersOf(analyzerType)
+	if analyzers == nil {
+		return nil
 	}
-	for _, analyzer := range libAnalyzers {
-		filenames = append(filenames, analyzer.RequiredFiles()...)
+	var r requirements
+	for _, a := range analyzers {
+		r.add(a)
+	}
+	return r.patterns()
+}
+
+// analyzersOf returns the analyzers of the type. nil is returned for an unknown type.
+func analyzersOf(analyzerType string) []interface{ RequiredFiles() []string } {
+	analyzers := []interface{ RequiredFiles() []string }{}
+	switch analyzerType {
+	case AnalyzerTypeOS:
+		for _, a := range osAnalyzers {
+			analyzers = append(analyzers, a)
+		}
+	case AnalyzerTypePkg:
+		for _, a := range pkgAnalyzers {
+			analyzers = append(analyzers, a)
+		}
+	case AnalyzerTypeLibrary:
+		for _, a := range libAnalyzers {
+			analyzers = append(analyzers, a)
+		}
+	case AnalyzerTypeSource:
+		for _, a := range srcAnalyzers {
+			analyzers = append(analyzers, a)
+		}
+	default:
+		return nil
 	}
-	return filenames
+	return analyzers
+}
+
+func uniqueStrings(ss []string) []string {
+	uniq := []string{}
+	seen := map[string]struct{}{}
+	for _, s := range ss {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		uniq = append(uniq, s)
+	}
+	return uniq
+}
+
+// DefaultExcludedPaths are vendored dependency trees which would be reported as separate applications.
+var DefaultExcludedPaths = []string{"**/node_modules/**", "**/vendor/bundle/**", "**/go/pkg/mod/**"}
+
+var excludedPaths = DefaultExcludedPaths
+
+// SetPathFilters replaces the globs of the paths excluded from the library analysis.
+// They are applied to the extraction in Analyze and AnalyzeFromFile as well. nil disables the exclusion.
+// Files required by a path pattern such as "**/node_modules/*/package.json" are not excluded.
+func SetPathFilters(excludes []string) {
+	excludedPaths = excludes
 }
 
 func Analyze(ctx context.Context, imageName string) (filesMap extractor.FileMap, err error) {
-	e := extractor.NewDockerExtractor(extractor.DockerOption{Timeout: 600 * time.Second})
-	filesMap, err = e.Extract(ctx, imageName, RequiredFilenames())
+	filesMap, _, err = AnalyzeWithMetadata(ctx, imageName)
+	return filesMap, err
+}
+
+// AnalyzeWithMetadata is the same as Analyze but also returns the digest, the ID and the diff IDs of the image
+// which was extracted, e.g. to record exactly which image "nginx:latest" was.
+func AnalyzeWithMetadata(ctx context.Context, imageName string) (extractor.FileMap, extractor.ImageMetadata, error) {
+	e := extractor.NewDockerExtractor(extractor.WithTimeout(600*time.Second), extractor.WithExcludedPaths(excludedPaths),
+		extractor.WithFileSizeLimits(fileSizeLimits()))
+	filesMap, metadata, err := e.ExtractWithMetadata(ctx, imageName, RequiredFilenames())
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to extract files")
+		return nil, extractor.ImageMetadata{}, errors.Wrap(err, "Failed to extract files")
 	}
-	return filesMap, nil
+	return filesMap, metadata, nil
 }
 
 func AnalyzeFromFile(ctx context.Context, r io.ReadCloser) (filesMap extractor.FileMap, err error) {
-	e := extractor.NewDockerExtractor(extractor.DockerOption{})
-	filesMap, err = e.ExtractFromFile(ctx, r, RequiredFilenames())
+	filesMap, _, err = AnalyzeFromFileWithMetadata(ctx, r)
+	return filesMap, err
+}
+
+// AnalyzeFromFileWithMetadata is the same as AnalyzeFromFile but also returns the ID and the diff IDs of the image.
+func AnalyzeFromFileWithMetadata(ctx context.Context, r io.ReadCloser) (extractor.FileMap, extractor.ImageMetadata, error) {
+	e := extractor.NewDockerExtractor(extractor.WithExcludedPaths(excludedPaths), extractor.WithFileSizeLimits(fileSizeLimits()))
+	filesMap, metadata, err := e.ExtractFromFileWithMetadata(ctx, r, RequiredFilenames())
+	if err != nil {
+		return nil, extractor.ImageMetadata{}, errors.Wrap(err, "Failed to extract files")
+	}
+	return filesMap, metadata, nil
+}
+
+// AnalyzeContainer extracts the files from the filesystem of the container in the Docker daemon.
+func AnalyzeContainer(ctx context.Context, containerID string) (filesMap extractor.FileMap, err error) {
+	e := extractor.NewDockerExtractor(extractor.WithExcludedPaths(excludedPaths), extractor.WithFileSizeLimits(fileSizeLimits()))
+	filesMap, err = e.ExtractFromContainer(ctx, containerID, RequiredFilenames())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to extract files: %w", err)
+	}
+	return filesMap, nil
+}
+
+// AnalyzeLocalFS extracts the files from the root filesystem on the local disk, e.g. "/" for the host.
+func AnalyzeLocalFS(ctx context.Context, root string) (filesMap extractor.FileMap, err error) {
+	e := extractor.NewLocalFSExtractor(root)
+	e.Option.ExcludedPaths = excludedPaths
+	e.Option.FileSizeLimits = fileSizeLimits()
+	filesMap, err = e.Extract(ctx, "", RequiredFilenames())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to extract files: %w", err)
+	}
+	return filesMap, nil
+}
+
+// AnalyzeFromOCILayout extracts the files from the OCI image layout directory.
+// ref is the reference name such as "latest", which can be empty if the layout has only one image.
+func AnalyzeFromOCILayout(ctx context.Context, dir, ref string) (filesMap extractor.FileMap, err error) {
+	e := extractor.NewDockerExtractor(extractor.WithExcludedPaths(excludedPaths), extractor.WithFileSizeLimits(fileSizeLimits()))
+	filesMap, err = e.ExtractFromOCILayout(ctx, dir, ref, RequiredFilenames())
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to extract files")
+		return nil, xerrors.Errorf("failed to extract files: %w", err)
 	}
 	return filesMap, nil
 }
 
+// AnalyzeResult is the result of all the analyzers for an image.
+type AnalyzeResult struct {
+	// ImageDigest is the digest of the manifest, e.g. to invalidate cached results when the tag is moved.
+	// It is empty for images which were built locally and never pushed.
+	ImageDigest string
+	// ImageID is the digest of the image config.
+	ImageID string
+	// DiffIDs is the digests of the uncompressed layers from the bottom.
+	DiffIDs []string
+	// Platform is "os/arch[/variant]" of the image selected from the multi-arch image in the registry.
+	Platform  string
+	OS        OS
+	Packages  []Package
+	Libraries map[FilePath][]types.Library
+	// Executables is the regular files with any execute bit, e.g. to detect unexpected binaries in base images.
+	Executables []extractor.ExecutableFile
+	// ScriptFindings is the lines of the package scripts matching DefaultScriptPatterns.
+	ScriptFindings []ScriptFinding
+	// PinningWarnings is the dependencies whose versions are not pinned.
+	PinningWarnings []PinningWarning
+	// LibraryErrors is the errors of the library analyzers which failed, keyed by the analyzer name.
+	LibraryErrors map[string]error
+	// RecoveredPanics is the analyzers which panicked. The other analyzers are not affected.
+	RecoveredPanics []AnalyzerError
+	// IsScratch reports whether the image has no OS files, e.g. static binaries built FROM scratch.
+	IsScratch bool
+	// Layers is the layers from the bottom, e.g. to find what makes the image large.
+	Layers []LayerInfo
+	// TotalCompressedBytes and TotalUncompressedBytes are the total sizes of the layer blobs and the layer tars,
+	// and TotalFileCount is the total number of the entries in the layer tars. The manifest and the config are not
+	// included. They are set even if the analysis fails.
+	TotalCompressedBytes   int64
+	TotalUncompressedBytes int64
+	TotalFileCount         int
+}
+
+// LayerInfo is the size of a layer and the instruction which created it.
+type LayerInfo struct {
+	// Digest is the digest of the layer blob. It is the diff ID for the uncompressed layers of docker save.
+	Digest string
+	// Size is the size of the layer blob, which is compressed in the registry.
+	Size             int64
+	UncompressedSize int64
+	// CreatedBy is the instruction in the image history, e.g. "/bin/sh -c apk add --no-cache curl".
+	CreatedBy string
+}
+
+// layerInfos returns the layers of the image, matching the history entries with a layer in order.
+// CreatedBy is empty if the history is missing or shorter than the layers.
+func layerInfos(metadata extractor.ImageMetadata) []LayerInfo {
+	var history []extractor.HistoryEntry
+	for _, h := range metadata.History {
+		if !h.EmptyLayer {
+			history = append(history, h)
+		}
+	}
+
+	var layers []LayerInfo
+	for _, l := range metadata.Layers {
+		if extractor.IsBuildkitCacheLayer(l) {
+			continue
+		}
+		info := LayerInfo{Digest: l.Digest, Size: l.Size, UncompressedSize: l.UncompressedSize}
+		if i := len(layers); i < len(history) {
+			info.CreatedBy = history[i].CreatedBy
+		}
+		layers = append(layers, info)
+	}
+	return layers
+}
+
+// AnalyzeAll extracts the files and runs all the analyzers.
+// An unknown OS, no packages and panics of analyzers are not regarded as errors.
+// Errors of individual library analyzers are stored in LibraryErrors, even if all of them failed.
+// If the analysis fails after the extraction, the result has only the image metadata, e.g. the sizes of the layers.
+func AnalyzeAll(ctx context.Context, imageName string) (AnalyzeResult, error) {
+	e := extractor.NewDockerExtractor(
+		extractor.WithTimeout(600*time.Second),
+		extractor.WithExcludedPaths(excludedPaths),
+		extractor.WithFileSizeLimits(fileSizeLimits()),
+		extractor.WithCollectExecutables(),
+	)
+	filesMap, metadata, err := e.ExtractWithMetadata(ctx, imageName, RequiredFilenames())
+	if err != nil {
+		return AnalyzeResult{}, xerrors.Errorf("failed to extract files: %w", err)
+	}
+	result, err := analyzeFiles(filesMap)
+	setImageMetadata(&result, metadata)
+	return result, err
+}
+
+// setImageMetadata sets the fields of the result from the image metadata.
+// The blobs of BuildKit are not counted in the totals as they are not layers.
+func setImageMetadata(result *AnalyzeResult, metadata extractor.ImageMetadata) {
+	result.ImageDigest = metadata.Digest
+	result.ImageID = metadata.ID
+	result.DiffIDs = metadata.DiffIDs
+	result.Platform = metadata.Platform
+	result.Executables = metadata.Executables
+	result.Layers = layerInfos(metadata)
+	for _, l := range metadata.Layers {
+		if extractor.IsBuildkitCacheLayer(l) {
+			continue
+		}
+		result.TotalCompressedBytes += l.Size
+		result.TotalUncompressedBytes += l.UncompressedSize
+		result.TotalFileCount += l.FileCount
+	}
+}
+
+// AnalyzeFromDirectory runs all the analyzers on the image filesystem in the directory,
+// e.g. the rootfs built by buildah or umoci before it is packaged, without Docker and registries.
+// ImageDigest, ImageID, DiffIDs and Executables are empty.
+func AnalyzeFromDirectory(ctx context.Context, dir string) (AnalyzeResult, error) {
+	filesMap, err := AnalyzeLocalFS(ctx, dir)
+	if err != nil {
+		return AnalyzeResult{}, err
+	}
+	return analyzeFiles(filesMap)
+}
+
+// analyzeFiles runs all the analyzers on the extracted files.
+func analyzeFiles(filesMap extractor.FileMap) (AnalyzeResult, error) {
+	var result AnalyzeResult
+	var err error
+	result.IsScratch = IsScratchImage(filesMap)
+	result.OS, err = getOS(filesMap, &result.RecoveredPanics)
+	if err != nil && !xerrors.Is(err, ErrNoAnalyzerMatch) && !xerrors.Is(err, ErrAnalyzerPanic) {
+		return AnalyzeResult{}, xerrors.Errorf("failed to analyze OS: %w", err)
+	}
+	result.Packages, err = getPackages(filesMap, &result.RecoveredPanics)
+	if err != nil && !xerrors.Is(err, ErrNoAnalyzerMatch) && !xerrors.Is(err, ErrAnalyzerPanic) {
+		return AnalyzeResult{}, xerrors.Errorf("failed to analyze packages: %w", err)
+	}
+	result.ScriptFindings = ScanPackageScripts(result.Packages, DefaultScriptPatterns)
+	// The failures of the library analyzers are in LibraryErrors even if all of them failed,
+	// so that a malformed lockfile doesn't drop the OS and the packages
+	findings, libErrs, err := getLibraryFindings(filesMap, &result.RecoveredPanics)
+	if err != nil && len(libErrs) == 0 {
+		return AnalyzeResult{}, xerrors.Errorf("failed to analyze libraries: %w", err)
+	}
+	result.Libraries = FindingsToLibraries(findings)
+	result.LibraryErrors = libErrs
+	result.PinningWarnings = getPinningWarnings(filesMap, &result.RecoveredPanics)
+	return result, nil
+}
+
+// IsScratchImage reports whether none of the files is required by the OS and the package analyzers,
+// e.g. images built FROM scratch or distroless images without the package database.
+func IsScratchImage(filesMap extractor.FileMap) bool {
+	patterns := append(RequiredFilenamesFor(AnalyzerTypeOS), RequiredFilenamesFor(AnalyzerTypePkg)...)
+	for _, filePath := range filesMap.Paths() {
+		if extractor.MatchAny(patterns, filePath) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetOS returns the OS detected first.
+// If no analyzer matches, the error of an analyzer which matched but failed is returned if any,
+// OS{Family: "scratch"} for scratch images, otherwise ErrUnknownOS.
+// A panic of an analyzer is recovered and regarded as a failure.
 func GetOS(filesMap extractor.FileMap) (OS, error) {
+	return getOS(filesMap, nil)
+}
+
+func getOS(filesMap extractor.FileMap, panics *[]AnalyzerError) (OS, error) {
+	var failure error
 	for _, analyzer := range osAnalyzers {
-		os, err := analyzer.Analyze(filesMap)
+		analyze := func(fm extractor.FileMap) (interface{}, error) { return analyzer.Analyze(fm) }
+		result, err := runAnalyzer(analyzer, analyze, filesMap, panics)
 		if err != nil {
+			if failure == nil && !xerrors.Is(err, ErrNoAnalyzerMatch) {
+				failure = err
+			}
+			continue
+		}
+		os, ok := result.(OS)
+		if !ok {
 			continue
 		}
 		return os, nil
 	}
+	if failure != nil {
+		return OS{}, failure
+	}
+	if IsScratchImage(filesMap) {
+		return OS{Family: aos.Scratch}, nil
+	}
 	return OS{}, ErrUnknownOS
-
 }
 
+// GetPackages collects packages from all the package analyzers and removes duplicates.
+// An empty slice is returned for scratch images.
 func GetPackages(filesMap extractor.FileMap) ([]Package, error) {
+	return getPackages(filesMap, nil)
+}
+
+func getPackages(filesMap extractor.FileMap, panics *[]AnalyzerError) ([]Package, error) {
+	var results []Package
+	var failure error
+	detected := false
 	for _, analyzer := range pkgAnalyzers {
-		pkgs, err := analyzer.Analyze(filesMap)
+		analyze := func(fm extractor.FileMap) (interface{}, error) { return analyzer.Analyze(fm) }
+		result, err := runAnalyzer(analyzer, analyze, filesMap, panics)
+		if err != nil {
+			if failure == nil && !xerrors.Is(err, ErrNoAnalyzerMatch) {
+				failure = err
+			}
+			continue
+		}
+		pkgs, ok := result.([]Package)
+		if !ok {
+			continue
+		}
+		results = append(results, pkgs...)
+		detected = true
+	}
+	if !detected {
+		if failure != nil {
+			return nil, failure
+		}
+		if IsScratchImage(filesMap) {
+			return []Package{}, nil
+		}
+		return nil, ErrUnknownOS
+	}
+	return DeduplicatePackages(results), nil
+}
+
+// BuildSourceMap returns the map from binary package names to their source packages,
+// e.g. for looking up CVEs in NVD which refers to source packages.
+func BuildSourceMap(filesMap extractor.FileMap) (map[string]SrcPackage, error) {
+	srcMap := map[string]SrcPackage{}
+	var failure error
+	detected := false
+	for _, analyzer := range srcAnalyzers {
+		analyze := func(fm extractor.FileMap) (interface{}, error) { return analyzer.AnalyzeSource(fm) }
+		result, err := runAnalyzer(analyzer, analyze, filesMap, nil)
 		if err != nil {
+			if failure == nil && !xerrors.Is(err, ErrNoAnalyzerMatch) {
+				failure = err
+			}
+			continue
+		}
+		m, ok := result.(map[string]SrcPackage)
+		if !ok {
+			continue
+		}
+		for binName, src := range m {
+			srcMap[binName] = src
+		}
+		detected = true
+	}
+	if !detected {
+		if failure != nil {
+			return nil, failure
+		}
+		return nil, ErrNoAnalyzerMatch
+	}
+	return srcMap, nil
+}
+
+type pkgKey struct {
+	Name    string
+	Version string
+	Release string
+	Epoch   int
+	Arch    string
+	Type    string
+}
+
+func newPkgKey(pkg Package) pkgKey {
+	return pkgKey{Name: pkg.Name, Version: pkg.Version, Release: pkg.Release, Epoch: pkg.Epoch, Arch: pkg.Arch, Type: pkg.Type}
+}
+
+// DeduplicatePackages keeps one package per name, version, epoch, release, arch and type,
+// and the package with more fields populated is kept. A package without Arch is regarded as the same as
+// the package with Arch only if exactly one arch matches, e.g. not as both of i386 and amd64.
+// The packages are in the order of the first appearance.
+func DeduplicatePackages(pkgs []Package) []Package {
+	var uniq []Package
+	indices := map[pkgKey]int{}
+	for _, pkg := range pkgs {
+		key := newPkgKey(pkg)
+		if i, ok := indices[key]; ok {
+			if populatedFields(pkg) > populatedFields(uniq[i]) {
+				uniq[i] = pkg
+			}
 			continue
 		}
-		return pkgs, nil
+		indices[key] = len(uniq)
+		uniq = append(uniq, pkg)
+	}
+
+	// The packages with Arch keyed by the key without Arch
+	archs := map[pkgKey][]int{}
+	for i, pkg := range uniq {
+		if pkg.Arch != "" {
+			key := newPkgKey(pkg)
+			key.Arch = ""
+			archs[key] = append(archs[key], i)
+		}
+	}
+	folded := map[int]bool{}
+	for i, pkg := range uniq {
+		if pkg.Arch != "" || len(archs[newPkgKey(pkg)]) != 1 {
+			continue
+		}
+		j := archs[newPkgKey(pkg)][0]
+		merged := uniq[j]
+		if populatedFields(pkg) > populatedFields(merged) {
+			merged = pkg
+			merged.Arch = uniq[j].Arch
+		}
+		if i < j {
+			uniq[i], folded[j] = merged, true
+		} else {
+			uniq[j], folded[i] = merged, true
+		}
+	}
+
+	var deduplicated []Package
+	for i, pkg := range uniq {
+		if !folded[i] {
+			deduplicated = append(deduplicated, pkg)
+		}
+	}
+	return deduplicated
+}
+
+// populatedFields returns the number of the fields set in the package. The empty maps and slices are not counted,
+// e.g. Scripts of a package without maintainer scripts.
+func populatedFields(pkg Package) int {
+	var n int
+	v := reflect.ValueOf(pkg)
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Map, reflect.Slice:
+			if f.Len() > 0 {
+				n++
+			}
+		default:
+			if !reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface()) {
+				n++
+			}
+		}
 	}
-	return nil, ErrUnknownOS
+	return n
 }
 
 func CheckPackage(pkg *Package) bool {
 	return pkg.Name != "" && pkg.Version != ""
 }
 
+// GetLibraries runs all the library analyzers and merges their results.
+// A failure of an analyzer doesn't abort the analysis, see GetLibraryFindingsWithErrors.
 func GetLibraries(filesMap extractor.FileMap) (map[FilePath][]types.Library, error) {
-	results := map[FilePath][]types.Library{}
+	findings, err := GetLibraryFindings(filesMap)
+	return FindingsToLibraries(findings), err
+}
+
+// FindingsToLibraries drops the metadata of the findings.
+func FindingsToLibraries(findings map[FilePath][]LibraryFinding) map[FilePath][]types.Library {
+	libMap := map[FilePath][]types.Library{}
+	for filePath, fs := range findings {
+		for _, f := range fs {
+			libMap[filePath] = append(libMap[filePath], f.Library)
+		}
+	}
+	return libMap
+}
+
+// GetLibraryFindings is the same as GetLibraries but returns how reliably each library was identified.
+// Libraries from analyzers which don't implement LibraryFindingAnalyzer have the confidence 1
+// and the file name as the source, and are not marked as dev dependencies.
+func GetLibraryFindings(filesMap extractor.FileMap) (map[FilePath][]LibraryFinding, error) {
+	results, _, err := GetLibraryFindingsWithErrors(filesMap)
+	return results, err
+}
+
+// GetLibraryFindingsWithErrors is the same as GetLibraryFindings but also returns the errors of the analyzers
+// which failed, keyed by the analyzer name such as "composer.composerLibraryAnalyzer".
+// Analyzers whose required files are not in the files map don't fail. The error is returned only when
+// all the analyzers which had files to analyze failed, or an analyzer returned an unexpected result.
+// A panic of an analyzer is recovered and regarded as a failure.
+func GetLibraryFindingsWithErrors(filesMap extractor.FileMap) (map[FilePath][]LibraryFinding, map[string]error, error) {
+	return getLibraryFindings(filesMap, nil)
+}
+
+func getLibraryFindings(filesMap extractor.FileMap, panics *[]AnalyzerError) (map[FilePath][]LibraryFinding, map[string]error, error) {
+	filesMap = excludeLibraryFiles(filesMap)
+	results := map[FilePath][]LibraryFinding{}
+	analyzerErrs := map[string]error{}
 	for _, analyzer := range libAnalyzers {
-		libMap, err := analyzer.Analyze(filesMap)
+		analyze := func(fm extractor.FileMap) (interface{}, error) { return analyzer.Analyze(fm) }
+		if a, ok := analyzer.(LibraryFindingAnalyzer); ok {
+			analyze = func(fm extractor.FileMap) (interface{}, error) { return a.AnalyzeFindings(fm) }
+		}
+		result, err := runAnalyzer(analyzer, analyze, filesMap, panics)
+		if err != nil {
+			var aErr *AnalyzerError
+			if xerrors.As(err, &aErr) {
+				analyzerErrs[aErr.AnalyzerName] = aErr.Cause
+			}
+			continue
+		}
+
+		switch r := result.(type) {
+		case map[FilePath][]LibraryFinding:
+			for filePath, fs := range r {
+				results[filePath] = append(results[filePath], fs...)
+			}
+		case map[FilePath][]types.Library:
+			for filePath, libs := range r {
+				for _, lib := range libs {
+					results[filePath] = append(results[filePath], LibraryFinding{
+						Library:    lib,
+						Confidence: 1,
+						Source:     filepath.Base(string(filePath)),
+					})
+				}
+			}
+		default:
+			return nil, nil, xerrors.Errorf("unexpected result type: %T", result)
+		}
+	}
+	if len(analyzerErrs) > 0 && len(results) == 0 {
+		var errs []error
+		for name, err := range analyzerErrs {
+			errs = append(errs, &AnalyzerError{AnalyzerName: name, Cause: err})
+		}
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+		return results, analyzerErrs, xerrors.Errorf("all the library analyzers failed: %w", multiError(errs))
+	}
+	return results, analyzerErrs, nil
+}
+
+// GetPinningWarnings runs the library analyzers implementing PinningAnalyzer.
+// The warnings are sorted by the file path and the dependency.
+func GetPinningWarnings(filesMap extractor.FileMap) []PinningWarning {
+	return getPinningWarnings(filesMap, nil)
+}
+
+// getPinningWarnings skips the analyzers which panicked, like the other analyzers.
+func getPinningWarnings(filesMap extractor.FileMap, panics *[]AnalyzerError) []PinningWarning {
+	filesMap = excludeLibraryFiles(filesMap)
+	var warnings []PinningWarning
+	for _, analyzer := range libAnalyzers {
+		a, ok := analyzer.(PinningAnalyzer)
+		if !ok {
+			continue
+		}
+		analyze := func(fm extractor.FileMap) (interface{}, error) { return a.AnalyzePinning(fm), nil }
+		result, err := runAnalyzer(a, analyze, filesMap, panics)
 		if err != nil {
-			return nil, xerrors.Errorf("failed to analyze libraries: %w", err)
+			continue
+		}
+		warnings = append(warnings, result.([]PinningWarning)...)
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].FilePath != warnings[j].FilePath {
+			return warnings[i].FilePath < warnings[j].FilePath
+		}
+		return warnings[i].Dependency < warnings[j].Dependency
+	})
+	return warnings
+}
+
+// excludeLibraryFiles hides the files in the excluded paths unless they are required by a path pattern.
+// The files are not copied, only the hidden paths are kept.
+func excludeLibraryFiles(filesMap extractor.FileMap) extractor.FileMap {
+	if len(excludedPaths) == 0 {
+		return filesMap
+	}
+	required := RequiredFilenamesFor(AnalyzerTypeLibrary)
+	hidden := map[string]struct{}{}
+	for _, filePath := range filesMap.Paths() {
+		if extractor.MatchAny(excludedPaths, filePath) && !extractor.IsRequired(filePath, required, excludedPaths) {
+			hidden[filePath] = struct{}{}
+		}
+	}
+	if len(hidden) == 0 {
+		return filesMap
+	}
+	return excludedFileMap{FileMap: filesMap, hidden: hidden}
+}
+
+// excludedFileMap is the FileMap without the hidden files.
+type excludedFileMap struct {
+	extractor.FileMap
+	hidden map[string]struct{}
+}
+
+func (m excludedFileMap) Get(path string) ([]byte, bool) {
+	if _, ok := m.hidden[path]; ok {
+		return nil, false
+	}
+	return m.FileMap.Get(path)
+}
+
+func (m excludedFileMap) Metadata(path string) (extractor.FileMetadata, bool) {
+	if _, ok := m.hidden[path]; ok {
+		return extractor.FileMetadata{}, false
+	}
+	return m.FileMap.Metadata(path)
+}
+
+func (m excludedFileMap) ForEach(fn func(path string, content []byte) error) error {
+	return m.FileMap.ForEach(func(path string, content []byte) error {
+		if _, ok := m.hidden[path]; ok {
+			return nil
 		}
+		return fn(path, content)
+	})
+}
 
-		for filePath, libs := range libMap {
-			results[filePath] = libs
+func (m excludedFileMap) Paths() []string {
+	var paths []string
+	for _, path := range m.FileMap.Paths() {
+		if _, ok := m.hidden[path]; !ok {
+			paths = append(paths, path)
 		}
 	}
-	return results, nil
+	return paths
+}
+
+type multiError []error
+
+// Is reports whether any of the errors is the target.
+func (e multiError) Is(target error) bool {
+	for _, err := range e {
+		if xerrors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e multiError) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
 }
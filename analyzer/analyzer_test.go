@@ -0,0 +1,901 @@
+package analyzer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+func TestPackage_VersionString(t *testing.T) {
+	var tests = map[string]struct {
+		pkg      Package
+		expected string
+	}{
+		"WithEpochAndRelease": {
+			pkg:      Package{Name: "kernel", Epoch: 1, Version: "4.18.0", Release: "305.el8"},
+			expected: "1:4.18.0-305.el8",
+		},
+		"WithEpoch": {
+			pkg:      Package{Name: "zlib1g", Epoch: 1, Version: "1.2.11.dfsg-0ubuntu2"},
+			expected: "1:1.2.11.dfsg-0ubuntu2",
+		},
+		"WithRelease": {
+			pkg:      Package{Name: "bash", Version: "4.4.23", Release: "1.fc28"},
+			expected: "4.4.23-1.fc28",
+		},
+		"VersionOnly": {
+			pkg:      Package{Name: "musl", Version: "1.1.14-r10"},
+			expected: "1.1.14-r10",
+		},
+	}
+	for testName, v := range tests {
+		if actual := v.pkg.VersionString(); actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+type mockOSAnalyzer struct {
+	calls *[]string
+}
+
+func (a mockOSAnalyzer) Analyze(extractor.FileMap) (OS, error) {
+	*a.calls = append(*a.calls, "analyze")
+	return OS{Family: "alpine", Name: "3.9.4"}, nil
+}
+
+func (a mockOSAnalyzer) RequiredFiles() []string {
+	return []string{"etc/alpine-release"}
+}
+
+func TestRegisterMiddleware(t *testing.T) {
+	origOSAnalyzers, origMiddlewares := osAnalyzers, middlewares
+	defer func() {
+		osAnalyzers, middlewares = origOSAnalyzers, origMiddlewares
+	}()
+
+	var calls []string
+	osAnalyzers = []OSAnalyzer{mockOSAnalyzer{calls: &calls}}
+	middlewares = nil
+	for _, id := range []string{"first", "second"} {
+		id := id
+		RegisterMiddleware(func(name string, next AnalyzeFunc) AnalyzeFunc {
+			return func(filesMap extractor.FileMap) (interface{}, error) {
+				calls = append(calls, id+" before "+name)
+				result, err := next(filesMap)
+				calls = append(calls, id+" after "+name)
+				return result, err
+			}
+		})
+	}
+
+	os, err := GetOS(extractor.MapFileMap{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if os.Family != "alpine" || os.Name != "3.9.4" {
+		t.Errorf("unexpected OS: %v", os)
+	}
+
+	expected := []string{
+		"first before analyzer.mockOSAnalyzer",
+		"second before analyzer.mockOSAnalyzer",
+		"analyze",
+		"second after analyzer.mockOSAnalyzer",
+		"first after analyzer.mockOSAnalyzer",
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, calls)
+	}
+}
+
+type mockFailedOSAnalyzer struct {
+	err error
+}
+
+func (a mockFailedOSAnalyzer) Analyze(extractor.FileMap) (OS, error) {
+	return OS{}, a.err
+}
+
+func (a mockFailedOSAnalyzer) RequiredFiles() []string {
+	return nil
+}
+
+func TestGetOS(t *testing.T) {
+	origOSAnalyzers := osAnalyzers
+	defer func() {
+		osAnalyzers = origOSAnalyzers
+	}()
+
+	var tests = map[string]struct {
+		analyzers []OSAnalyzer
+		filesMap  extractor.FileMap
+		expected  error
+	}{
+		"NoMatch": {
+			analyzers: []OSAnalyzer{
+				mockRequiredFilesOSAnalyzer{
+					mockFailedOSAnalyzer: mockFailedOSAnalyzer{err: xerrors.Errorf("alpine: %w", ErrNoAnalyzerMatch)},
+					files:                []string{"etc/os-release"},
+				},
+				mockFailedOSAnalyzer{err: xerrors.Errorf("debian: %w", ErrNoAnalyzerMatch)},
+			},
+			filesMap: extractor.MapFileMap{"etc/os-release": []byte("ID=unknown\n")},
+			expected: ErrUnknownOS,
+		},
+		"Scratch": {
+			analyzers: []OSAnalyzer{
+				mockRequiredFilesOSAnalyzer{
+					mockFailedOSAnalyzer: mockFailedOSAnalyzer{err: xerrors.Errorf("alpine: %w", ErrNoAnalyzerMatch)},
+					files:                []string{"etc/os-release"},
+				},
+			},
+			filesMap: extractor.MapFileMap{"app/go.sum": []byte("")},
+			expected: nil,
+		},
+		"Malformed": {
+			analyzers: []OSAnalyzer{
+				mockFailedOSAnalyzer{err: xerrors.Errorf("alpine: %w", ErrNoAnalyzerMatch)},
+				mockFailedOSAnalyzer{err: xerrors.Errorf("cent: invalid centos-release: %w", ErrMalformedFile)},
+			},
+			expected: ErrMalformedFile,
+		},
+	}
+	for testName, v := range tests {
+		osAnalyzers = v.analyzers
+		actual, err := GetOS(v.filesMap)
+		if !xerrors.Is(err, v.expected) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, err)
+		}
+		if v.expected == nil && actual.Family != "scratch" {
+			t.Errorf("[%s] scratch is expected: %v", testName, actual)
+		}
+		if v.expected == ErrMalformedFile {
+			var aErr *AnalyzerError
+			if !xerrors.As(err, &aErr) || aErr.AnalyzerName != "analyzer.mockFailedOSAnalyzer" {
+				t.Errorf("[%s] AnalyzerError is expected: %v", testName, err)
+			}
+		}
+	}
+}
+
+type mockLibraryAnalyzer struct {
+	libMap map[FilePath][]types.Library
+	err    error
+}
+
+func (a mockLibraryAnalyzer) Analyze(extractor.FileMap) (map[FilePath][]types.Library, error) {
+	return a.libMap, a.err
+}
+
+func (a mockLibraryAnalyzer) RequiredFiles() []string {
+	return nil
+}
+
+type mockFailedLibraryAnalyzer struct {
+	err error
+}
+
+func (a mockFailedLibraryAnalyzer) Analyze(extractor.FileMap) (map[FilePath][]types.Library, error) {
+	return nil, a.err
+}
+
+func (a mockFailedLibraryAnalyzer) RequiredFiles() []string {
+	return nil
+}
+
+func TestGetLibraries(t *testing.T) {
+	origLibAnalyzers := libAnalyzers
+	defer func() {
+		libAnalyzers = origLibAnalyzers
+	}()
+
+	libAnalyzers = []LibraryAnalyzer{
+		mockFailedLibraryAnalyzer{err: xerrors.Errorf("invalid composer.lock format: %w", ErrMalformedFile)},
+		mockLibraryAnalyzer{libMap: map[FilePath][]types.Library{
+			"app/package-lock.json": {{Name: "express", Version: "4.17.1"}},
+		}},
+	}
+
+	libs, err := GetLibraries(extractor.MapFileMap{})
+	if err != nil {
+		t.Errorf("the error should not be returned when some analyzers succeed: %v", err)
+	}
+	expected := map[FilePath][]types.Library{
+		"app/package-lock.json": {{Name: "express", Version: "4.17.1"}},
+	}
+	if !reflect.DeepEqual(expected, libs) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, libs)
+	}
+
+	_, libErrs, err := GetLibraryFindingsWithErrors(extractor.MapFileMap{})
+	if err != nil {
+		t.Errorf("the error should not be returned when some analyzers succeed: %v", err)
+	}
+	if len(libErrs) != 1 || !xerrors.Is(libErrs["analyzer.mockFailedLibraryAnalyzer"], ErrMalformedFile) {
+		t.Errorf("the error of the failed analyzer should be returned: %v", libErrs)
+	}
+
+	libAnalyzers = []LibraryAnalyzer{
+		mockFailedLibraryAnalyzer{err: xerrors.Errorf("invalid composer.lock format: %w", ErrMalformedFile)},
+		mockLibraryAnalyzer{},
+	}
+	_, err = GetLibraries(extractor.MapFileMap{})
+	if err == nil {
+		t.Fatal("expected error when all the analyzers fail")
+	}
+	if !strings.Contains(err.Error(), "composer.lock") {
+		t.Errorf("the error of the failed analyzer should be returned: %v", err)
+	}
+}
+
+type mockFileLibraryAnalyzer struct {
+	files []string
+}
+
+// Analyze returns a library for each required file
+func (a mockFileLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[FilePath][]types.Library, error) {
+	libMap := map[FilePath][]types.Library{}
+	for _, filePath := range fileMap.Paths() {
+		if extractor.MatchAny(a.files, filePath) {
+			libMap[FilePath(filePath)] = []types.Library{{Name: "foo", Version: "1.0"}}
+		}
+	}
+	return libMap, nil
+}
+
+func (a mockFileLibraryAnalyzer) RequiredFiles() []string {
+	return a.files
+}
+
+func TestSetPathFilters(t *testing.T) {
+	origLibAnalyzers := libAnalyzers
+	defer func() {
+		libAnalyzers = origLibAnalyzers
+		SetPathFilters(DefaultExcludedPaths)
+	}()
+
+	libAnalyzers = []LibraryAnalyzer{
+		mockFileLibraryAnalyzer{files: []string{"yarn.lock", "Gemfile.lock"}},
+		mockFileLibraryAnalyzer{files: []string{"**/node_modules/*/package.json"}},
+	}
+	fileMap := extractor.MapFileMap{
+		"app/yarn.lock":                               nil,
+		"app/node_modules/foo/yarn.lock":              nil,
+		"app/node_modules/foo/package.json":           nil,
+		"app/vendor/bundle/ruby/gems/Gemfile.lock":    nil,
+		"root/go/pkg/mod/github.com/foo/Gemfile.lock": nil,
+	}
+
+	var tests = map[string]struct {
+		excludes []string
+		expected []FilePath
+	}{
+		"Default": {
+			excludes: DefaultExcludedPaths,
+			expected: []FilePath{"app/node_modules/foo/package.json", "app/yarn.lock"},
+		},
+		"Disabled": {
+			expected: []FilePath{
+				"app/node_modules/foo/package.json", "app/node_modules/foo/yarn.lock", "app/vendor/bundle/ruby/gems/Gemfile.lock",
+				"app/yarn.lock", "root/go/pkg/mod/github.com/foo/Gemfile.lock",
+			},
+		},
+	}
+	for testName, v := range tests {
+		SetPathFilters(v.excludes)
+		libs, err := GetLibraries(fileMap)
+		if err != nil {
+			t.Errorf("[%s] catch the error : %v", testName, err)
+		}
+		var actual []FilePath
+		for filePath := range libs {
+			actual = append(actual, filePath)
+		}
+		sort.Slice(actual, func(i, j int) bool { return actual[i] < actual[j] })
+		if !reflect.DeepEqual(v.expected, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestDeduplicatePackages(t *testing.T) {
+	var tests = map[string]struct {
+		pkgs     []Package
+		expected []Package
+	}{
+		"NoDuplicates": {
+			pkgs: []Package{
+				{Name: "bash", Version: "4.4.18-2ubuntu1", Type: TypeBinary},
+				{Name: "bash", Version: "4.4.18-2ubuntu1", Type: TypeSource},
+			},
+			expected: []Package{
+				{Name: "bash", Version: "4.4.18-2ubuntu1", Type: TypeBinary},
+				{Name: "bash", Version: "4.4.18-2ubuntu1", Type: TypeSource},
+			},
+		},
+		"Duplicates": {
+			pkgs: []Package{
+				{Name: "bash", Version: "4.4.23", Release: "1.fc28"},
+				{Name: "glibc", Version: "2.27", Release: "32.fc28", Arch: "x86_64"},
+				{Name: "bash", Version: "4.4.23", Release: "1.fc28", Arch: "x86_64"},
+				{Name: "glibc", Version: "2.27", Release: "32.fc28"},
+				{Name: "glibc", Version: "2.27", Release: "32.fc28", Arch: "i686"},
+				{Name: "bash", Version: "4.4.23", Release: "1.fc28"},
+			},
+			// glibc without Arch is not folded as both of x86_64 and i686 match
+			expected: []Package{
+				{Name: "bash", Version: "4.4.23", Release: "1.fc28", Arch: "x86_64"},
+				{Name: "glibc", Version: "2.27", Release: "32.fc28", Arch: "x86_64"},
+				{Name: "glibc", Version: "2.27", Release: "32.fc28"},
+				{Name: "glibc", Version: "2.27", Release: "32.fc28", Arch: "i686"},
+			},
+		},
+		"MultiArchAfterArchLess": {
+			pkgs: []Package{
+				{Name: "libc6", Version: "2.28-10", License: "LGPL-2.1", Maintainer: "GNU Libc Maintainers"},
+				{Name: "libc6", Version: "2.28-10", Arch: "i386"},
+				{Name: "libc6", Version: "2.28-10", Arch: "amd64"},
+			},
+			expected: []Package{
+				{Name: "libc6", Version: "2.28-10", License: "LGPL-2.1", Maintainer: "GNU Libc Maintainers"},
+				{Name: "libc6", Version: "2.28-10", Arch: "i386"},
+				{Name: "libc6", Version: "2.28-10", Arch: "amd64"},
+			},
+		},
+		// The package without Arch with more fields gets Arch of the only package with Arch
+		"ArchLessWithMoreFields": {
+			pkgs: []Package{
+				{Name: "libc6", Version: "2.28-10", Arch: "amd64"},
+				{Name: "libc6", Version: "2.28-10", License: "LGPL-2.1", Maintainer: "GNU Libc Maintainers"},
+			},
+			expected: []Package{
+				{Name: "libc6", Version: "2.28-10", Arch: "amd64", License: "LGPL-2.1", Maintainer: "GNU Libc Maintainers"},
+			},
+		},
+		"EmptyScripts": {
+			pkgs: []Package{
+				{Name: "bash", Version: "4.4.23", Scripts: map[string]string{}, ConfigFiles: []string{}},
+				{Name: "bash", Version: "4.4.23", Arch: "x86_64", License: "GPLv3+"},
+			},
+			expected: []Package{
+				{Name: "bash", Version: "4.4.23", Arch: "x86_64", License: "GPLv3+"},
+			},
+		},
+		"DifferentEpoch": {
+			pkgs: []Package{
+				{Name: "vim-minimal", Epoch: 2, Version: "8.1.328", Release: "1.fc28"},
+				{Name: "vim-minimal", Version: "8.1.328", Release: "1.fc28"},
+			},
+			expected: []Package{
+				{Name: "vim-minimal", Epoch: 2, Version: "8.1.328", Release: "1.fc28"},
+				{Name: "vim-minimal", Version: "8.1.328", Release: "1.fc28"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		actual := DeduplicatePackages(v.pkgs)
+		if !reflect.DeepEqual(v.expected, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+		// idempotency
+		if again := DeduplicatePackages(actual); !reflect.DeepEqual(actual, again) {
+			t.Errorf("[%s] not idempotent\nfirst : %v\nsecond : %v", testName, actual, again)
+		}
+	}
+}
+
+type mockRequiredFilesOSAnalyzer struct {
+	mockFailedOSAnalyzer
+	files []string
+}
+
+func (a mockRequiredFilesOSAnalyzer) RequiredFiles() []string {
+	return a.files
+}
+
+func TestRequiredFilenames(t *testing.T) {
+	origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers, origSrcAnalyzers := osAnalyzers, pkgAnalyzers, libAnalyzers, srcAnalyzers
+	defer func() {
+		osAnalyzers, pkgAnalyzers, libAnalyzers, srcAnalyzers = origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers, origSrcAnalyzers
+	}()
+
+	osAnalyzers = []OSAnalyzer{
+		mockRequiredFilesOSAnalyzer{files: []string{"etc/os-release", "etc/debian_version"}},
+		mockRequiredFilesOSAnalyzer{files: []string{"etc/lsb-release", "etc/os-release"}},
+		mockRequiredFilesOSAnalyzer{files: []string{"etc/os-release"}},
+	}
+	pkgAnalyzers, libAnalyzers, srcAnalyzers = nil, nil, nil
+
+	expected := []string{"etc/os-release", "etc/debian_version", "etc/lsb-release"}
+	if actual := RequiredFilenames(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+	if actual := RequiredFilenamesFor(AnalyzerTypeOS); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+	if actual := RequiredFilenamesFor(AnalyzerTypeLibrary); len(actual) != 0 {
+		t.Errorf("no library file is expected: %v", actual)
+	}
+	if actual := RequiredFilenamesFor("unknown"); actual != nil {
+		t.Errorf("nil is expected: %v", actual)
+	}
+}
+
+type mockDirPkgAnalyzer struct {
+	statusPkgAnalyzer
+	files []string
+	dirs  []string
+}
+
+func (a mockDirPkgAnalyzer) RequiredFiles() []string {
+	return a.files
+}
+
+func (a mockDirPkgAnalyzer) RequiredDirs() []string {
+	return a.dirs
+}
+
+func TestRequiredFilenames_Dirs(t *testing.T) {
+	origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers, origSrcAnalyzers := osAnalyzers, pkgAnalyzers, libAnalyzers, srcAnalyzers
+	defer func() {
+		osAnalyzers, pkgAnalyzers, libAnalyzers, srcAnalyzers = origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers, origSrcAnalyzers
+	}()
+
+	osAnalyzers = []OSAnalyzer{mockRequiredFilesOSAnalyzer{files: []string{"etc/*-release", "etc/os-release"}}}
+	pkgAnalyzers = []PkgAnalyzer{
+		mockDirPkgAnalyzer{files: []string{"etc/arch-release"}, dirs: []string{"var/lib/pacman/local"}},
+		mockDirPkgAnalyzer{files: []string{"var/lib/dpkg/status.d/"}, dirs: []string{"var/lib/pacman/local/"}},
+	}
+	libAnalyzers, srcAnalyzers = nil, nil
+
+	expected := []string{"etc/os-release", "etc/arch-release", "etc/*-release", "var/lib/pacman/local/", "var/lib/dpkg/status.d/"}
+	if actual := RequiredFilenames(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+	expected = []string{"etc/arch-release", "var/lib/pacman/local/", "var/lib/dpkg/status.d/"}
+	if actual := RequiredFilenamesFor(AnalyzerTypePkg); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+	if !extractor.IsRequired("var/lib/pacman/local/bash-5.0-1/desc", RequiredFilenames(), nil) {
+		t.Error("the files under the required directories must be required")
+	}
+}
+
+func TestIsScratchImage(t *testing.T) {
+	origOSAnalyzers, origPkgAnalyzers := osAnalyzers, pkgAnalyzers
+	defer func() {
+		osAnalyzers, pkgAnalyzers = origOSAnalyzers, origPkgAnalyzers
+	}()
+	osAnalyzers = []OSAnalyzer{mockRequiredFilesOSAnalyzer{
+		mockFailedOSAnalyzer: mockFailedOSAnalyzer{err: xerrors.Errorf("alpine: %w", ErrNoAnalyzerMatch)},
+		files:                []string{"etc/os-release"},
+	}}
+	pkgAnalyzers = nil
+
+	var tests = map[string]struct {
+		filesMap extractor.FileMap
+		expected bool
+	}{
+		"Empty": {
+			filesMap: extractor.MapFileMap{},
+			expected: true,
+		},
+		"OnlyLibraries": {
+			filesMap: extractor.MapFileMap{"app/go.sum": []byte(""), "app/package-lock.json": []byte("{}")},
+			expected: true,
+		},
+		"OSFile": {
+			filesMap: extractor.MapFileMap{"app/go.sum": []byte(""), "etc/os-release": []byte("ID=alpine\n")},
+			expected: false,
+		},
+	}
+	for testName, v := range tests {
+		if actual := IsScratchImage(v.filesMap); actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+
+	pkgs, err := GetPackages(extractor.MapFileMap{})
+	if err != nil || pkgs == nil || len(pkgs) != 0 {
+		t.Errorf("an empty slice is expected for scratch images: %v, %v", pkgs, err)
+	}
+	result, err := analyzeFiles(extractor.MapFileMap{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsScratch || result.OS.Family != "scratch" {
+		t.Errorf("scratch is expected: %v", result)
+	}
+}
+
+type panicOSAnalyzer struct{}
+
+func (a panicOSAnalyzer) Analyze(extractor.FileMap) (OS, error) {
+	panic("os")
+}
+
+func (a panicOSAnalyzer) RequiredFiles() []string {
+	return nil
+}
+
+type panicPkgAnalyzer struct{}
+
+func (a panicPkgAnalyzer) Analyze(extractor.FileMap) ([]Package, error) {
+	var pkgs []Package
+	return []Package{pkgs[0]}, nil
+}
+
+func (a panicPkgAnalyzer) RequiredFiles() []string {
+	return nil
+}
+
+type panicLibraryAnalyzer struct{}
+
+func (a panicLibraryAnalyzer) Analyze(extractor.FileMap) (map[FilePath][]types.Library, error) {
+	panic("library")
+}
+
+func (a panicLibraryAnalyzer) RequiredFiles() []string {
+	return nil
+}
+
+type panicPinningAnalyzer struct{}
+
+func (a panicPinningAnalyzer) Analyze(extractor.FileMap) (map[FilePath][]types.Library, error) {
+	return nil, nil
+}
+
+func (a panicPinningAnalyzer) AnalyzePinning(extractor.FileMap) []PinningWarning {
+	panic("pinning")
+}
+
+func (a panicPinningAnalyzer) RequiredFiles() []string {
+	return nil
+}
+
+func TestAnalyzerPanic(t *testing.T) {
+	origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers := osAnalyzers, pkgAnalyzers, libAnalyzers
+	defer func() {
+		osAnalyzers, pkgAnalyzers, libAnalyzers = origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers
+	}()
+
+	var calls []string
+	libMap := map[FilePath][]types.Library{"app/Gemfile.lock": {{Name: "rails", Version: "5.2.3"}}}
+	osAnalyzers = []OSAnalyzer{panicOSAnalyzer{}, mockOSAnalyzer{calls: &calls}}
+	pkgAnalyzers = []PkgAnalyzer{panicPkgAnalyzer{}}
+	libAnalyzers = []LibraryAnalyzer{panicLibraryAnalyzer{}, mockLibraryAnalyzer{libMap: libMap}, panicPinningAnalyzer{}}
+
+	result, err := analyzeFiles(extractor.MapFileMap{"etc/alpine-release": []byte("3.9.4")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.OS.Family != "alpine" || len(calls) != 1 {
+		t.Errorf("the OS analyzer after the panic is expected to run: %v", result.OS)
+	}
+	if !reflect.DeepEqual(libMap, result.Libraries) {
+		t.Errorf("\nexpected : %v\nactual : %v", libMap, result.Libraries)
+	}
+
+	var names []string
+	for _, p := range result.RecoveredPanics {
+		if !xerrors.Is(p.Cause, ErrAnalyzerPanic) {
+			t.Errorf("ErrAnalyzerPanic is expected: %v", p.Cause)
+		}
+		names = append(names, p.AnalyzerName)
+	}
+	expected := []string{"analyzer.panicOSAnalyzer", "analyzer.panicPkgAnalyzer", "analyzer.panicLibraryAnalyzer",
+		"analyzer.panicPinningAnalyzer"}
+	if !reflect.DeepEqual(expected, names) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, names)
+	}
+	if _, ok := result.LibraryErrors["analyzer.panicLibraryAnalyzer"]; !ok {
+		t.Errorf("the panic is expected in LibraryErrors: %v", result.LibraryErrors)
+	}
+
+	// The panic is returned by GetOS when no other analyzer matches
+	osAnalyzers = []OSAnalyzer{panicOSAnalyzer{}}
+	_, err = GetOS(extractor.MapFileMap{})
+	if !xerrors.Is(err, ErrAnalyzerPanic) || !strings.Contains(err.Error(), "panic: os") {
+		t.Errorf("ErrAnalyzerPanic is expected: %v", err)
+	}
+}
+
+func TestAnalyzeFiles_LibraryErrors(t *testing.T) {
+	origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers := osAnalyzers, pkgAnalyzers, libAnalyzers
+	defer func() {
+		osAnalyzers, pkgAnalyzers, libAnalyzers = origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers
+	}()
+
+	// All the library analyzers which had files failed
+	var calls []string
+	osAnalyzers = []OSAnalyzer{mockOSAnalyzer{calls: &calls}}
+	pkgAnalyzers = nil
+	libAnalyzers = []LibraryAnalyzer{
+		mockFailedLibraryAnalyzer{err: xerrors.Errorf("invalid composer.lock format: %w", ErrMalformedFile)},
+	}
+
+	result, err := analyzeFiles(extractor.MapFileMap{"etc/alpine-release": []byte("3.9.4")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := (OS{Family: "alpine", Name: "3.9.4"}); result.OS != expected {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, result.OS)
+	}
+	if !xerrors.Is(result.LibraryErrors["analyzer.mockFailedLibraryAnalyzer"], ErrMalformedFile) {
+		t.Errorf("the error of the failed analyzer is expected in LibraryErrors: %v", result.LibraryErrors)
+	}
+}
+
+func TestAnalyzeFromFileWithMetadata(t *testing.T) {
+	f, err := os.Open("../extractor/testdata/image1.tar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, metadata, err := AnalyzeFromFileWithMetadata(context.Background(), f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "sha256:d1cea7b7e18c216254f32eb618a49e57943d7d85919dd882935748479816a784"; metadata.ID != expected {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, metadata.ID)
+	}
+	if len(metadata.DiffIDs) != 3 {
+		t.Errorf("3 diff IDs are expected: %v", metadata.DiffIDs)
+	}
+}
+
+// releaseOSAnalyzer reads the version in etc/alpine-release.
+type releaseOSAnalyzer struct{}
+
+func (a releaseOSAnalyzer) Analyze(fileMap extractor.FileMap) (OS, error) {
+	b, ok := fileMap.Get("etc/alpine-release")
+	if !ok {
+		return OS{}, xerrors.Errorf("alpine: %w", ErrNoAnalyzerMatch)
+	}
+	return OS{Family: "alpine", Name: strings.TrimSpace(string(b))}, nil
+}
+
+func (a releaseOSAnalyzer) RequiredFiles() []string {
+	return []string{"etc/alpine-release"}
+}
+
+func TestAnalyzeFromDirectory(t *testing.T) {
+	origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers := osAnalyzers, pkgAnalyzers, libAnalyzers
+	defer func() {
+		osAnalyzers, pkgAnalyzers, libAnalyzers = origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers
+	}()
+	osAnalyzers, pkgAnalyzers, libAnalyzers = []OSAnalyzer{releaseOSAnalyzer{}}, nil, nil
+
+	dir, err := ioutil.TempDir("", "rootfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = os.MkdirAll(filepath.Join(dir, "etc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "etc", "alpine-release"), []byte("3.10.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := AnalyzeFromDirectory(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := OS{Family: "alpine", Name: "3.10.2"}
+	if result.OS != expected {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, result.OS)
+	}
+
+	if _, err = AnalyzeFromDirectory(context.Background(), filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
+
+// statusPkgAnalyzer reads the package name and the version in var/lib/dpkg/status with one package.
+type statusPkgAnalyzer struct{}
+
+func (a statusPkgAnalyzer) Analyze(fileMap extractor.FileMap) ([]Package, error) {
+	b, ok := fileMap.Get("var/lib/dpkg/status")
+	if !ok {
+		return nil, xerrors.Errorf("dpkg: %w", ErrNoAnalyzerMatch)
+	}
+	var pkg Package
+	for _, line := range strings.Split(string(b), "\n") {
+		switch {
+		case strings.HasPrefix(line, "Package: "):
+			pkg.Name = strings.TrimPrefix(line, "Package: ")
+		case strings.HasPrefix(line, "Version: "):
+			pkg.Version = strings.TrimPrefix(line, "Version: ")
+		}
+	}
+	return []Package{pkg}, nil
+}
+
+func (a statusPkgAnalyzer) RequiredFiles() []string {
+	return []string{"var/lib/dpkg/status"}
+}
+
+func TestGetPackages_Whiteout(t *testing.T) {
+	origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers := osAnalyzers, pkgAnalyzers, libAnalyzers
+	defer func() {
+		osAnalyzers, pkgAnalyzers, libAnalyzers = origOSAnalyzers, origPkgAnalyzers, origLibAnalyzers
+	}()
+	osAnalyzers = []OSAnalyzer{mockRequiredFilesOSAnalyzer{
+		mockFailedOSAnalyzer: mockFailedOSAnalyzer{err: ErrNoAnalyzerMatch},
+		files:                []string{"etc/debian_version"},
+	}}
+	pkgAnalyzers, libAnalyzers = []PkgAnalyzer{statusPkgAnalyzer{}}, nil
+
+	var tests = map[string]struct {
+		file     string
+		expected []Package
+		err      error
+	}{
+		// var/lib/dpkg/status is removed in the last layer
+		"Removed": {
+			file: "../extractor/testdata/whiteout.tar",
+			err:  ErrUnknownOS,
+		},
+		// var/lib/dpkg/status is removed and added again in the next layer
+		"ReAdded": {
+			file:     "../extractor/testdata/whiteout2.tar",
+			expected: []Package{{Name: "dash", Version: "0.5.10.2-5"}},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filesMap, err := AnalyzeFromFile(context.Background(), f)
+		f.Close()
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+			continue
+		}
+		pkgs, err := GetPackages(filesMap)
+		if v.err != nil {
+			if !xerrors.Is(err, v.err) {
+				t.Errorf("[%s]\nexpected error : %v\nactual error : %v", testName, v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.expected, pkgs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, pkgs)
+		}
+	}
+}
+
+func TestLayerInfos(t *testing.T) {
+	layers := []extractor.LayerDescriptor{
+		{Digest: "sha256:aaaa", MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Size: 100, UncompressedSize: 300},
+		{Digest: "sha256:cache", MediaType: "application/vnd.buildkit.cacheconfig.v0", Size: 10},
+		{Digest: "sha256:bbbb", MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Size: 20, UncompressedSize: 50},
+	}
+	var tests = map[string]struct {
+		metadata extractor.ImageMetadata
+		expected []LayerInfo
+	}{
+		"History": {
+			metadata: extractor.ImageMetadata{
+				Layers: layers,
+				History: []extractor.HistoryEntry{
+					{CreatedBy: "/bin/sh -c #(nop) ADD file:38bc6b51 in / "},
+					{CreatedBy: `/bin/sh -c #(nop)  CMD ["/bin/sh"]`, EmptyLayer: true},
+					{CreatedBy: "/bin/sh -c apk add --no-cache curl"},
+				},
+			},
+			expected: []LayerInfo{
+				{Digest: "sha256:aaaa", Size: 100, UncompressedSize: 300, CreatedBy: "/bin/sh -c #(nop) ADD file:38bc6b51 in / "},
+				{Digest: "sha256:bbbb", Size: 20, UncompressedSize: 50, CreatedBy: "/bin/sh -c apk add --no-cache curl"},
+			},
+		},
+		"NoHistory": {
+			metadata: extractor.ImageMetadata{Layers: layers},
+			expected: []LayerInfo{
+				{Digest: "sha256:aaaa", Size: 100, UncompressedSize: 300},
+				{Digest: "sha256:bbbb", Size: 20, UncompressedSize: 50},
+			},
+		},
+		"NoLayers": {
+			metadata: extractor.ImageMetadata{},
+		},
+	}
+	for testName, v := range tests {
+		actual := layerInfos(v.metadata)
+		if !reflect.DeepEqual(v.expected, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestSetImageMetadata(t *testing.T) {
+	metadata := extractor.ImageMetadata{
+		ID: "sha256:d1cea7b7",
+		Layers: []extractor.LayerDescriptor{
+			{Digest: "sha256:aaaa", Size: 100, UncompressedSize: 300, FileCount: 10},
+			{Digest: "sha256:cache", MediaType: "application/vnd.buildkit.cacheconfig.v0", Size: 10},
+			{Digest: "sha256:bbbb", Size: 20, UncompressedSize: 50, FileCount: 2},
+		},
+		TotalSizeBytes: 130,
+	}
+	// The result is empty when the analysis fails
+	var result AnalyzeResult
+	setImageMetadata(&result, metadata)
+	if result.ImageID != "sha256:d1cea7b7" || len(result.Layers) != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.TotalCompressedBytes != 120 || result.TotalUncompressedBytes != 350 || result.TotalFileCount != 12 {
+		t.Errorf("\nexpected : 120 350 12\nactual : %d %d %d",
+			result.TotalCompressedBytes, result.TotalUncompressedBytes, result.TotalFileCount)
+	}
+}
+
+func TestExcludeLibraryFiles(t *testing.T) {
+	filesMap := excludeLibraryFiles(extractor.MapFileMap{
+		"app/Gemfile.lock":                    []byte("GEM\n"),
+		"app/node_modules/foo/Gemfile.lock":   []byte("GEM\n"),
+		"app/vendor/bundle/ruby/Gemfile.lock": []byte("GEM\n"),
+	})
+	expected := []string{"app/Gemfile.lock"}
+	if actual := filesMap.Paths(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+	var paths []string
+	filesMap.ForEach(func(path string, content []byte) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if !reflect.DeepEqual(expected, paths) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, paths)
+	}
+	if _, ok := filesMap.Get("app/node_modules/foo/Gemfile.lock"); ok {
+		t.Error("the excluded file is expected to be hidden")
+	}
+	if _, ok := filesMap.Metadata("app/node_modules/foo/Gemfile.lock"); ok {
+		t.Error("the metadata of the excluded file is expected to be hidden")
+	}
+	if meta, ok := filesMap.Metadata("app/Gemfile.lock"); !ok || meta.MIMEType != "text/plain" {
+		t.Errorf("the metadata is expected: %v", meta)
+	}
+}
+
+type limitedLibraryAnalyzer struct {
+	panicLibraryAnalyzer
+	limits map[string]int64
+}
+
+func (a limitedLibraryAnalyzer) FileSizeLimits() map[string]int64 {
+	return a.limits
+}
+
+func TestFileSizeLimits(t *testing.T) {
+	origLibAnalyzers := libAnalyzers
+	defer func() { libAnalyzers = origLibAnalyzers }()
+
+	libAnalyzers = []LibraryAnalyzer{
+		limitedLibraryAnalyzer{limits: map[string]int64{"*.jar": 100, "*.war": 100}},
+		mockLibraryAnalyzer{},
+		limitedLibraryAnalyzer{limits: map[string]int64{"*.jar": 50}},
+	}
+	expected := map[string]int64{"*.jar": 50, "*.war": 100}
+	if actual := fileSizeLimits(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+}
@@ -22,18 +22,22 @@ func (a bundlerLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer
 	libMap := map[analyzer.FilePath][]types.Library{}
 	requiredFiles := a.RequiredFiles()
 
-	for filename, content := range fileMap {
+	err := fileMap.ForEach(func(filename string, content []byte) error {
 		basename := filepath.Base(filename)
 		if !utils.StringInSlice(basename, requiredFiles) {
-			continue
+			return nil
 		}
 
 		r := bytes.NewBuffer(content)
 		libs, err := bundler.Parse(r)
 		if err != nil {
-			return nil, xerrors.Errorf("invalid Gemfile.lock format: %w", err)
+			return xerrors.Errorf("invalid Gemfile.lock format: %v: %w", err, analyzer.ErrMalformedFile)
 		}
 		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return libMap, nil
 }
@@ -0,0 +1,168 @@
+package cargo
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/fanal/utils"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&cargoLibraryAnalyzer{})
+}
+
+// lockfile supports the versions 1 to 4.
+// The version 1 and 2 lockfiles don't have "version" and version 1 has checksums in [metadata].
+type lockfile struct {
+	Version  int            `toml:"version"`
+	Packages []cargoPackage `toml:"package"`
+}
+
+type cargoPackage struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+	// e.g. "registry+https://github.com/rust-lang/crates.io-index"
+	// and "git+https://github.com/serde-rs/serde?branch=master#6e4e0f5a8a2b3c4d"
+	// Workspace members and path dependencies don't have it.
+	Source string `toml:"source"`
+	// e.g. "serde", "serde 1.0.193" and "itoa 0.4.4 (registry+https://github.com/rust-lang/crates.io-index)"
+	// The version is written only when multiple versions of the package exist.
+	Dependencies []string `toml:"dependencies"`
+}
+
+type cargoLibraryAnalyzer struct{}
+
+func (a cargoLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	findingMap, err := a.AnalyzeFindings(fileMap)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.FindingsToLibraries(findingMap), nil
+}
+
+// AnalyzeFindings returns the libraries with the dependency graph.
+func (a cargoLibraryAnalyzer) AnalyzeFindings(fileMap extractor.FileMap) (map[analyzer.FilePath][]analyzer.LibraryFinding, error) {
+	libMap := map[analyzer.FilePath][]analyzer.LibraryFinding{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !utils.StringInSlice(basename, requiredFiles) {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid Cargo.lock format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a cargoLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"Cargo.lock"}
+}
+
+// parse returns registry and git dependencies with their versions.
+// Local packages such as workspace members are skipped, and their dependencies are regarded as direct ones.
+// The revision of git dependencies is returned as the revision of the findings.
+func parse(r io.Reader) ([]analyzer.LibraryFinding, error) {
+	var lock lockfile
+	if _, err := toml.DecodeReader(r, &lock); err != nil {
+		return nil, xerrors.Errorf("failed to decode Cargo.lock: %w", err)
+	}
+	if lock.Version > 4 {
+		return nil, xerrors.Errorf("unsupported lockfile version: %d", lock.Version)
+	}
+
+	versions := map[string][]string{}
+	for _, pkg := range lock.Packages {
+		versions[pkg.Name] = append(versions[pkg.Name], pkg.Version)
+	}
+	// e.g. "itoa 0.4.4 (registry+...)" => itoa@0.4.4
+	resolve := func(dep string) string {
+		fields := strings.Fields(dep)
+		if len(fields) == 0 {
+			return ""
+		}
+		name := fields[0]
+		if len(fields) > 1 {
+			return packageID(name, fields[1])
+		}
+		if vs := versions[name]; len(vs) == 1 {
+			return packageID(name, vs[0])
+		}
+		return ""
+	}
+
+	local := map[string]bool{}
+	direct := map[string]bool{}
+	for _, pkg := range lock.Packages {
+		if pkg.Source != "" {
+			continue
+		}
+		local[packageID(pkg.Name, pkg.Version)] = true
+		for _, dep := range pkg.Dependencies {
+			direct[resolve(dep)] = true
+		}
+	}
+
+	var libs []analyzer.LibraryFinding
+	for _, pkg := range lock.Packages {
+		if pkg.Source == "" {
+			continue
+		}
+		id := packageID(pkg.Name, pkg.Version)
+		var dependsOn []string
+		for _, dep := range pkg.Dependencies {
+			if depID := resolve(dep); depID != "" && !local[depID] {
+				dependsOn = append(dependsOn, depID)
+			}
+		}
+		sort.Strings(dependsOn)
+
+		libs = append(libs, analyzer.LibraryFinding{
+			Library: types.Library{
+				Name:    pkg.Name,
+				Version: pkg.Version,
+			},
+			Confidence: 1,
+			Source:     "Cargo.lock",
+			ID:         id,
+			Direct:     direct[id],
+			DependsOn:  dependsOn,
+			Revision:   gitRevision(pkg.Source),
+		})
+	}
+	return libs, nil
+}
+
+func packageID(name, version string) string {
+	return name + "@" + version
+}
+
+// gitRevision returns the commit of the git source, e.g. "6e4e0f5a8a2b3c4d" of
+// "git+https://github.com/serde-rs/serde?branch=master#6e4e0f5a8a2b3c4d". It is empty for the other sources.
+func gitRevision(source string) string {
+	if !strings.HasPrefix(source, "git+") {
+		return ""
+	}
+	if i := strings.LastIndex(source, "#"); i >= 0 {
+		return source[i+1:]
+	}
+	return ""
+}
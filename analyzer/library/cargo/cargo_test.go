@@ -0,0 +1,79 @@
+package cargo
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	atesting "github.com/knqyf263/fanal/analyzer/testing"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []analyzer.LibraryFinding
+	}{
+		"Version1": {
+			path: "./testdata/Cargo_v1.lock",
+			libs: []analyzer.LibraryFinding{
+				{Library: types.Library{Name: "itoa", Version: "0.4.4"}, Confidence: 1, Source: "Cargo.lock", ID: "itoa@0.4.4", Direct: true},
+			},
+		},
+		"Workspace": {
+			path: "./testdata/Cargo_workspace.lock",
+			libs: []analyzer.LibraryFinding{
+				{Library: types.Library{Name: "cfg-if", Version: "1.0.0"}, Confidence: 1, Source: "Cargo.lock", ID: "cfg-if@1.0.0", Direct: true},
+				{Library: types.Library{Name: "log", Version: "0.4.20"}, Confidence: 1, Source: "Cargo.lock", ID: "log@0.4.20", Direct: true},
+			},
+		},
+		"GitDependency": {
+			path: "./testdata/Cargo_git.lock",
+			libs: []analyzer.LibraryFinding{
+				{
+					Library: types.Library{Name: "serde", Version: "1.0.193"}, Confidence: 1, Source: "Cargo.lock",
+					ID: "serde@1.0.193", Direct: true, Revision: "6e4e0f5a8a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d",
+				},
+				{Library: types.Library{Name: "tokio", Version: "1.35.0"}, Confidence: 1, Source: "Cargo.lock", ID: "tokio@1.35.0", Direct: true},
+			},
+		},
+		"Graph": {
+			path: "./testdata/Cargo_graph.lock",
+			libs: []analyzer.LibraryFinding{
+				{
+					Library: types.Library{Name: "bytes", Version: "1.5.0"}, Confidence: 1, Source: "Cargo.lock",
+					ID: "bytes@1.5.0", DependsOn: []string{"serde@1.0.100"},
+				},
+				{
+					Library: types.Library{Name: "pin-project-lite", Version: "0.2.13"}, Confidence: 1, Source: "Cargo.lock",
+					ID: "pin-project-lite@0.2.13", DependsOn: []string{"tokio@1.35.0"},
+				},
+				{Library: types.Library{Name: "serde", Version: "1.0.100"}, Confidence: 1, Source: "Cargo.lock", ID: "serde@1.0.100"},
+				{Library: types.Library{Name: "serde", Version: "1.0.193"}, Confidence: 1, Source: "Cargo.lock", ID: "serde@1.0.193", Direct: true},
+				{
+					Library: types.Library{Name: "tokio", Version: "1.35.0"}, Confidence: 1, Source: "Cargo.lock",
+					ID: "tokio@1.35.0", Direct: true, DependsOn: []string{"bytes@1.5.0", "pin-project-lite@0.2.13"},
+				},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
+
+func BenchmarkCargo(b *testing.B) {
+	atesting.RunAnalyzerBenchmark(b, cargoLibraryAnalyzer{}, map[string]string{"app/Cargo.lock": "testdata/Cargo_graph.lock"})
+}
@@ -0,0 +1,104 @@
+package cocoapods
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/fanal/utils"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// e.g. Firebase/Core (10.0.0)
+var podRegexp = regexp.MustCompile(`^(\S+) \((\S+)\)$`)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&cocoaPodsLibraryAnalyzer{})
+}
+
+type lockFile struct {
+	// A string without dependencies, or a map from the pod to its dependencies
+	Pods            []interface{}                `yaml:"PODS"`
+	ExternalSources map[string]map[string]string `yaml:"EXTERNAL SOURCES"`
+}
+
+type cocoaPodsLibraryAnalyzer struct{}
+
+func (a cocoaPodsLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !utils.StringInSlice(basename, requiredFiles) {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid Podfile.lock format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a cocoaPodsLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"Podfile.lock"}
+}
+
+// parse returns the pods in the PODS section.
+// Subspecs such as "Firebase/Core" are reported as the root pod, and local pods with :path are skipped.
+func parse(r io.Reader) ([]types.Library, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("read error: %w", err)
+	}
+	var lock lockFile
+	if err := yaml.Unmarshal(b, &lock); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	var libs []types.Library
+	seen := map[types.Library]struct{}{}
+	for _, pod := range lock.Pods {
+		var entry string
+		switch p := pod.(type) {
+		case string:
+			entry = p
+		case map[interface{}]interface{}:
+			for k := range p {
+				entry, _ = k.(string)
+			}
+		}
+
+		m := podRegexp.FindStringSubmatch(entry)
+		if m == nil {
+			continue
+		}
+		name := strings.SplitN(m[1], "/", 2)[0]
+		if _, ok := lock.ExternalSources[name][":path"]; ok {
+			continue
+		}
+
+		lib := types.Library{Name: name, Version: m[2]}
+		if _, ok := seen[lib]; ok {
+			continue
+		}
+		seen[lib] = struct{}{}
+		libs = append(libs, lib)
+	}
+	return libs, nil
+}
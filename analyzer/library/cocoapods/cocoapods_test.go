@@ -0,0 +1,41 @@
+package cocoapods
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []types.Library
+	}{
+		"Valid": {
+			path: "./testdata/Podfile.lock",
+			libs: []types.Library{
+				{Name: "Alamofire", Version: "5.6.4"},
+				{Name: "Firebase", Version: "10.0.0"},
+				{Name: "FirebaseAnalytics", Version: "10.0.0"},
+				{Name: "FirebaseCore", Version: "10.0.0"},
+				{Name: "SwiftyJSON", Version: "5.0.1"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
@@ -2,42 +2,153 @@ package composer
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
 	"path/filepath"
 
 	"github.com/knqyf263/fanal/analyzer"
 	"github.com/knqyf263/fanal/extractor"
 	"github.com/knqyf263/fanal/utils"
-	"github.com/knqyf263/go-dep-parser/pkg/composer"
 	"github.com/knqyf263/go-dep-parser/pkg/types"
 	"golang.org/x/xerrors"
 )
 
+const installedJSON = "**/vendor/composer/installed.json"
+
 func init() {
 	analyzer.RegisterLibraryAnalyzer(&composerLibraryAnalyzer{})
 }
 
+type packageInfo struct {
+	Name    string
+	Version string
+}
+
+type lockFile struct {
+	Packages    []packageInfo `json:"packages"`
+	PackagesDev []packageInfo `json:"packages-dev"`
+}
+
 type composerLibraryAnalyzer struct{}
 
 func (a composerLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
-	libMap := map[analyzer.FilePath][]types.Library{}
-	requiredFiles := a.RequiredFiles()
+	findingMap, err := a.AnalyzeFindings(fileMap)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.FindingsToLibraries(findingMap), nil
+}
+
+// AnalyzeFindings parses vendor/composer/installed.json keyed by the vendor directory and composer.lock.
+// composer.lock is skipped when installed.json of the same project exists
+// because installed.json reflects what is actually installed, e.g. without dev packages.
+// Libraries in "packages-dev" of composer.lock and "dev-package-names" of installed.json are marked as dev dependencies.
+func (a composerLibraryAnalyzer) AnalyzeFindings(fileMap extractor.FileMap) (map[analyzer.FilePath][]analyzer.LibraryFinding, error) {
+	libMap := map[analyzer.FilePath][]analyzer.LibraryFinding{}
 
-	for filename, content := range fileMap {
-		basename := filepath.Base(filename)
-		if !utils.StringInSlice(basename, requiredFiles) {
-			continue
+	installed := map[string]struct{}{}
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		if !isInstalledJSON(filename) {
+			return nil
+		}
+
+		libs, err := parseInstalled(bytes.NewBuffer(content))
+		if err != nil {
+			return xerrors.Errorf("invalid installed.json format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		// e.g. app/vendor/composer/installed.json => app/vendor
+		vendorDir := filepath.Dir(filepath.Dir(filename))
+		libMap[analyzer.FilePath(vendorDir)] = libs
+		installed[filepath.Dir(vendorDir)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = fileMap.ForEach(func(filename string, content []byte) error {
+		if filepath.Base(filename) != "composer.lock" {
+			return nil
+		}
+		if _, ok := installed[filepath.Dir(filename)]; ok {
+			return nil
 		}
 
 		r := bytes.NewBuffer(content)
-		libs, err := composer.Parse(r)
+		libs, err := parseLock(r)
 		if err != nil {
-			return nil, xerrors.Errorf("invalid composer.lock format: %w", err)
+			return xerrors.Errorf("invalid composer.lock format: %v: %w", err, analyzer.ErrMalformedFile)
 		}
 		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return libMap, nil
 }
 
 func (a composerLibraryAnalyzer) RequiredFiles() []string {
-	return []string{"composer.lock"}
+	return []string{"composer.lock", installedJSON}
+}
+
+func isInstalledJSON(filename string) bool {
+	return extractor.Match(installedJSON, filename)
+}
+
+func parseLock(r io.Reader) ([]analyzer.LibraryFinding, error) {
+	var lock lockFile
+	if err := json.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	libs := toFindings(lock.Packages, "composer.lock", nil)
+	for _, lib := range toFindings(lock.PackagesDev, "composer.lock", nil) {
+		lib.Dev = true
+		libs = append(libs, lib)
+	}
+	return libs, nil
+}
+
+// parseInstalled supports both the array of Composer 1 and {"packages": [...]} of Composer 2.
+// Only Composer 2 records which packages are for development.
+func parseInstalled(r io.Reader) ([]analyzer.LibraryFinding, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("read error: %w", err)
+	}
+
+	var pkgs []packageInfo
+	var devNames []string
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		err = json.Unmarshal(b, &pkgs)
+	} else {
+		var installed struct {
+			Packages        []packageInfo
+			DevPackageNames []string `json:"dev-package-names"`
+		}
+		err = json.Unmarshal(b, &installed)
+		pkgs, devNames = installed.Packages, installed.DevPackageNames
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+	return toFindings(pkgs, "installed.json", devNames), nil
+}
+
+func toFindings(pkgs []packageInfo, source string, devNames []string) []analyzer.LibraryFinding {
+	var libs []analyzer.LibraryFinding
+	for _, pkg := range pkgs {
+		libs = append(libs, analyzer.LibraryFinding{
+			Library: types.Library{
+				Name:    pkg.Name,
+				Version: pkg.Version,
+			},
+			Confidence: 1,
+			Source:     source,
+			Dev:        utils.StringInSlice(pkg.Name, devNames),
+		})
+	}
+	return libs
 }
@@ -0,0 +1,119 @@
+package composer
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	atesting "github.com/knqyf263/fanal/analyzer/testing"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParseInstalled(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []analyzer.LibraryFinding
+	}{
+		"Composer1": {
+			path: "./testdata/installed_v1.json",
+			libs: []analyzer.LibraryFinding{
+				{Library: types.Library{Name: "laravel/framework", Version: "v5.8.17"}, Confidence: 1, Source: "installed.json"},
+				{Library: types.Library{Name: "monolog/monolog", Version: "1.24.0"}, Confidence: 1, Source: "installed.json"},
+			},
+		},
+		"Composer2": {
+			path: "./testdata/installed_v2.json",
+			libs: []analyzer.LibraryFinding{
+				{Library: types.Library{Name: "guzzlehttp/guzzle", Version: "7.8.1"}, Confidence: 1, Source: "installed.json"},
+				{Library: types.Library{Name: "psr/log", Version: "3.0.0"}, Confidence: 1, Source: "installed.json"},
+			},
+		},
+		"Composer2Dev": {
+			path: "./testdata/installed_v2_dev.json",
+			libs: []analyzer.LibraryFinding{
+				{Library: types.Library{Name: "phpunit/phpunit", Version: "10.5.10"}, Confidence: 1, Source: "installed.json", Dev: true},
+				{Library: types.Library{Name: "psr/log", Version: "3.0.0"}, Confidence: 1, Source: "installed.json"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parseInstalled(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	lock, err := ioutil.ReadFile("./testdata/composer.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	installed, err := ioutil.ReadFile("./testdata/installed_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileMap := extractor.MapFileMap{
+		"app/composer.lock":                         lock,
+		"app/vendor/composer/installed.json":        installed,
+		"other/composer.lock":                       lock,
+		"other/node_modules/foo/bar/installed.json": []byte("{}"),
+	}
+	expected := map[analyzer.FilePath][]types.Library{
+		"app/vendor": {
+			{Name: "guzzlehttp/guzzle", Version: "7.8.1"},
+			{Name: "psr/log", Version: "3.0.0"},
+		},
+		"other/composer.lock": {
+			{Name: "guzzlehttp/guzzle", Version: "7.8.1"},
+			{Name: "psr/log", Version: "3.0.0"},
+			{Name: "phpunit/phpunit", Version: "10.5.10"},
+		},
+	}
+
+	a := composerLibraryAnalyzer{}
+	libMap, err := a.Analyze(fileMap)
+	if err != nil {
+		t.Errorf("catch the error : %v", err)
+	}
+	if !reflect.DeepEqual(expected, libMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, libMap)
+	}
+}
+
+func TestParseLock(t *testing.T) {
+	f, err := os.Open("./testdata/composer.lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	expected := []analyzer.LibraryFinding{
+		{Library: types.Library{Name: "guzzlehttp/guzzle", Version: "7.8.1"}, Confidence: 1, Source: "composer.lock"},
+		{Library: types.Library{Name: "psr/log", Version: "3.0.0"}, Confidence: 1, Source: "composer.lock"},
+		{Library: types.Library{Name: "phpunit/phpunit", Version: "10.5.10"}, Confidence: 1, Source: "composer.lock", Dev: true},
+	}
+	libs, err := parseLock(f)
+	if err != nil {
+		t.Errorf("catch the error : %v", err)
+	}
+	if !reflect.DeepEqual(expected, libs) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, libs)
+	}
+}
+
+func BenchmarkComposer(b *testing.B) {
+	atesting.RunAnalyzerBenchmark(b, composerLibraryAnalyzer{}, map[string]string{"app/composer.lock": "testdata/composer.lock"})
+}
@@ -0,0 +1,123 @@
+package conan
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/fanal/utils"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&conanLibraryAnalyzer{})
+}
+
+type lockFile struct {
+	// Conan 1.x
+	GraphLock struct {
+		Nodes map[string]struct {
+			Ref string
+		}
+	} `json:"graph_lock"`
+
+	// Conan 2.x
+	Requires      []string `json:"requires"`
+	BuildRequires []string `json:"build_requires"`
+}
+
+type conanLibraryAnalyzer struct{}
+
+func (a conanLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !utils.StringInSlice(basename, requiredFiles) {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid conan.lock format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a conanLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"conan.lock"}
+}
+
+// parse supports both the graph_lock of Conan 1.x and the requires of Conan 2.x.
+// Build requirements are included, and malformed references are skipped.
+func parse(r io.Reader) ([]types.Library, error) {
+	var lock lockFile
+	if err := json.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	var refs []string
+	// Nodes are keyed by "0", "1", ...
+	var ids []string
+	for id := range lock.GraphLock.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ni, erri := strconv.Atoi(ids[i])
+		nj, errj := strconv.Atoi(ids[j])
+		if erri != nil || errj != nil {
+			return ids[i] < ids[j]
+		}
+		return ni < nj
+	})
+	for _, id := range ids {
+		refs = append(refs, lock.GraphLock.Nodes[id].Ref)
+	}
+	refs = append(refs, lock.Requires...)
+	refs = append(refs, lock.BuildRequires...)
+
+	var libs []types.Library
+	seen := map[types.Library]struct{}{}
+	for _, ref := range refs {
+		lib, ok := parseRef(ref)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[lib]; ok {
+			continue
+		}
+		seen[lib] = struct{}{}
+		libs = append(libs, lib)
+	}
+	return libs, nil
+}
+
+// parseRef parses e.g. "zlib/1.2.13#rrev" and "openssl/3.0.0@user/channel#rrev%1678901234.5".
+func parseRef(ref string) (types.Library, bool) {
+	if i := strings.Index(ref, "#"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.Library{}, false
+	}
+	return types.Library{Name: parts[0], Version: parts[1]}, true
+}
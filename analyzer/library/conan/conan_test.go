@@ -0,0 +1,47 @@
+package conan
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []types.Library
+	}{
+		"GraphLock": {
+			path: "./testdata/conan_v1.lock",
+			libs: []types.Library{
+				{Name: "zlib", Version: "1.2.13"},
+				{Name: "openssl", Version: "1.1.1t"},
+				{Name: "cmake", Version: "3.25.3"},
+			},
+		},
+		"Requires": {
+			path: "./testdata/conan_v2.lock",
+			libs: []types.Library{
+				{Name: "zlib", Version: "1.2.13"},
+				{Name: "openssl", Version: "3.1.2"},
+				{Name: "cmake", Version: "3.27.1"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
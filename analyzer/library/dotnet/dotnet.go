@@ -0,0 +1,91 @@
+package dotnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&dotnetLibraryAnalyzer{})
+}
+
+type depsFile struct {
+	// "<name>/<version>" => library
+	Libraries map[string]struct {
+		Type string
+	}
+}
+
+type dotnetLibraryAnalyzer struct{}
+
+// Analyze parses <app>.deps.json written by "dotnet publish".
+func (a dotnetLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		if !strings.HasSuffix(filename, ".deps.json") {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid deps.json format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		if len(libs) == 0 {
+			return nil
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a dotnetLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"*.deps.json"}
+}
+
+// parse returns NuGet packages and, in self-contained deployments, the runtime packs.
+// Projects and the other entries such as runtime stores are skipped.
+func parse(r io.Reader) ([]types.Library, error) {
+	var deps depsFile
+	if err := json.NewDecoder(r).Decode(&deps); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	var keys []string
+	for key := range deps.Libraries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var libs []types.Library
+	for _, key := range keys {
+		switch deps.Libraries[key].Type {
+		case "package", "runtimepack":
+		default:
+			continue
+		}
+		// e.g. Newtonsoft.Json/13.0.1, runtimepack.Microsoft.NETCore.App.Runtime.linux-x64/6.0.25
+		i := strings.LastIndex(key, "/")
+		if i < 0 {
+			continue
+		}
+		libs = append(libs, types.Library{
+			Name:    strings.TrimPrefix(key[:i], "runtimepack."),
+			Version: key[i+1:],
+		})
+	}
+	return libs, nil
+}
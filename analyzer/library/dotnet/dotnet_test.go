@@ -0,0 +1,49 @@
+package dotnet
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []types.Library
+	}{
+		"FrameworkDependent": {
+			path: "./testdata/app.deps.json",
+			libs: []types.Library{
+				{Name: "Newtonsoft.Json", Version: "13.0.1"},
+				{Name: "Serilog", Version: "2.12.0"},
+			},
+		},
+		"SelfContained": {
+			path: "./testdata/selfcontained.deps.json",
+			libs: []types.Library{
+				{Name: "System.Text.Json", Version: "6.0.0"},
+				{Name: "Microsoft.NETCore.App.Runtime.linux-x64", Version: "6.0.25"},
+			},
+		},
+		"NoLibraries": {
+			path: "./testdata/trimmed.deps.json",
+			libs: nil,
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package gemspec
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+const specifications = "specifications"
+
+var (
+	// e.g. s.name = "rack".freeze
+	nameRegexp = regexp.MustCompile(`^\s*\w+\.name\s*=\s*(.+)$`)
+	// e.g. s.version = "2.0.7", s.version = Gem::Version.new("1.0.0.pre")
+	versionRegexp = regexp.MustCompile(`^\s*\w+\.version\s*=\s*(.+)$`)
+	// e.g. "rack", 'rack', %q{rack}, %q(rack), %Q[rack]
+	quotedRegexp = regexp.MustCompile(`^(?:"([^"]*)"|'([^']*)'|%[qQ]?(?:\{([^}]*)\}|\(([^)]*)\)|\[([^\]]*)\]|<([^>]*)>))`)
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&gemspecLibraryAnalyzer{})
+}
+
+type gemspecLibraryAnalyzer struct{}
+
+// Analyze detects installed gems from specifications/*.gemspec and specifications/default/*.gemspec.
+// Results are grouped by the gem home directory.
+func (a gemspecLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		if !extractor.MatchAny(requiredFiles, filename) {
+			return nil
+		}
+		gemHome, ok := gemHomePath(filename)
+		if !ok {
+			return nil
+		}
+
+		lib := parse(content)
+		if lib.Name == "" || lib.Version == "" {
+			return nil
+		}
+		libMap[gemHome] = append(libMap[gemHome], lib)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a gemspecLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"**/" + specifications + "/*.gemspec", "**/" + specifications + "/default/*.gemspec"}
+}
+
+// gemHomePath returns the directory containing "specifications".
+func gemHomePath(filename string) (analyzer.FilePath, bool) {
+	dir := filepath.Dir(filename)
+	if filepath.Base(dir) == "default" {
+		dir = filepath.Dir(dir)
+	}
+	if filepath.Base(dir) != specifications {
+		return "", false
+	}
+	return analyzer.FilePath(filepath.Dir(dir)), true
+}
+
+func parse(content []byte) types.Library {
+	var lib types.Library
+	scanner := bufio.NewScanner(bytes.NewBuffer(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Comments such as "# frozen_string_literal: true" and "# stub: rack 2.0.7 ruby lib"
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		if m := nameRegexp.FindStringSubmatch(line); m != nil && lib.Name == "" {
+			lib.Name = unquote(m[1])
+		} else if m := versionRegexp.FindStringSubmatch(line); m != nil && lib.Version == "" {
+			lib.Version = unquote(m[1])
+		}
+		if lib.Name != "" && lib.Version != "" {
+			break
+		}
+	}
+	return lib
+}
+
+// unquote returns the string literal in the Ruby expression.
+func unquote(expr string) string {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimPrefix(expr, "Gem::Version.new(")
+	m := quotedRegexp.FindStringSubmatch(expr)
+	if m == nil {
+		return ""
+	}
+	for _, s := range m[1:] {
+		if s != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
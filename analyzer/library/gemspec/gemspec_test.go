@@ -0,0 +1,71 @@
+package gemspec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestAnalyze(t *testing.T) {
+	var tests = map[string]struct {
+		root   string
+		libMap map[analyzer.FilePath][]types.Library
+	}{
+		"Installed": {
+			root: "./testdata",
+			libMap: map[analyzer.FilePath][]types.Library{
+				"usr/local/bundle": {
+					{Name: "json", Version: "2.1.0"},
+					{Name: "legacy", Version: "0.1.0"},
+					{Name: "rack", Version: "2.0.7"},
+					{Name: "rails", Version: "6.0.0.rc1"},
+				},
+				"usr/local/lib/ruby/gems/2.6.0": {
+					{Name: "bundler", Version: "1.17.2"},
+				},
+			},
+		},
+	}
+	a := gemspecLibraryAnalyzer{}
+	for testName, v := range tests {
+		fileMap := extractor.MapFileMap{}
+		err := filepath.Walk(v.root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(v.root, path)
+			if err != nil {
+				return err
+			}
+			fileMap[filepath.ToSlash(rel)] = b
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s : can't read %s: %v", testName, v.root, err)
+		}
+
+		libMap, err := a.Analyze(fileMap)
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		for _, libs := range libMap {
+			sort.Slice(libs, func(i, j int) bool {
+				return libs[i].Name < libs[j].Name
+			})
+		}
+		if !reflect.DeepEqual(v.libMap, libMap) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libMap, libMap)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package gradle
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&gradleLibraryAnalyzer{})
+}
+
+type gradleLibraryAnalyzer struct{}
+
+// Analyze parses gradle.lockfile and the per-configuration lockfiles in gradle/dependency-locks/.
+func (a gradleLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		if !isLockfile(filename) {
+			return nil
+		}
+
+		libs, err := parse(bytes.NewBuffer(content))
+		if err != nil {
+			return xerrors.Errorf("invalid %s format: %v: %w", filepath.Base(filename), err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a gradleLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"gradle.lockfile", "*.lockfile"}
+}
+
+func isLockfile(filename string) bool {
+	if filepath.Base(filename) == "gradle.lockfile" {
+		return true
+	}
+	// e.g. gradle/dependency-locks/compileClasspath.lockfile
+	return filepath.Ext(filename) == ".lockfile" &&
+		strings.HasSuffix(filepath.Dir(filename), "gradle/dependency-locks")
+}
+
+// parse reads lines like "org.apache.commons:commons-lang3:3.12.0=compileClasspath,runtimeClasspath".
+// Dependencies referenced by multiple configurations appear only once.
+func parse(r io.Reader) ([]types.Library, error) {
+	var libs []types.Library
+	seen := map[types.Library]struct{}{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// e.g. "empty=annotationProcessor,testAnnotationProcessor"
+		if strings.HasPrefix(line, "empty=") {
+			continue
+		}
+		// The per-configuration lockfiles don't have "=<configurations>"
+		coordinate := strings.SplitN(line, "=", 2)[0]
+		parts := strings.Split(coordinate, ":")
+		if len(parts) != 3 {
+			return nil, xerrors.Errorf("invalid dependency: %s", line)
+		}
+		lib := types.Library{
+			Name:    parts[0] + ":" + parts[1],
+			Version: parts[2],
+		}
+		if _, ok := seen[lib]; ok {
+			continue
+		}
+		seen[lib] = struct{}{}
+		libs = append(libs, lib)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("failed to scan: %w", err)
+	}
+	return libs, nil
+}
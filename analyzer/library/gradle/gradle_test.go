@@ -0,0 +1,61 @@
+package gradle
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []types.Library
+	}{
+		"SingleFile": {
+			path: "./testdata/gradle.lockfile",
+			libs: []types.Library{
+				{Name: "com.google.guava:failureaccess", Version: "1.0.1"},
+				{Name: "com.google.guava:guava", Version: "31.1-jre"},
+				{Name: "junit:junit", Version: "4.13.2"},
+				{Name: "org.apache.commons:commons-lang3", Version: "3.12.0"},
+			},
+		},
+		"PerConfiguration": {
+			path: "./testdata/gradle/dependency-locks/compileClasspath.lockfile",
+			libs: []types.Library{
+				{Name: "com.google.guava:failureaccess", Version: "1.0.1"},
+				{Name: "com.google.guava:guava", Version: "31.1-jre"},
+				{Name: "org.apache.commons:commons-lang3", Version: "3.12.0"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
+
+func TestIsLockfile(t *testing.T) {
+	var tests = map[string]bool{
+		"app/gradle.lockfile": true,
+		"app/gradle/dependency-locks/compileClasspath.lockfile": true,
+		"app/other.lockfile": false,
+	}
+	for filename, expected := range tests {
+		if actual := isLockfile(filename); actual != expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", filename, expected, actual)
+		}
+	}
+}
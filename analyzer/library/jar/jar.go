@@ -0,0 +1,275 @@
+package jar
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+var (
+	// MaxUncompressedSize is the limit of the total uncompressed size read from an archive including nested ones.
+	// It guards against zip bombs.
+	MaxUncompressedSize int64 = 1 << 30
+	// MaxDepth is the maximum nesting level of archives, e.g. BOOT-INF/lib/*.jar in Spring Boot jars.
+	MaxDepth = 1
+	// Enabled makes the analyzer require the Java archives. They are not extracted by default, as the archives
+	// of an application can be hundreds of megabytes, which are held in memory.
+	Enabled = false
+	// MaxArchiveSize is the limit of the size of an archive, over which the archive is skipped without being
+	// extracted. See analyzer.FileSizeLimiter.
+	MaxArchiveSize int64 = 100 << 20
+)
+
+var (
+	// e.g. META-INF/maven/org.apache.commons/commons-lang3/pom.properties
+	pomPropertiesRegexp = regexp.MustCompile(`^META-INF/maven/[^/]+/[^/]+/pom\.properties$`)
+	// e.g. commons-lang3-3.9.jar, spring-core-5.1.7.RELEASE.jar
+	jarFileRegexp = regexp.MustCompile(`^(.+?)-(\d[\w.\-]*)\.[jwe]ar$`)
+)
+
+var archivePatterns = []string{"*.jar", "*.war", "*.ear"}
+
+var errSizeLimit = xerrors.New("uncompressed size limit exceeded")
+
+// Confidence of each parsing method. MANIFEST.MF can be hand-authored and file names can be renamed.
+const (
+	pomPropertiesConfidence = 1.0
+	manifestConfidence      = 0.8
+	fileNameConfidence      = 0.5
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&jarLibraryAnalyzer{})
+}
+
+type jarLibraryAnalyzer struct{}
+
+func (a jarLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	findingMap, err := a.AnalyzeFindings(fileMap)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.FindingsToLibraries(findingMap), nil
+}
+
+// AnalyzeFindings identifies Java archives with pom.properties, MANIFEST.MF or the file name in this order.
+// Libraries in nested archives and shaded pom.properties are also returned.
+// Corrupt archives and archives without any metadata are skipped.
+func (a jarLibraryAnalyzer) AnalyzeFindings(fileMap extractor.FileMap) (map[analyzer.FilePath][]analyzer.LibraryFinding, error) {
+	libMap := map[analyzer.FilePath][]analyzer.LibraryFinding{}
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		if !isArchive(filename) {
+			return nil
+		}
+		if int64(len(content)) > MaxArchiveSize {
+			log.Printf("skip %s: %d bytes exceed MaxArchiveSize", filename, len(content))
+			return nil
+		}
+
+		// The zip reader decides whether it is an archive, as "fully executable" jars of Spring Boot
+		// start with the launch script instead of the signature of zip
+		p := parser{remaining: MaxUncompressedSize}
+		libs, err := p.parse(filepath.Base(filename), content, 0)
+		if xerrors.Is(err, zip.ErrFormat) {
+			// e.g. a text file named *.jar
+			return nil
+		} else if err != nil {
+			log.Printf("failed to analyze %s: %s", filename, err)
+			return nil
+		}
+		if len(libs) == 0 {
+			return nil
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+// RequiredFiles returns the Java archives only if Enabled.
+func (a jarLibraryAnalyzer) RequiredFiles() []string {
+	if !Enabled {
+		return nil
+	}
+	return archivePatterns
+}
+
+func (a jarLibraryAnalyzer) FileSizeLimits() map[string]int64 {
+	limits := map[string]int64{}
+	for _, pattern := range archivePatterns {
+		limits[pattern] = MaxArchiveSize
+	}
+	return limits
+}
+
+func isArchive(filename string) bool {
+	switch filepath.Ext(filename) {
+	case ".jar", ".war", ".ear":
+		return true
+	}
+	return false
+}
+
+type parser struct {
+	// remaining is the uncompressed size which can be still read
+	remaining int64
+}
+
+func (p *parser) parse(filename string, content []byte, depth int) ([]analyzer.LibraryFinding, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, xerrors.Errorf("invalid zip: %w", err)
+	}
+
+	artifactName, artifactVersion := parseFileName(filename)
+
+	var pomLibs, nestedLibs []analyzer.LibraryFinding
+	var manifestLib types.Library
+	for _, f := range zr.File {
+		switch {
+		case pomPropertiesRegexp.MatchString(f.Name):
+			props, err := p.readProperties(f)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to read %s: %w", f.Name, err)
+			}
+			if props["groupId"] == "" || props["artifactId"] == "" || props["version"] == "" {
+				continue
+			}
+			lib := analyzer.LibraryFinding{
+				Library: types.Library{
+					Name:    fmt.Sprintf("%s:%s", props["groupId"], props["artifactId"]),
+					Version: props["version"],
+				},
+				Confidence: pomPropertiesConfidence,
+				Source:     "pom.properties",
+			}
+			// The pom.properties of the archive itself comes first
+			if props["artifactId"] == artifactName {
+				pomLibs = append([]analyzer.LibraryFinding{lib}, pomLibs...)
+			} else {
+				pomLibs = append(pomLibs, lib)
+			}
+		case f.Name == "META-INF/MANIFEST.MF":
+			attrs, err := p.readProperties(f)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to read %s: %w", f.Name, err)
+			}
+			manifestLib = types.Library{
+				Name:    attrs["Implementation-Title"],
+				Version: attrs["Implementation-Version"],
+			}
+		case isArchive(f.Name) && depth < MaxDepth:
+			// e.g. BOOT-INF/lib/spring-core-5.1.7.RELEASE.jar
+			b, err := p.read(f)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to read %s: %w", f.Name, err)
+			}
+			libs, err := p.parse(filepath.Base(f.Name), b, depth+1)
+			if xerrors.Is(err, errSizeLimit) {
+				return nil, err
+			} else if err != nil {
+				log.Printf("failed to analyze %s in %s: %s", f.Name, filename, err)
+				continue
+			}
+			nestedLibs = append(nestedLibs, libs...)
+		}
+	}
+
+	var libs []analyzer.LibraryFinding
+	switch {
+	case len(pomLibs) > 0:
+		libs = pomLibs
+	case manifestLib.Name != "" && manifestLib.Version != "":
+		libs = []analyzer.LibraryFinding{{Library: manifestLib, Confidence: manifestConfidence, Source: "MANIFEST.MF"}}
+	case artifactName != "" && artifactVersion != "":
+		libs = []analyzer.LibraryFinding{{
+			Library:    types.Library{Name: artifactName, Version: artifactVersion},
+			Confidence: fileNameConfidence,
+			Source:     "filename",
+		}}
+	}
+	return uniqueLibs(append(libs, nestedLibs...)), nil
+}
+
+// read reads the file within the limit of the uncompressed size
+func (p *parser) read(f *zip.File) ([]byte, error) {
+	if int64(f.UncompressedSize64) > p.remaining {
+		return nil, errSizeLimit
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// The header can lie about the size
+	b, err := ioutil.ReadAll(io.LimitReader(rc, p.remaining+1))
+	if err != nil {
+		return nil, err
+	}
+	p.remaining -= int64(len(b))
+	if p.remaining < 0 {
+		return nil, errSizeLimit
+	}
+	return b, nil
+}
+
+// readProperties parses "key=value" in pom.properties and "Key: Value" in MANIFEST.MF.
+func (p *parser) readProperties(f *zip.File) (map[string]string, error) {
+	b, err := p.read(f)
+	if err != nil {
+		return nil, err
+	}
+
+	props := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewBuffer(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			continue
+		}
+		props[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+	return props, scanner.Err()
+}
+
+func parseFileName(filename string) (string, string) {
+	m := jarFileRegexp.FindStringSubmatch(filename)
+	if m == nil {
+		return strings.TrimSuffix(filename, filepath.Ext(filename)), ""
+	}
+	return m[1], m[2]
+}
+
+func uniqueLibs(libs []analyzer.LibraryFinding) []analyzer.LibraryFinding {
+	var uniq []analyzer.LibraryFinding
+	seen := map[types.Library]struct{}{}
+	for _, lib := range libs {
+		if _, ok := seen[lib.Library]; ok {
+			continue
+		}
+		seen[lib.Library] = struct{}{}
+		uniq = append(uniq, lib)
+	}
+	return uniq
+}
@@ -0,0 +1,204 @@
+package jar
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestAnalyze(t *testing.T) {
+	var tests = map[string]struct {
+		paths               []string
+		maxUncompressedSize int64
+		libMap              map[analyzer.FilePath][]types.Library
+	}{
+		"PomProperties": {
+			paths: []string{"./testdata/commons-lang3-3.9.jar"},
+			libMap: map[analyzer.FilePath][]types.Library{
+				"app/commons-lang3-3.9.jar": {{Name: "org.apache.commons:commons-lang3", Version: "3.9"}},
+			},
+		},
+		"Manifest": {
+			paths: []string{"./testdata/app.war"},
+			libMap: map[analyzer.FilePath][]types.Library{
+				"app/app.war": {{Name: "sample-app", Version: "1.0.0-SNAPSHOT"}},
+			},
+		},
+		"FileName": {
+			paths: []string{"./testdata/guava-27.1-jre.jar"},
+			libMap: map[analyzer.FilePath][]types.Library{
+				"app/guava-27.1-jre.jar": {{Name: "guava", Version: "27.1-jre"}},
+			},
+		},
+		"SpringBoot": {
+			paths: []string{"./testdata/demo-0.0.1-SNAPSHOT.jar"},
+			libMap: map[analyzer.FilePath][]types.Library{
+				"app/demo-0.0.1-SNAPSHOT.jar": {
+					{Name: "com.example:demo", Version: "0.0.1-SNAPSHOT"},
+					{Name: "spring-core", Version: "5.1.7.RELEASE"},
+					{Name: "com.fasterxml.jackson.core:jackson-databind", Version: "2.9.8"},
+					{Name: "org.example:nested", Version: "1.0"},
+				},
+			},
+		},
+		"Shaded": {
+			paths: []string{"./testdata/shaded-2.0.jar"},
+			libMap: map[analyzer.FilePath][]types.Library{
+				"app/shaded-2.0.jar": {
+					{Name: "com.example:shaded", Version: "2.0"},
+					{Name: "com.google.guava:guava", Version: "27.1-jre"},
+				},
+			},
+		},
+		"SizeLimit": {
+			paths:               []string{"./testdata/demo-0.0.1-SNAPSHOT.jar"},
+			maxUncompressedSize: 1024,
+			libMap:              map[analyzer.FilePath][]types.Library{},
+		},
+		"Skipped": {
+			paths:  []string{"./testdata/nometa.jar", "./testdata/corrupt.jar"},
+			libMap: map[analyzer.FilePath][]types.Library{},
+		},
+	}
+	defaultSize := MaxUncompressedSize
+	defer func() { MaxUncompressedSize = defaultSize }()
+
+	a := jarLibraryAnalyzer{}
+	for testName, v := range tests {
+		fileMap := extractor.MapFileMap{}
+		for _, path := range v.paths {
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%s : can't open file %s", testName, path)
+			}
+			fileMap["app/"+filepath.Base(path)] = b
+		}
+
+		MaxUncompressedSize = defaultSize
+		if v.maxUncompressedSize > 0 {
+			MaxUncompressedSize = v.maxUncompressedSize
+		}
+		libMap, err := a.Analyze(fileMap)
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libMap, libMap) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libMap, libMap)
+		}
+	}
+}
+
+// The "fully executable" jar of Spring Boot starts with the launch script
+func TestAnalyze_ExecutableJar(t *testing.T) {
+	b, err := ioutil.ReadFile("./testdata/commons-lang3-3.9.jar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/bash\n#\n# Spring Boot launch script\nexec java -jar \"$0\" \"$@\"\nexit 0\n"
+	fileMap := extractor.MapFileMap{"app/commons-lang3-3.9.jar": append([]byte(script), b...)}
+
+	libMap, err := jarLibraryAnalyzer{}.Analyze(fileMap)
+	if err != nil {
+		t.Errorf("catch the error : %v", err)
+	}
+	expected := map[analyzer.FilePath][]types.Library{
+		"app/commons-lang3-3.9.jar": {{Name: "org.apache.commons:commons-lang3", Version: "3.9"}},
+	}
+	if !reflect.DeepEqual(expected, libMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, libMap)
+	}
+}
+
+func TestAnalyze_MaxArchiveSize(t *testing.T) {
+	b, err := ioutil.ReadFile("./testdata/commons-lang3-3.9.jar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	origMaxArchiveSize := MaxArchiveSize
+	defer func() { MaxArchiveSize = origMaxArchiveSize }()
+	MaxArchiveSize = int64(len(b)) - 1
+
+	libMap, err := jarLibraryAnalyzer{}.Analyze(extractor.MapFileMap{"app/commons-lang3-3.9.jar": b})
+	if err != nil {
+		t.Errorf("catch the error : %v", err)
+	}
+	if len(libMap) != 0 {
+		t.Errorf("the archive larger than MaxArchiveSize is expected to be skipped: %v", libMap)
+	}
+}
+
+func TestRequiredFiles(t *testing.T) {
+	origEnabled := Enabled
+	defer func() { Enabled = origEnabled }()
+
+	var tests = map[string]struct {
+		enabled  bool
+		expected []string
+	}{
+		"Disabled": {},
+		"Enabled":  {enabled: true, expected: []string{"*.jar", "*.war", "*.ear"}},
+	}
+	for testName, v := range tests {
+		Enabled = v.enabled
+		if actual := (jarLibraryAnalyzer{}).RequiredFiles(); !reflect.DeepEqual(v.expected, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+	expected := map[string]int64{"*.jar": MaxArchiveSize, "*.war": MaxArchiveSize, "*.ear": MaxArchiveSize}
+	if actual := (jarLibraryAnalyzer{}).FileSizeLimits(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+}
+
+func TestAnalyzeFindings(t *testing.T) {
+	var tests = map[string]struct {
+		path     string
+		findings []analyzer.LibraryFinding
+	}{
+		"PomProperties": {
+			path: "./testdata/commons-lang3-3.9.jar",
+			findings: []analyzer.LibraryFinding{{
+				Library:    types.Library{Name: "org.apache.commons:commons-lang3", Version: "3.9"},
+				Confidence: 1.0,
+				Source:     "pom.properties",
+			}},
+		},
+		"Manifest": {
+			path: "./testdata/app.war",
+			findings: []analyzer.LibraryFinding{{
+				Library:    types.Library{Name: "sample-app", Version: "1.0.0-SNAPSHOT"},
+				Confidence: 0.8,
+				Source:     "MANIFEST.MF",
+			}},
+		},
+		"FileName": {
+			path: "./testdata/guava-27.1-jre.jar",
+			findings: []analyzer.LibraryFinding{{
+				Library:    types.Library{Name: "guava", Version: "27.1-jre"},
+				Confidence: 0.5,
+				Source:     "filename",
+			}},
+		},
+	}
+
+	a := jarLibraryAnalyzer{}
+	for testName, v := range tests {
+		b, err := ioutil.ReadFile(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		filePath := "app/" + filepath.Base(v.path)
+		findingMap, err := a.AnalyzeFindings(extractor.MapFileMap{filePath: b})
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.findings, findingMap[analyzer.FilePath(filePath)]) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.findings, findingMap[analyzer.FilePath(filePath)])
+		}
+	}
+}
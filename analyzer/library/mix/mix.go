@@ -0,0 +1,74 @@
+package mix
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/fanal/utils"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+// e.g. "plug": {:hex, :plug, "1.15.3", "712976f5...", [:mix], [...], "hexpm", "cc4365a3..."},
+// The key can differ from the package name, e.g. "my_plug": {:hex, :plug, ...}
+var hexRegexp = regexp.MustCompile(`\{\s*:hex\s*,\s*:"?([\w.]+)"?\s*,\s*"([^"]+)"`)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&mixLibraryAnalyzer{})
+}
+
+type mixLibraryAnalyzer struct{}
+
+func (a mixLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !utils.StringInSlice(basename, requiredFiles) {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid mix.lock format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a mixLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"mix.lock"}
+}
+
+// parse extracts the name and version of hex packages.
+// :git and :path dependencies are skipped as they don't have versions.
+func parse(r io.Reader) ([]types.Library, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("read error: %w", err)
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("%{")) {
+		return nil, xerrors.New("not an Elixir map")
+	}
+
+	var libs []types.Library
+	for _, m := range hexRegexp.FindAllSubmatch(b, -1) {
+		libs = append(libs, types.Library{
+			Name:    string(m[1]),
+			Version: string(m[2]),
+		})
+	}
+	return libs, nil
+}
@@ -0,0 +1,135 @@
+package nodepkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+const nodeModules = "node_modules"
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&nodePkgLibraryAnalyzer{})
+}
+
+type nodePkgLibraryAnalyzer struct{}
+
+// Analyze detects packages installed in node_modules even if the lockfile was pruned.
+// Results are grouped by the application root, which is the directory containing the top-level node_modules.
+func (a nodePkgLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+	detected := map[analyzer.FilePath]map[types.Library]struct{}{}
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		if !extractor.MatchAny(requiredFiles, filename) {
+			return nil
+		}
+		appRoot, ok := appRootPath(filename)
+		if !ok {
+			return nil
+		}
+
+		lib, err := parsePackageJSON(content)
+		if err != nil {
+			log.Printf("invalid package.json format: %s: %s", filename, err)
+			return nil
+		}
+		if lib.Name == "" || lib.Version == "" {
+			return nil
+		}
+
+		if _, ok := detected[appRoot]; !ok {
+			detected[appRoot] = map[types.Library]struct{}{}
+		}
+		if _, ok := detected[appRoot][lib]; ok {
+			return nil
+		}
+		detected[appRoot][lib] = struct{}{}
+		libMap[appRoot] = append(libMap[appRoot], lib)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a nodePkgLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"**/node_modules/*/package.json", "**/node_modules/@*/*/package.json"}
+}
+
+// appRootPath returns the directory containing the top-level node_modules
+// if the file is node_modules/<name>/package.json or node_modules/@<scope>/<name>/package.json.
+func appRootPath(filename string) (analyzer.FilePath, bool) {
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(filename)), "/")
+	n := len(dirs)
+	switch {
+	case n >= 2 && dirs[n-2] == nodeModules && !strings.HasPrefix(dirs[n-1], "@"):
+	case n >= 3 && dirs[n-3] == nodeModules && strings.HasPrefix(dirs[n-2], "@"):
+	default:
+		return "", false
+	}
+
+	for i, dir := range dirs {
+		if dir == nodeModules {
+			root := strings.Join(dirs[:i], "/")
+			if root == "" {
+				root = "."
+			}
+			return analyzer.FilePath(root), true
+		}
+	}
+	return "", false
+}
+
+// parsePackageJSON reads only the top-level "name" and "version" and stops as soon as both are found,
+// so that large fields such as "readme" don't need to be decoded.
+func parsePackageJSON(content []byte) (types.Library, error) {
+	var lib types.Library
+	dec := json.NewDecoder(bytes.NewReader(content))
+	if t, err := dec.Token(); err != nil {
+		return lib, xerrors.Errorf("failed to read token: %w", err)
+	} else if t != json.Delim('{') {
+		return lib, xerrors.New("not a JSON object")
+	}
+
+	for dec.More() && (lib.Name == "" || lib.Version == "") {
+		t, err := dec.Token()
+		if err != nil {
+			return lib, xerrors.Errorf("failed to read key: %w", err)
+		}
+		key, ok := t.(string)
+		if !ok {
+			return lib, xerrors.Errorf("invalid key: %v", t)
+		}
+
+		switch key {
+		case "name", "version":
+			var value interface{}
+			if err = dec.Decode(&value); err != nil {
+				return lib, xerrors.Errorf("failed to decode %s: %w", key, err)
+			}
+			// e.g. "version": {...} in malformed files
+			s, _ := value.(string)
+			if key == "name" {
+				lib.Name = s
+			} else {
+				lib.Version = s
+			}
+		default:
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				return lib, xerrors.Errorf("failed to decode %s: %w", key, err)
+			}
+		}
+	}
+	return lib, nil
+}
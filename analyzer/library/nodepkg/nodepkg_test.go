@@ -0,0 +1,63 @@
+package nodepkg
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestAnalyze(t *testing.T) {
+	var tests = map[string]struct {
+		fileMap extractor.FileMap
+		libMap  map[analyzer.FilePath][]types.Library
+	}{
+		"Installed": {
+			fileMap: extractor.MapFileMap{
+				"usr/src/app/package.json":                                      []byte(`{"name": "app", "version": "1.0.0"}`),
+				"usr/src/app/node_modules/express/package.json":                 []byte(`{"name": "express", "version": "4.17.1", "readme": "..."}`),
+				"usr/src/app/node_modules/@babel/core/package.json":             []byte(`{"_from": "@babel/core", "version": "7.4.4", "name": "@babel/core"}`),
+				"usr/src/app/node_modules/express/node_modules/ms/package.json": []byte(`{"name": "ms", "version": "2.0.0"}`),
+				"usr/src/app/node_modules/debug/node_modules/ms/package.json":   []byte(`{"name": "ms", "version": "2.0.0"}`),
+				"usr/src/app/node_modules/express/lib/package.json":             []byte(`{"name": "lib", "version": "0.0.1"}`),
+				"usr/src/app/node_modules/noversion/package.json":               []byte(`{"name": "noversion"}`),
+				"usr/src/app/node_modules/broken/package.json":                  []byte(`{"name": `),
+				"node_modules/npm/package.json":                                 []byte(`{"name": "npm", "version": "6.9.0"}`),
+			},
+			libMap: map[analyzer.FilePath][]types.Library{
+				"usr/src/app": {
+					{Name: "@babel/core", Version: "7.4.4"},
+					{Name: "express", Version: "4.17.1"},
+					{Name: "ms", Version: "2.0.0"},
+				},
+				".": {
+					{Name: "npm", Version: "6.9.0"},
+				},
+			},
+		},
+		"NoNodeModules": {
+			fileMap: extractor.MapFileMap{
+				"usr/src/app/package.json": []byte(`{"name": "app", "version": "1.0.0"}`),
+			},
+			libMap: map[analyzer.FilePath][]types.Library{},
+		},
+	}
+	a := nodePkgLibraryAnalyzer{}
+	for testName, v := range tests {
+		libMap, err := a.Analyze(v.fileMap)
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		for _, libs := range libMap {
+			sort.Slice(libs, func(i, j int) bool {
+				return libs[i].Name < libs[j].Name
+			})
+		}
+		if !reflect.DeepEqual(v.libMap, libMap) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libMap, libMap)
+		}
+	}
+}
@@ -2,42 +2,230 @@ package npm
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
+	"log"
 	"path/filepath"
+	"regexp"
+	"sort"
 
 	"github.com/knqyf263/fanal/analyzer"
 	"github.com/knqyf263/fanal/extractor"
-	"github.com/knqyf263/fanal/utils"
-	"github.com/knqyf263/go-dep-parser/pkg/npm"
 	"github.com/knqyf263/go-dep-parser/pkg/types"
 	"golang.org/x/xerrors"
 )
 
+const lockFileName = "package-lock.json"
+
+var (
+	// e.g. "4.17.1", "=4.17.1", "v4.17.1", "1.0.0-beta.1"
+	exactVersionRegexp = regexp.MustCompile(`^[=v]?\d+\.\d+\.\d+(?:[-+][0-9A-Za-z.+-]*)?$`)
+	// e.g. "file:../foo", "git+https://github.com/foo/bar.git", "github:foo/bar", "npm:bar@1.0.0"
+	nonRegistryRegexp = regexp.MustCompile(`^(?:[a-z+]+:|\.{0,2}/)`)
+)
+
 func init() {
 	analyzer.RegisterLibraryAnalyzer(&npmLibraryAnalyzer{})
 }
 
+type lockFile struct {
+	Dependencies map[string]dependency `json:"dependencies"`
+}
+
+type dependency struct {
+	Version      string                `json:"version"`
+	Dev          bool                  `json:"dev"`
+	Requires     map[string]string     `json:"requires"`
+	Dependencies map[string]dependency `json:"dependencies"`
+}
+
+// packageJSON is used to tell the direct dependencies.
+type packageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
 type npmLibraryAnalyzer struct{}
 
 func (a npmLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
-	libMap := map[analyzer.FilePath][]types.Library{}
-	requiredFiles := a.RequiredFiles()
+	findingMap, err := a.AnalyzeFindings(fileMap)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.FindingsToLibraries(findingMap), nil
+}
 
-	for filename, content := range fileMap {
-		basename := filepath.Base(filename)
-		if !utils.StringInSlice(basename, requiredFiles) {
-			continue
+// AnalyzeFindings marks libraries with "dev": true as dev dependencies and returns the dependency graph.
+// The direct dependencies are taken from package.json in the same directory if it exists.
+func (a npmLibraryAnalyzer) AnalyzeFindings(fileMap extractor.FileMap) (map[analyzer.FilePath][]analyzer.LibraryFinding, error) {
+	libMap := map[analyzer.FilePath][]analyzer.LibraryFinding{}
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		if filepath.Base(filename) != lockFileName {
+			return nil
+		}
+
+		var direct map[string]bool
+		if b, ok := fileMap.Get(filepath.Join(filepath.Dir(filename), "package.json")); ok {
+			var err error
+			if direct, err = parseDirect(b); err != nil {
+				log.Printf("invalid package.json format: %s: %s", filename, err)
+			}
 		}
 
 		r := bytes.NewBuffer(content)
-		libs, err := npm.Parse(r)
+		libs, err := parse(r, direct)
 		if err != nil {
-			return nil, xerrors.Errorf("invalid package-lock.json format: %w", err)
+			return xerrors.Errorf("invalid package-lock.json format: %v: %w", err, analyzer.ErrMalformedFile)
 		}
 		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return libMap, nil
 }
 
+// AnalyzePinning returns the dependencies in package.json whose versions are ranges or tags such as "latest".
+// package.json with package-lock.json in the same directory is skipped as the versions are locked.
+// Peer dependencies are not included as they are provided by the dependent.
+func (a npmLibraryAnalyzer) AnalyzePinning(fileMap extractor.FileMap) []analyzer.PinningWarning {
+	var warnings []analyzer.PinningWarning
+	for _, filename := range fileMap.Paths() {
+		if filepath.Base(filename) != "package.json" {
+			continue
+		}
+		if _, ok := fileMap.Get(filepath.Join(filepath.Dir(filename), lockFileName)); ok {
+			continue
+		}
+		content, _ := fileMap.Get(filename)
+
+		var pkg packageJSON
+		if err := json.Unmarshal(content, &pkg); err != nil {
+			log.Printf("invalid package.json format: %s: %s", filename, err)
+			continue
+		}
+		for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies} {
+			for name, constraint := range deps {
+				if exactVersionRegexp.MatchString(constraint) || nonRegistryRegexp.MatchString(constraint) {
+					continue
+				}
+				warnings = append(warnings, analyzer.PinningWarning{
+					FilePath:   filename,
+					Dependency: name,
+					Constraint: constraint,
+				})
+			}
+		}
+	}
+	return warnings
+}
+
 func (a npmLibraryAnalyzer) RequiredFiles() []string {
-	return []string{"package-lock.json"}
+	return []string{lockFileName, "package.json"}
+}
+
+func parseDirect(content []byte) (map[string]bool, error) {
+	var pkg packageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+	direct := map[string]bool{}
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies, pkg.PeerDependencies} {
+		for name := range deps {
+			direct[name] = true
+		}
+	}
+	return direct, nil
+}
+
+// parse walks nested dependencies as well.
+// A library required by both production and dev dependencies is not marked as dev.
+// Without the names of the direct dependencies, top-level libraries which no library requires are regarded as direct.
+func parse(r io.Reader, direct map[string]bool) ([]analyzer.LibraryFinding, error) {
+	var lock lockFile
+	if err := json.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	findings := map[string]*analyzer.LibraryFinding{}
+	walkDependencies(lock.Dependencies, nil, findings)
+
+	required := map[string]bool{}
+	for _, f := range findings {
+		for _, id := range f.DependsOn {
+			required[id] = true
+		}
+	}
+	for name, d := range lock.Dependencies {
+		id := packageID(name, d.Version)
+		if direct != nil {
+			findings[id].Direct = direct[name]
+		} else {
+			findings[id].Direct = !required[id]
+		}
+	}
+
+	var libs []analyzer.LibraryFinding
+	for _, f := range findings {
+		sort.Strings(f.DependsOn)
+		libs = append(libs, *f)
+	}
+	sort.Slice(libs, func(i, j int) bool {
+		if libs[i].Library.Name != libs[j].Library.Name {
+			return libs[i].Library.Name < libs[j].Library.Name
+		}
+		return libs[i].Library.Version < libs[j].Library.Version
+	})
+	return libs, nil
+}
+
+// walkDependencies records each library with the libraries it requires.
+// A required library is looked up from the nested dependencies up to the top level like node_modules.
+// scopes are the dependencies of the ancestors, the innermost first.
+func walkDependencies(deps map[string]dependency, scopes []map[string]dependency, findings map[string]*analyzer.LibraryFinding) {
+	scopes = append([]map[string]dependency{deps}, scopes...)
+	for name, d := range deps {
+		id := packageID(name, d.Version)
+		f, ok := findings[id]
+		if !ok {
+			f = &analyzer.LibraryFinding{
+				Library:    types.Library{Name: name, Version: d.Version},
+				Confidence: 1,
+				Source:     lockFileName,
+				Dev:        true,
+				ID:         id,
+			}
+			findings[id] = f
+		}
+		f.Dev = f.Dev && d.Dev
+
+		lookup := append([]map[string]dependency{d.Dependencies}, scopes...)
+		for reqName := range d.Requires {
+			for _, scope := range lookup {
+				if req, ok := scope[reqName]; ok {
+					f.DependsOn = appendUnique(f.DependsOn, packageID(reqName, req.Version))
+					break
+				}
+			}
+		}
+
+		walkDependencies(d.Dependencies, scopes, findings)
+	}
+}
+
+func appendUnique(ids []string, id string) []string {
+	for _, i := range ids {
+		if i == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+func packageID(name, version string) string {
+	return name + "@" + version
 }
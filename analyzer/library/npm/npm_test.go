@@ -0,0 +1,161 @@
+package npm
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	atesting "github.com/knqyf263/fanal/analyzer/testing"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path   string
+		direct map[string]bool
+		libs   []analyzer.LibraryFinding
+	}{
+		"WithoutPackageJSON": {
+			path: "./testdata/package-lock.json",
+			libs: []analyzer.LibraryFinding{
+				{
+					Library: types.Library{Name: "debug", Version: "2.6.9"}, Confidence: 1, Source: "package-lock.json",
+					ID: "debug@2.6.9", DependsOn: []string{"ms@2.0.0"},
+				},
+				{
+					Library: types.Library{Name: "debug", Version: "3.2.6"}, Confidence: 1, Source: "package-lock.json", Dev: true,
+					ID: "debug@3.2.6",
+				},
+				{
+					Library: types.Library{Name: "express", Version: "4.17.1"}, Confidence: 1, Source: "package-lock.json",
+					ID: "express@4.17.1", Direct: true, DependsOn: []string{"debug@2.6.9"},
+				},
+				{
+					Library: types.Library{Name: "mocha", Version: "6.1.4"}, Confidence: 1, Source: "package-lock.json", Dev: true,
+					ID: "mocha@6.1.4", Direct: true, DependsOn: []string{"debug@3.2.6", "ms@2.1.1"},
+				},
+				{
+					Library: types.Library{Name: "ms", Version: "2.0.0"}, Confidence: 1, Source: "package-lock.json",
+					ID: "ms@2.0.0",
+				},
+				{
+					Library: types.Library{Name: "ms", Version: "2.1.1"}, Confidence: 1, Source: "package-lock.json", Dev: true,
+					ID: "ms@2.1.1",
+				},
+			},
+		},
+		"WithPackageJSON": {
+			path:   "./testdata/package-lock.json",
+			direct: map[string]bool{"debug": true},
+			libs: []analyzer.LibraryFinding{
+				{
+					Library: types.Library{Name: "debug", Version: "2.6.9"}, Confidence: 1, Source: "package-lock.json",
+					ID: "debug@2.6.9", Direct: true, DependsOn: []string{"ms@2.0.0"},
+				},
+				{
+					Library: types.Library{Name: "debug", Version: "3.2.6"}, Confidence: 1, Source: "package-lock.json", Dev: true,
+					ID: "debug@3.2.6",
+				},
+				{
+					Library: types.Library{Name: "express", Version: "4.17.1"}, Confidence: 1, Source: "package-lock.json",
+					ID: "express@4.17.1", DependsOn: []string{"debug@2.6.9"},
+				},
+				{
+					Library: types.Library{Name: "mocha", Version: "6.1.4"}, Confidence: 1, Source: "package-lock.json", Dev: true,
+					ID: "mocha@6.1.4", DependsOn: []string{"debug@3.2.6", "ms@2.1.1"},
+				},
+				{
+					Library: types.Library{Name: "ms", Version: "2.0.0"}, Confidence: 1, Source: "package-lock.json",
+					ID: "ms@2.0.0",
+				},
+				{
+					Library: types.Library{Name: "ms", Version: "2.1.1"}, Confidence: 1, Source: "package-lock.json", Dev: true,
+					ID: "ms@2.1.1",
+				},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f, v.direct)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
+
+func TestAnalyzeFindings(t *testing.T) {
+	fileMap := extractor.MapFileMap{}
+	for path, testdata := range map[string]string{
+		"app/package-lock.json": "./testdata/package-lock.json",
+		"app/package.json":      "./testdata/package.json",
+		"other/package.json":    "./testdata/package.json",
+	} {
+		b, err := ioutil.ReadFile(testdata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileMap[path] = b
+	}
+
+	a := npmLibraryAnalyzer{}
+	libMap, err := a.AnalyzeFindings(fileMap)
+	if err != nil {
+		t.Errorf("catch the error : %v", err)
+	}
+	if len(libMap) != 1 {
+		t.Fatalf("only package-lock.json should be analyzed: %v", libMap)
+	}
+	var direct []string
+	for _, lib := range libMap["app/package-lock.json"] {
+		if lib.Direct {
+			direct = append(direct, lib.ID)
+		}
+	}
+	expected := []string{"express@4.17.1", "mocha@6.1.4"}
+	if !reflect.DeepEqual(expected, direct) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, direct)
+	}
+}
+
+func TestAnalyzePinning(t *testing.T) {
+	fileMap := extractor.MapFileMap{}
+	for path, testdata := range map[string]string{
+		"app/package-lock.json": "./testdata/package-lock.json",
+		"app/package.json":      "./testdata/package_unpinned.json",
+		"other/package.json":    "./testdata/package_unpinned.json",
+	} {
+		b, err := ioutil.ReadFile(testdata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileMap[path] = b
+	}
+
+	a := npmLibraryAnalyzer{}
+	warnings := a.AnalyzePinning(fileMap)
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Dependency < warnings[j].Dependency })
+	expected := []analyzer.PinningWarning{
+		{FilePath: "other/package.json", Dependency: "debug", Constraint: "latest"},
+		{FilePath: "other/package.json", Dependency: "lodash", Constraint: "^4.0.0"},
+		{FilePath: "other/package.json", Dependency: "mocha", Constraint: "~6.1.4"},
+	}
+	if !reflect.DeepEqual(expected, warnings) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, warnings)
+	}
+}
+
+func BenchmarkNpm(b *testing.B) {
+	atesting.RunAnalyzerBenchmark(b, npmLibraryAnalyzer{}, map[string]string{"app/package-lock.json": "testdata/package-lock.json"})
+}
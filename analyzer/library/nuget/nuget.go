@@ -0,0 +1,99 @@
+package nuget
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/fanal/utils"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&nugetLibraryAnalyzer{})
+}
+
+type lockFile struct {
+	// target framework => package name => dependency
+	Dependencies map[string]map[string]dependency
+}
+
+type dependency struct {
+	Type     string // Direct, Transitive, CentralTransitive or Project
+	Resolved string
+}
+
+type nugetLibraryAnalyzer struct{}
+
+func (a nugetLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !utils.StringInSlice(basename, requiredFiles) {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid packages.lock.json format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a nugetLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"packages.lock.json"}
+}
+
+// parse returns one library per package and resolved version across all the target frameworks.
+// Project references are skipped as they are not NuGet packages.
+func parse(r io.Reader) ([]types.Library, error) {
+	var lock lockFile
+	if err := json.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	var frameworks []string
+	for framework := range lock.Dependencies {
+		frameworks = append(frameworks, framework)
+	}
+	sort.Strings(frameworks)
+
+	var libs []types.Library
+	seen := map[types.Library]struct{}{}
+	for _, framework := range frameworks {
+		deps := lock.Dependencies[framework]
+		var names []string
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			dep := deps[name]
+			if dep.Type == "Project" || dep.Resolved == "" {
+				continue
+			}
+			lib := types.Library{Name: name, Version: dep.Resolved}
+			if _, ok := seen[lib]; ok {
+				continue
+			}
+			seen[lib] = struct{}{}
+			libs = append(libs, lib)
+		}
+	}
+	return libs, nil
+}
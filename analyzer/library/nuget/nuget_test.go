@@ -0,0 +1,40 @@
+package nuget
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []types.Library
+	}{
+		"MultiTargetFrameworks": {
+			path: "./testdata/packages.lock.json",
+			libs: []types.Library{
+				{Name: "Newtonsoft.Json", Version: "13.0.1"},
+				{Name: "Serilog", Version: "2.12.0"},
+				{Name: "System.Memory", Version: "4.5.4"},
+				{Name: "Serilog", Version: "3.1.1"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
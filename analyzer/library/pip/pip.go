@@ -0,0 +1,168 @@
+package pip
+
+import (
+	"bufio"
+	"bytes"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+var (
+	// e.g. "requests==2.31.0", "requests[security] == 2.31.0", "requests===2.31.0"
+	pinnedRegexp = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*===?\s*([^\s*,]+)$`)
+	// e.g. "requests", "requests>=2.0", "requests~=2.31.0", "requests==2.*"
+	unpinnedRegexp = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*((?:(?:[<>!~]=?|===?)\s*[^\s,]+\s*,?\s*)*)$`)
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&pipLibraryAnalyzer{})
+}
+
+type pipLibraryAnalyzer struct{}
+
+// Analyze parses requirements files on a best-effort basis.
+// Lines that can't be parsed are skipped so that the other files are still analyzed.
+func (a pipLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !matchAny(requiredFiles, basename) {
+			return nil
+		}
+		if meta, _ := fileMap.Metadata(filename); !extractor.IsText(meta.MIMEType) {
+			return nil
+		}
+
+		libs := parse(filename, content)
+		if len(libs) == 0 {
+			return nil
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+// AnalyzePinning returns the requirements which don't pin the version with "==" or "===".
+func (a pipLibraryAnalyzer) AnalyzePinning(fileMap extractor.FileMap) []analyzer.PinningWarning {
+	var warnings []analyzer.PinningWarning
+	requiredFiles := a.RequiredFiles()
+	for _, filename := range fileMap.Paths() {
+		if !matchAny(requiredFiles, filepath.Base(filename)) {
+			continue
+		}
+		if meta, _ := fileMap.Metadata(filename); !extractor.IsText(meta.MIMEType) {
+			continue
+		}
+		content, _ := fileMap.Get(filename)
+		warnings = append(warnings, parseUnpinned(filename, content)...)
+	}
+	return warnings
+}
+
+func (a pipLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"requirements*.txt"}
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func parse(filename string, content []byte) []types.Library {
+	var libs []types.Library
+	for _, line := range joinLines(content) {
+		lib, ok := parseLine(line)
+		if !ok && !isIgnorable(line) {
+			log.Printf("Unsupported requirement line: %s: %s", filename, line)
+		}
+		if ok {
+			libs = append(libs, lib)
+		}
+	}
+	return libs
+}
+
+func parseUnpinned(filename string, content []byte) []analyzer.PinningWarning {
+	var warnings []analyzer.PinningWarning
+	for _, line := range joinLines(content) {
+		line = stripLine(line)
+		if pinnedRegexp.MatchString(line) {
+			continue
+		}
+		m := unpinnedRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		warnings = append(warnings, analyzer.PinningWarning{
+			FilePath:   filename,
+			Dependency: m[1],
+			Constraint: strings.Join(strings.Fields(m[2]), ""),
+		})
+	}
+	return warnings
+}
+
+// joinLines returns the lines joining continuation lines.
+func joinLines(content []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewBuffer(content))
+	var line string
+	for scanner.Scan() {
+		line += scanner.Text()
+		if strings.HasSuffix(line, `\`) {
+			line = strings.TrimSuffix(line, `\`) + " "
+			continue
+		}
+		lines = append(lines, line)
+		line = ""
+	}
+	return lines
+}
+
+// parseLine returns the library if the version is pinned.
+func parseLine(line string) (types.Library, bool) {
+	line = stripLine(line)
+	m := pinnedRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return types.Library{}, false
+	}
+	return types.Library{Name: m[1], Version: m[2]}, true
+}
+
+// stripLine removes comments, environment markers and per-requirement options such as --hash.
+func stripLine(line string) string {
+	if i := strings.Index(line, "#"); i == 0 || i > 0 && (line[i-1] == ' ' || line[i-1] == '\t') {
+		line = line[:i]
+	}
+	if i := strings.Index(line, ";"); i >= 0 {
+		line = line[:i]
+	}
+	if i := strings.Index(line, " --"); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+// isIgnorable reports whether the line is intentionally skipped,
+// e.g. a blank line, a comment, an option like "-r base.txt" or an unpinned requirement.
+func isIgnorable(line string) bool {
+	line = stripLine(line)
+	return line == "" || strings.HasPrefix(line, "-") || strings.Contains(line, "://") ||
+		unpinnedRegexp.MatchString(line)
+}
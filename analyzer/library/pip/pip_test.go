@@ -0,0 +1,56 @@
+package pip
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []types.Library
+	}{
+		"Requirements": {
+			path: "./testdata/requirements.txt",
+			libs: []types.Library{
+				{Name: "Django", Version: "2.2.1"},
+				{Name: "requests", Version: "2.22.0"},
+				{Name: "celery", Version: "4.3.0"},
+				{Name: "PyYAML", Version: "5.1"},
+				{Name: "urllib3", Version: "1.25.2"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		b, err := ioutil.ReadFile(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs := parse(v.path, b)
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
+
+func TestParseUnpinned(t *testing.T) {
+	path := "./testdata/requirements.txt"
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("can't open file %s", path)
+	}
+	expected := []analyzer.PinningWarning{
+		{FilePath: path, Dependency: "gunicorn", Constraint: ">=19.9.0"},
+		{FilePath: path, Dependency: "six", Constraint: "~=1.12.0"},
+		{FilePath: path, Dependency: "boto3", Constraint: "==1.9.*"},
+		{FilePath: path, Dependency: "flask", Constraint: ""},
+	}
+	warnings := parseUnpinned(path, b)
+	if !reflect.DeepEqual(expected, warnings) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, warnings)
+	}
+}
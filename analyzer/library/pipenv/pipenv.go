@@ -2,12 +2,15 @@ package pipenv
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/knqyf263/fanal/analyzer"
 	"github.com/knqyf263/fanal/extractor"
 	"github.com/knqyf263/fanal/utils"
-	"github.com/knqyf263/go-dep-parser/pkg/pipenv"
 	"github.com/knqyf263/go-dep-parser/pkg/types"
 	"golang.org/x/xerrors"
 )
@@ -16,24 +19,47 @@ func init() {
 	analyzer.RegisterLibraryAnalyzer(&pipenvLibraryAnalyzer{})
 }
 
+type lockFile struct {
+	Default map[string]dependency `json:"default"`
+	Develop map[string]dependency `json:"develop"`
+}
+
+type dependency struct {
+	// e.g. "==2.31.0". Empty for VCS and local path dependencies.
+	Version string `json:"version"`
+}
+
 type pipenvLibraryAnalyzer struct{}
 
 func (a pipenvLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
-	libMap := map[analyzer.FilePath][]types.Library{}
+	findingMap, err := a.AnalyzeFindings(fileMap)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.FindingsToLibraries(findingMap), nil
+}
+
+// AnalyzeFindings marks libraries only in "develop" as dev dependencies.
+func (a pipenvLibraryAnalyzer) AnalyzeFindings(fileMap extractor.FileMap) (map[analyzer.FilePath][]analyzer.LibraryFinding, error) {
+	libMap := map[analyzer.FilePath][]analyzer.LibraryFinding{}
 	requiredFiles := a.RequiredFiles()
 
-	for filename, content := range fileMap {
+	err := fileMap.ForEach(func(filename string, content []byte) error {
 		basename := filepath.Base(filename)
 		if !utils.StringInSlice(basename, requiredFiles) {
-			continue
+			return nil
 		}
 
 		r := bytes.NewBuffer(content)
-		libs, err := pipenv.Parse(r)
+		libs, err := parse(r)
 		if err != nil {
-			return nil, xerrors.Errorf("invalid Pipfile.lock format: %w", err)
+			return xerrors.Errorf("invalid Pipfile.lock format: %v: %w", err, analyzer.ErrMalformedFile)
 		}
 		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return libMap, nil
 }
@@ -41,3 +67,41 @@ func (a pipenvLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.
 func (a pipenvLibraryAnalyzer) RequiredFiles() []string {
 	return []string{"Pipfile.lock"}
 }
+
+// parse returns libraries in both "default" and "develop".
+// Dependencies without a pinned version such as VCS and editable ones are skipped.
+func parse(r io.Reader) ([]analyzer.LibraryFinding, error) {
+	var lock lockFile
+	if err := json.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	var libs []analyzer.LibraryFinding
+	unique := map[types.Library]struct{}{}
+	for i, deps := range []map[string]dependency{lock.Default, lock.Develop} {
+		var names []string
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			version := strings.TrimPrefix(deps[name].Version, "==")
+			if version == "" {
+				continue
+			}
+			lib := types.Library{Name: name, Version: version}
+			if _, ok := unique[lib]; ok {
+				continue
+			}
+			unique[lib] = struct{}{}
+			libs = append(libs, analyzer.LibraryFinding{
+				Library:    lib,
+				Confidence: 1,
+				Source:     "Pipfile.lock",
+				Dev:        i == 1,
+			})
+		}
+	}
+	return libs, nil
+}
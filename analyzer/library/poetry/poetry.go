@@ -0,0 +1,88 @@
+package poetry
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/fanal/utils"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&poetryLibraryAnalyzer{})
+}
+
+type lockfile struct {
+	Packages []struct {
+		Name     string `toml:"name"`
+		Version  string `toml:"version"`
+		Category string `toml:"category"` // removed in lock-version 2.0
+		Optional bool   `toml:"optional"`
+	} `toml:"package"`
+}
+
+type poetryLibraryAnalyzer struct{}
+
+func (a poetryLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	findingMap, err := a.AnalyzeFindings(fileMap)
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.FindingsToLibraries(findingMap), nil
+}
+
+// AnalyzeFindings marks libraries in the "dev" category as dev dependencies.
+// lock-version 2.0 doesn't record groups, so all libraries in it are regarded as non-dev.
+func (a poetryLibraryAnalyzer) AnalyzeFindings(fileMap extractor.FileMap) (map[analyzer.FilePath][]analyzer.LibraryFinding, error) {
+	libMap := map[analyzer.FilePath][]analyzer.LibraryFinding{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !utils.StringInSlice(basename, requiredFiles) {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid poetry.lock format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a poetryLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"poetry.lock"}
+}
+
+func parse(r io.Reader) ([]analyzer.LibraryFinding, error) {
+	var lock lockfile
+	if _, err := toml.DecodeReader(r, &lock); err != nil {
+		return nil, xerrors.Errorf("failed to decode poetry.lock: %w", err)
+	}
+
+	var libs []analyzer.LibraryFinding
+	for _, pkg := range lock.Packages {
+		libs = append(libs, analyzer.LibraryFinding{
+			Library: types.Library{
+				Name:    pkg.Name,
+				Version: pkg.Version,
+			},
+			Confidence: 1,
+			Source:     "poetry.lock",
+			Dev:        pkg.Category == "dev",
+		})
+	}
+	return libs, nil
+}
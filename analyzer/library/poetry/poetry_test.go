@@ -0,0 +1,48 @@
+package poetry
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []analyzer.LibraryFinding
+	}{
+		"LockVersion1": {
+			path: "./testdata/poetry_v1.lock",
+			libs: []analyzer.LibraryFinding{
+				{Library: types.Library{Name: "certifi", Version: "2023.7.22"}, Confidence: 1, Source: "poetry.lock"},
+				{Library: types.Library{Name: "pytest", Version: "7.4.2"}, Confidence: 1, Source: "poetry.lock", Dev: true},
+				{Library: types.Library{Name: "requests", Version: "2.31.0"}, Confidence: 1, Source: "poetry.lock"},
+			},
+		},
+		"LockVersion2": {
+			path: "./testdata/poetry_v2.lock",
+			libs: []analyzer.LibraryFinding{
+				{Library: types.Library{Name: "colorama", Version: "0.4.6"}, Confidence: 1, Source: "poetry.lock"},
+				{Library: types.Library{Name: "pytest", Version: "8.1.1"}, Confidence: 1, Source: "poetry.lock"},
+				{Library: types.Library{Name: "requests", Version: "2.31.0"}, Confidence: 1, Source: "poetry.lock"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
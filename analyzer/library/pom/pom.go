@@ -0,0 +1,177 @@
+package pom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/fanal/utils"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+// e.g. ${spring.version}
+var propertyRegexp = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&pomLibraryAnalyzer{})
+}
+
+type project struct {
+	GroupID    string       `xml:"groupId"`
+	ArtifactID string       `xml:"artifactId"`
+	Version    string       `xml:"version"`
+	Parent     parent       `xml:"parent"`
+	Properties properties   `xml:"properties"`
+	Deps       []dependency `xml:"dependencies>dependency"`
+	ManagedDep []dependency `xml:"dependencyManagement>dependencies>dependency"`
+}
+
+type parent struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type dependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+type properties map[string]string
+
+func (props *properties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*props = properties{}
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch e := t.(type) {
+		case xml.StartElement:
+			var value string
+			if err = d.DecodeElement(&value, &e); err != nil {
+				return err
+			}
+			(*props)[e.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type pomLibraryAnalyzer struct{}
+
+func (a pomLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !utils.StringInSlice(basename, requiredFiles) {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid pom.xml format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a pomLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"pom.xml"}
+}
+
+// parse returns the dependencies declared in pom.xml.
+// Only the properties and the parent in the same file are used,
+// and dependencies whose version can't be resolved are skipped.
+func parse(r io.Reader) ([]types.Library, error) {
+	var p project
+	if err := xml.NewDecoder(r).Decode(&p); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	props := p.builtinProperties()
+	for k, v := range p.Properties {
+		props[k] = v
+	}
+
+	managed := map[string]string{}
+	for _, dep := range p.ManagedDep {
+		managed[props.name(dep)] = props.resolve(dep.Version)
+	}
+
+	var libs []types.Library
+	for _, dep := range p.Deps {
+		name := props.name(dep)
+		version := props.resolve(dep.Version)
+		if version == "" {
+			version = managed[name]
+		}
+		if name == "" || version == "" || strings.Contains(name+version, "${") {
+			continue
+		}
+		libs = append(libs, types.Library{Name: name, Version: version})
+	}
+	return libs, nil
+}
+
+func (p project) builtinProperties() properties {
+	groupID, version := p.GroupID, p.Version
+	// Inherit from the parent
+	if groupID == "" {
+		groupID = p.Parent.GroupID
+	}
+	if version == "" {
+		version = p.Parent.Version
+	}
+	return properties{
+		"project.groupId":        groupID,
+		"project.artifactId":     p.ArtifactID,
+		"project.version":        version,
+		"pom.groupId":            groupID,
+		"pom.version":            version,
+		"version":                version,
+		"project.parent.groupId": p.Parent.GroupID,
+		"project.parent.version": p.Parent.Version,
+		"parent.version":         p.Parent.Version,
+	}
+}
+
+func (props properties) name(dep dependency) string {
+	groupID := props.resolve(dep.GroupID)
+	artifactID := props.resolve(dep.ArtifactID)
+	if groupID == "" || artifactID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s", groupID, artifactID)
+}
+
+// resolve replaces ${property}. Properties can refer to other properties.
+func (props properties) resolve(s string) string {
+	s = strings.TrimSpace(s)
+	for i := 0; i < 10 && strings.Contains(s, "${"); i++ {
+		s = propertyRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			if v, ok := props[m[2:len(m)-1]]; ok && v != "" {
+				return v
+			}
+			return m
+		})
+	}
+	return s
+}
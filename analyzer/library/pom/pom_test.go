@@ -0,0 +1,40 @@
+package pom
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []types.Library
+	}{
+		"Properties": {
+			path: "./testdata/pom.xml",
+			libs: []types.Library{
+				{Name: "com.fasterxml.jackson.core:jackson-databind", Version: "2.9.8"},
+				{Name: "com.example:example-core", Version: "1.2.0"},
+				{Name: "org.apache.commons:commons-lang3", Version: "3.9"},
+				{Name: "junit:junit", Version: "4.12"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package pub
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/fanal/utils"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&pubLibraryAnalyzer{})
+}
+
+type lockFile struct {
+	Packages map[string]struct {
+		Dependency string // "direct main", "direct dev", "direct overridden" or "transitive"
+		// A string for sdk sources and a map for the other sources
+		Description interface{}
+		Source      string
+		Version     string
+	}
+}
+
+type pubLibraryAnalyzer struct{}
+
+func (a pubLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !utils.StringInSlice(basename, requiredFiles) {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid pubspec.lock format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a pubLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"pubspec.lock"}
+}
+
+// parse returns hosted packages and git packages with the resolved ref as the version.
+// sdk and path packages are skipped.
+func parse(r io.Reader) ([]types.Library, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("read error: %w", err)
+	}
+	var lock lockFile
+	if err := yaml.Unmarshal(b, &lock); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	var names []string
+	for name := range lock.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var libs []types.Library
+	for _, name := range names {
+		pkg := lock.Packages[name]
+		var version string
+		switch pkg.Source {
+		case "hosted":
+			version = pkg.Version
+		case "git":
+			desc, ok := pkg.Description.(map[interface{}]interface{})
+			if !ok {
+				continue
+			}
+			version, _ = desc["resolved-ref"].(string)
+		default:
+			continue
+		}
+		if version == "" {
+			continue
+		}
+		libs = append(libs, types.Library{Name: name, Version: version})
+	}
+	return libs, nil
+}
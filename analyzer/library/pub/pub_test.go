@@ -0,0 +1,40 @@
+package pub
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []types.Library
+	}{
+		"Valid": {
+			path: "./testdata/pubspec.lock",
+			libs: []types.Library{
+				{Name: "async", Version: "2.8.2"},
+				{Name: "http", Version: "0.13.4"},
+				{Name: "mockito", Version: "5.1.0"},
+				{Name: "shelf_router", Version: "7a1b6a1b3b0a4e4c9d0c7f0fb5e4c7b8f2a7e1d3"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
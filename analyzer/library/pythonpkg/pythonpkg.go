@@ -0,0 +1,85 @@
+package pythonpkg
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+var normalizeRegexp = regexp.MustCompile(`[-_.]+`)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&pythonPkgLibraryAnalyzer{})
+}
+
+type pythonPkgLibraryAnalyzer struct{}
+
+// Analyze detects packages installed by pip from *.dist-info/METADATA and *.egg-info/PKG-INFO,
+// and by distutils from the *.egg-info files, e.g. usr/lib/python3/dist-packages/six-1.12.0.egg-info of Debian.
+// Results are grouped by the site-packages or dist-packages directory so that each virtualenv is reported separately.
+func (a pythonPkgLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		// e.g. usr/local/lib/python3.7/site-packages/pip-19.1.dist-info/METADATA
+		if !extractor.MatchAny(requiredFiles, filename) {
+			return nil
+		}
+		metadataDir := filepath.Dir(filename)
+		if strings.HasSuffix(filename, ".egg-info") {
+			// The metadata file itself is in site-packages
+			metadataDir = filename
+		}
+
+		lib := parseMetadata(content)
+		if lib.Name == "" || lib.Version == "" {
+			return nil
+		}
+		sitePackages := analyzer.FilePath(filepath.Dir(metadataDir))
+		libMap[sitePackages] = append(libMap[sitePackages], lib)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a pythonPkgLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"**/*.dist-info/METADATA", "**/*.egg-info/PKG-INFO", "**/*.egg-info"}
+}
+
+// parseMetadata parses the headers of the core metadata.
+// https://packaging.python.org/specifications/core-metadata/
+func parseMetadata(content []byte) types.Library {
+	var lib types.Library
+	scanner := bufio.NewScanner(bytes.NewBuffer(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// The description body follows the headers
+		if line == "" {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(line, "Name:"):
+			lib.Name = normalizeName(strings.TrimSpace(strings.TrimPrefix(line, "Name:")))
+		case strings.HasPrefix(line, "Version:"):
+			lib.Version = strings.TrimSpace(strings.TrimPrefix(line, "Version:"))
+		}
+	}
+	return lib
+}
+
+// normalizeName normalizes the package name in the same way as pip.
+// https://www.python.org/dev/peps/pep-0503/#normalized-names
+func normalizeName(name string) string {
+	return strings.ToLower(normalizeRegexp.ReplaceAllString(name, "-"))
+}
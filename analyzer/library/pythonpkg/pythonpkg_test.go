@@ -0,0 +1,86 @@
+package pythonpkg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestAnalyze(t *testing.T) {
+	var tests = map[string]struct {
+		root   string
+		libMap map[analyzer.FilePath][]types.Library
+	}{
+		"Python3.12": {
+			root: "./testdata/python3.12",
+			libMap: map[analyzer.FilePath][]types.Library{
+				"usr/local/lib/python3.12/site-packages": {
+					{Name: "pip", Version: "24.0"},
+					{Name: "pyyaml", Version: "6.0.1"},
+					{Name: "setuptools", Version: "69.0.3"},
+					{Name: "typing-extensions", Version: "4.9.0"},
+					{Name: "wheel", Version: "0.42.0"},
+				},
+				"opt/venv/lib/python3.12/site-packages": {
+					{Name: "requests", Version: "2.31.0"},
+					{Name: "zope-interface", Version: "6.1"},
+				},
+			},
+		},
+		// Debian and Ubuntu install the system packages in dist-packages
+		"DistPackages": {
+			root: "./testdata/dist-packages",
+			libMap: map[analyzer.FilePath][]types.Library{
+				"usr/lib/python3/dist-packages": {
+					{Name: "requests", Version: "2.21.0"},
+					{Name: "six", Version: "1.12.0"},
+				},
+				"usr/local/lib/python3.7/dist-packages": {
+					{Name: "flask", Version: "1.0.2"},
+				},
+			},
+		},
+	}
+	a := pythonPkgLibraryAnalyzer{}
+	for testName, v := range tests {
+		fileMap := extractor.MapFileMap{}
+		err := filepath.Walk(v.root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(v.root, path)
+			if err != nil {
+				return err
+			}
+			fileMap[filepath.ToSlash(rel)] = b
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s : can't read %s: %v", testName, v.root, err)
+		}
+
+		libMap, err := a.Analyze(fileMap)
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		for _, libs := range libMap {
+			sort.Slice(libs, func(i, j int) bool {
+				return libs[i].Name < libs[j].Name
+			})
+		}
+		if !reflect.DeepEqual(v.libMap, libMap) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libMap, libMap)
+		}
+	}
+}
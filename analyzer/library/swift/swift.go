@@ -0,0 +1,106 @@
+package swift
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"github.com/knqyf263/fanal/utils"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+func init() {
+	analyzer.RegisterLibraryAnalyzer(&swiftLibraryAnalyzer{})
+}
+
+type resolvedFile struct {
+	Version int
+	// version 1
+	Object struct {
+		Pins []pin
+	}
+	// version 2 and 3
+	Pins []pin
+}
+
+type pin struct {
+	Identity      string // version 2 and 3
+	Location      string // version 2 and 3
+	RepositoryURL string // version 1
+	State         struct {
+		Branch   string
+		Revision string
+		Version  string
+	}
+}
+
+type swiftLibraryAnalyzer struct{}
+
+func (a swiftLibraryAnalyzer) Analyze(fileMap extractor.FileMap) (map[analyzer.FilePath][]types.Library, error) {
+	libMap := map[analyzer.FilePath][]types.Library{}
+	requiredFiles := a.RequiredFiles()
+
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		basename := filepath.Base(filename)
+		if !utils.StringInSlice(basename, requiredFiles) {
+			return nil
+		}
+
+		r := bytes.NewBuffer(content)
+		libs, err := parse(r)
+		if err != nil {
+			return xerrors.Errorf("invalid Package.resolved format: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		libMap[analyzer.FilePath(filename)] = libs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return libMap, nil
+}
+
+func (a swiftLibraryAnalyzer) RequiredFiles() []string {
+	return []string{"Package.resolved"}
+}
+
+// parse returns the pinned packages. Branch pins have the revision as the version.
+func parse(r io.Reader) ([]types.Library, error) {
+	var resolved resolvedFile
+	if err := json.NewDecoder(r).Decode(&resolved); err != nil {
+		return nil, xerrors.Errorf("decode error: %w", err)
+	}
+
+	pins := resolved.Pins
+	if resolved.Version == 1 {
+		pins = resolved.Object.Pins
+	}
+
+	var libs []types.Library
+	for _, p := range pins {
+		name := p.Identity
+		if name == "" {
+			// e.g. https://github.com/Alamofire/Alamofire.git => Alamofire
+			url := p.Location
+			if url == "" {
+				url = p.RepositoryURL
+			}
+			name = strings.TrimSuffix(path.Base(strings.TrimSuffix(url, "/")), ".git")
+		}
+		version := p.State.Version
+		if version == "" {
+			version = p.State.Revision
+		}
+		if name == "" || name == "." || version == "" {
+			continue
+		}
+		libs = append(libs, types.Library{Name: name, Version: version})
+	}
+	return libs, nil
+}
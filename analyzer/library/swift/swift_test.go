@@ -0,0 +1,45 @@
+package swift
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestParse(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+		libs []types.Library
+	}{
+		"Version1": {
+			path: "./testdata/Package.resolved.v1",
+			libs: []types.Library{
+				{Name: "Alamofire", Version: "5.6.4"},
+				{Name: "swift-log", Version: "6fe203dc33195667ce1759bf0182975e4653ba1c"},
+			},
+		},
+		"Version2": {
+			path: "./testdata/Package.resolved.v2",
+			libs: []types.Library{
+				{Name: "alamofire", Version: "5.6.4"},
+				{Name: "swift-log", Version: "6fe203dc33195667ce1759bf0182975e4653ba1c"},
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.path)
+		if err != nil {
+			t.Fatalf("%s : can't open file %s", testName, v.path)
+		}
+		libs, err := parse(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.libs, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.libs, libs)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+var licenseOperatorRegexp = regexp.MustCompile(`(?i)\s+(and|or|with)\s+`)
+
+// spdxLicenses maps license names used by RPM spec files and Debian copyright files to SPDX identifiers.
+var spdxLicenses = map[string]string{
+	"GPLv2":        "GPL-2.0-only",
+	"GPLv2+":       "GPL-2.0-or-later",
+	"GPL-2":        "GPL-2.0-only",
+	"GPL-2+":       "GPL-2.0-or-later",
+	"GPLv3":        "GPL-3.0-only",
+	"GPLv3+":       "GPL-3.0-or-later",
+	"GPL-3":        "GPL-3.0-only",
+	"GPL-3+":       "GPL-3.0-or-later",
+	"LGPLv2":       "LGPL-2.0-only",
+	"LGPLv2+":      "LGPL-2.0-or-later",
+	"LGPL-2":       "LGPL-2.0-only",
+	"LGPL-2+":      "LGPL-2.0-or-later",
+	"LGPLv2.1":     "LGPL-2.1-only",
+	"LGPLv2.1+":    "LGPL-2.1-or-later",
+	"LGPL-2.1":     "LGPL-2.1-only",
+	"LGPL-2.1+":    "LGPL-2.1-or-later",
+	"LGPLv3":       "LGPL-3.0-only",
+	"LGPLv3+":      "LGPL-3.0-or-later",
+	"LGPL-3":       "LGPL-3.0-only",
+	"LGPL-3+":      "LGPL-3.0-or-later",
+	"ASL 2.0":      "Apache-2.0",
+	"Apache 2.0":   "Apache-2.0",
+	"Apache-2":     "Apache-2.0",
+	"Apache-2.0":   "Apache-2.0",
+	"MPLv2.0":      "MPL-2.0",
+	"MPL-2.0":      "MPL-2.0",
+	"MIT":          "MIT",
+	"Expat":        "MIT",
+	"ISC":          "ISC",
+	"Zlib":         "Zlib",
+	"zlib":         "Zlib",
+	"BSD-2-clause": "BSD-2-Clause",
+	"BSD-3-clause": "BSD-3-Clause",
+	"Artistic":     "Artistic-1.0-Perl",
+	"OpenSSL":      "OpenSSL",
+	"PSF":          "Python-2.0",
+}
+
+// NormalizeLicense converts the license to an SPDX expression as far as possible,
+// e.g. "GPLv2+ and LGPLv2+" to "GPL-2.0-or-later AND LGPL-2.0-or-later".
+// Unknown license names are kept as they are.
+func NormalizeLicense(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	operators := licenseOperatorRegexp.FindAllStringSubmatch(raw, -1)
+	terms := licenseOperatorRegexp.Split(raw, -1)
+
+	var b strings.Builder
+	for i, term := range terms {
+		if i > 0 {
+			b.WriteString(" " + strings.ToUpper(operators[i-1][1]) + " ")
+		}
+		// Keep the parentheses, e.g. "(GPLv2+ or MIT)"
+		lparen := strings.Repeat("(", len(term)-len(strings.TrimLeft(term, "(")))
+		rparen := strings.Repeat(")", len(term)-len(strings.TrimRight(term, ")")))
+		name := strings.TrimSpace(strings.TrimRight(strings.TrimLeft(term, "("), ")"))
+		if id, ok := spdxLicenses[name]; ok {
+			name = id
+		}
+		b.WriteString(lparen + name + rparen)
+	}
+	return b.String()
+}
@@ -0,0 +1,23 @@
+package analyzer
+
+import "testing"
+
+func TestNormalizeLicense(t *testing.T) {
+	var tests = map[string]struct {
+		raw      string
+		expected string
+	}{
+		"RPM":         {raw: "GPLv2+", expected: "GPL-2.0-or-later"},
+		"Debian":      {raw: "LGPL-2.1+", expected: "LGPL-2.1-or-later"},
+		"SPDX":        {raw: "Apache-2.0", expected: "Apache-2.0"},
+		"And":         {raw: "GPLv2+ and LGPLv2+", expected: "GPL-2.0-or-later AND LGPL-2.0-or-later"},
+		"Parentheses": {raw: "(GPLv2+ or MIT) and ASL 2.0", expected: "(GPL-2.0-or-later OR MIT) AND Apache-2.0"},
+		"Unknown":     {raw: "Public Domain", expected: "Public Domain"},
+		"Empty":       {raw: " ", expected: ""},
+	}
+	for testName, v := range tests {
+		if actual := NormalizeLicense(v.raw); actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
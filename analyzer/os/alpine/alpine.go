@@ -3,7 +3,8 @@ package alpine
 import (
 	"bufio"
 	"bytes"
-	"errors"
+
+	"golang.org/x/xerrors"
 
 	"github.com/knqyf263/fanal/analyzer/os"
 
@@ -19,7 +20,7 @@ type alpineOSAnalyzer struct{}
 
 func (a alpineOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
 	for _, filename := range a.RequiredFiles() {
-		file, ok := fileMap[filename]
+		file, ok := fileMap.Get(filename)
 		if !ok {
 			continue
 		}
@@ -29,7 +30,7 @@ func (a alpineOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error
 			return analyzer.OS{Family: os.Alpine, Name: line}, nil
 		}
 	}
-	return analyzer.OS{}, errors.New("alpine: Not match")
+	return analyzer.OS{}, xerrors.Errorf("alpine: %w", analyzer.ErrNoAnalyzerMatch)
 }
 
 func (a alpineOSAnalyzer) RequiredFiles() []string {
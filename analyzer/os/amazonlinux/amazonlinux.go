@@ -3,10 +3,11 @@ package amazonlinux
 import (
 	"bufio"
 	"bytes"
-	"errors"
-	"fmt"
+	"regexp"
 	"strings"
 
+	"golang.org/x/xerrors"
+
 	"github.com/knqyf263/fanal/analyzer/os"
 
 	"github.com/knqyf263/fanal/analyzer"
@@ -19,21 +20,20 @@ func init() {
 
 type amazonlinuxOSAnalyzer struct{}
 
+// e.g. "Amazon Linux release 2 (Karoo)", "Amazon Linux release 2023 (Amazon Linux)"
+var releaseRe = regexp.MustCompile(`^Amazon Linux release (\d+)`)
+
 func (a amazonlinuxOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
-	for _, filename := range a.RequiredFiles() {
-		file, ok := fileMap[filename]
-		if !ok {
-			continue
-		}
+	if file, ok := fileMap.Get("etc/system-release"); ok {
 		scanner := bufio.NewScanner(bytes.NewBuffer(file))
 		for scanner.Scan() {
 			line := scanner.Text()
 			fields := strings.Fields(line)
 			// Only Amazon Linux Prefix
-			if strings.HasPrefix(line, "Amazon Linux release 2") {
+			if result := releaseRe.FindStringSubmatch(line); len(result) == 2 {
 				return analyzer.OS{
 					Family: os.Amazon,
-					Name:   fmt.Sprintf("%s %s", fields[3], fields[4]),
+					Name:   result[1],
 				}, nil
 			} else if strings.HasPrefix(line, "Amazon Linux") {
 				return analyzer.OS{
@@ -43,9 +43,31 @@ func (a amazonlinuxOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS,
 			}
 		}
 	}
-	return analyzer.OS{}, errors.New("amzn: Not match")
+
+	// e.g. ID="amzn" and VERSION_ID="2023"
+	if file, ok := fileMap.Get("etc/os-release"); ok {
+		var isAmazon bool
+		var versionID string
+		scanner := bufio.NewScanner(bytes.NewBuffer(file))
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "ID="):
+				isAmazon = strings.Trim(strings.TrimPrefix(line, "ID="), `"`) == "amzn"
+			case strings.HasPrefix(line, "VERSION_ID="):
+				versionID = strings.Trim(strings.TrimPrefix(line, "VERSION_ID="), `"`)
+			}
+		}
+		if isAmazon && versionID != "" {
+			return analyzer.OS{Family: os.Amazon, Name: versionID}, nil
+		}
+	}
+	return analyzer.OS{}, xerrors.Errorf("amzn: %w", analyzer.ErrNoAnalyzerMatch)
 }
 
 func (a amazonlinuxOSAnalyzer) RequiredFiles() []string {
-	return []string{"etc/system-release"}
+	return []string{
+		"etc/system-release",
+		"etc/os-release",
+	}
 }
@@ -0,0 +1,67 @@
+package amazonlinux
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestAnalyze(t *testing.T) {
+	var tests = map[string]struct {
+		files    map[string]string
+		expected analyzer.OS
+		err      error
+	}{
+		"AmazonLinux1": {
+			files:    map[string]string{"etc/system-release": "./testdata/system-release-al1"},
+			expected: analyzer.OS{Family: "amazon", Name: "AMI release 2018.03"},
+		},
+		"AmazonLinux2": {
+			files:    map[string]string{"etc/system-release": "./testdata/system-release-al2"},
+			expected: analyzer.OS{Family: "amazon", Name: "2"},
+		},
+		"AmazonLinux2023": {
+			files: map[string]string{
+				"etc/system-release": "./testdata/system-release-al2023",
+				"etc/os-release":     "./testdata/os-release-al2023",
+			},
+			expected: analyzer.OS{Family: "amazon", Name: "2023"},
+		},
+		"AmazonLinux2023OSRelease": {
+			files:    map[string]string{"etc/os-release": "./testdata/os-release-al2023"},
+			expected: analyzer.OS{Family: "amazon", Name: "2023"},
+		},
+		"NoFile": {
+			files: map[string]string{},
+			err:   analyzer.ErrNoAnalyzerMatch,
+		},
+	}
+	a := amazonlinuxOSAnalyzer{}
+	for testName, v := range tests {
+		fileMap := extractor.MapFileMap{}
+		for path, testdata := range v.files {
+			b, err := ioutil.ReadFile(testdata)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fileMap[path] = b
+		}
+		actual, err := a.Analyze(fileMap)
+		if v.err != nil {
+			if !xerrors.Is(err, v.err) {
+				t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] catch the error : %v", testName, err)
+		}
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
@@ -22,13 +22,21 @@ const (
 	// Oracle is done
 	Oracle = "oracle"
 
+	// Rocky is Rocky Linux
+	Rocky = "rocky"
+
+	// Alma is AlmaLinux
+	Alma = "alma"
+
 	// FreeBSD currently doesn't support docker
 	// FreeBSD = "freebsd"
 
-	// Windows only run windows os
-	// TODO : support windows
+	// Windows is done
 	Windows = "windows"
 
+	// SUSE is SUSE Linux Enterprise Server
+	SUSE = "suse"
+
 	// OpenSUSE is done
 	OpenSUSE = "opensuse"
 
@@ -38,6 +46,15 @@ const (
 	// OpenSUSETumbleweed is done
 	OpenSUSETumbleweed = "opensuse.tumbleweed"
 
+	// CoreOS is Fedora CoreOS, and CoreOS Container Linux before it
+	CoreOS = "coreos"
+
+	// Flatcar is Flatcar Container Linux
+	Flatcar = "flatcar"
+
 	// Alpine is done
 	Alpine = "alpine"
+
+	// Scratch is the images built FROM scratch, which have no OS files
+	Scratch = "scratch"
 )
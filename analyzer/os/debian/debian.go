@@ -3,10 +3,11 @@ package debian
 import (
 	"bufio"
 	"bytes"
-	"errors"
 	"regexp"
 	"strings"
 
+	"golang.org/x/xerrors"
+
 	"github.com/knqyf263/fanal/analyzer/os"
 
 	"github.com/knqyf263/fanal/analyzer"
@@ -21,7 +22,7 @@ type debianOSAnalyzer struct{}
 
 func (a debianOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
 	for _, filename := range a.RequiredFiles() {
-		file, ok := fileMap[filename]
+		file, ok := fileMap.Get(filename)
 		if !ok {
 			continue
 		}
@@ -36,7 +37,7 @@ func (a debianOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error
 			}
 		}
 	}
-	return analyzer.OS{}, errors.New("debian: Not match")
+	return analyzer.OS{}, xerrors.Errorf("debian: %w", analyzer.ErrNoAnalyzerMatch)
 }
 
 func (a debianOSAnalyzer) RequiredFiles() []string {
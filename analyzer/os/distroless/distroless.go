@@ -0,0 +1,74 @@
+package distroless
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer/os"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+const (
+	distroless = "distroless"
+	// statusDir has a status file per package in distroless images, e.g. var/lib/dpkg/status.d/base-files
+	statusDir = "var/lib/dpkg/status.d"
+)
+
+func init() {
+	analyzer.RegisterOSAnalyzer(&distrolessOSAnalyzer{})
+}
+
+type distrolessOSAnalyzer struct{}
+
+// Distroless images don't have etc/os-release, but have the dpkg database in var/lib/dpkg/status.d.
+// etc/debian_version with the version number is detected by the debian analyzer before this analyzer,
+// so the name is the codename here, e.g. "bookworm/sid" of the images based on Debian testing.
+func (a distrolessOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	if _, ok := fileMap.Get("etc/os-release"); ok {
+		return analyzer.OS{}, xerrors.Errorf("distroless: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+	if !hasDpkgDatabase(fileMap) {
+		return analyzer.OS{}, xerrors.Errorf("distroless: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+
+	if file, ok := fileMap.Get("etc/debian_version"); ok {
+		scanner := bufio.NewScanner(bytes.NewBuffer(file))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" {
+				return analyzer.OS{Family: os.Debian, Name: line}, nil
+			}
+		}
+	}
+	return analyzer.OS{Family: os.Debian, Name: distroless}, nil
+}
+
+// hasDpkgDatabase reports whether the image has var/lib/dpkg/status or any file in var/lib/dpkg/status.d.
+func hasDpkgDatabase(fileMap extractor.FileMap) bool {
+	if _, ok := fileMap.Get("var/lib/dpkg/status"); ok {
+		return true
+	}
+	for _, path := range fileMap.Paths() {
+		if strings.HasPrefix(path, statusDir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (a distrolessOSAnalyzer) RequiredFiles() []string {
+	return []string{
+		"etc/os-release",
+		"etc/debian_version",
+		"var/lib/dpkg/status",
+	}
+}
+
+func (a distrolessOSAnalyzer) RequiredDirs() []string {
+	return []string{statusDir}
+}
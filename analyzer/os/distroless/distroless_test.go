@@ -0,0 +1,102 @@
+package distroless
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer"
+	_ "github.com/knqyf263/fanal/analyzer/os/debian"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestAnalyze(t *testing.T) {
+	status, err := ioutil.ReadFile("./testdata/status.d/base-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	debianVersion, err := ioutil.ReadFile("./testdata/debian_version")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		fileMap  extractor.FileMap
+		expected analyzer.OS
+		err      error
+	}{
+		"StatusDir": {
+			fileMap:  extractor.MapFileMap{"var/lib/dpkg/status.d/base-files": status},
+			expected: analyzer.OS{Family: "debian", Name: "distroless"},
+		},
+		"StatusFile": {
+			fileMap:  extractor.MapFileMap{"var/lib/dpkg/status": status},
+			expected: analyzer.OS{Family: "debian", Name: "distroless"},
+		},
+		"DebianVersion": {
+			fileMap: extractor.MapFileMap{
+				"var/lib/dpkg/status.d/base-files": status,
+				"etc/debian_version":               debianVersion,
+			},
+			expected: analyzer.OS{Family: "debian", Name: "bookworm/sid"},
+		},
+		"OSRelease": {
+			fileMap: extractor.MapFileMap{
+				"var/lib/dpkg/status.d/base-files": status,
+				"etc/os-release":                   []byte("ID=debian\n"),
+			},
+			err: analyzer.ErrNoAnalyzerMatch,
+		},
+		"NoDpkg": {
+			fileMap: extractor.MapFileMap{"etc/debian_version": debianVersion},
+			err:     analyzer.ErrNoAnalyzerMatch,
+		},
+	}
+	a := distrolessOSAnalyzer{}
+	for testName, v := range tests {
+		actual, err := a.Analyze(v.fileMap)
+		if v.err != nil {
+			if !xerrors.Is(err, v.err) {
+				t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] catch the error : %v", testName, err)
+		}
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+// The debian analyzer is registered before this analyzer as in cmd/fanal
+func TestGetOS(t *testing.T) {
+	status, err := ioutil.ReadFile("./testdata/status.d/base-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		debianVersion string
+		expected      analyzer.OS
+	}{
+		"Codename": {debianVersion: "bookworm/sid\n", expected: analyzer.OS{Family: "debian", Name: "bookworm/sid"}},
+		"Version":  {debianVersion: "11.7\n", expected: analyzer.OS{Family: "debian", Name: "11.7"}},
+		"None":     {expected: analyzer.OS{Family: "debian", Name: "distroless"}},
+	}
+	for testName, v := range tests {
+		fileMap := extractor.MapFileMap{"var/lib/dpkg/status.d/base-files": status}
+		if v.debianVersion != "" {
+			fileMap["etc/debian_version"] = []byte(v.debianVersion)
+		}
+		actual, err := analyzer.GetOS(fileMap)
+		if err != nil {
+			t.Errorf("[%s] catch the error : %v", testName, err)
+		}
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
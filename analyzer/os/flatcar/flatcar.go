@@ -0,0 +1,43 @@
+package flatcar
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer/os"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func init() {
+	analyzer.RegisterOSAnalyzer(&flatcarOSAnalyzer{})
+}
+
+// flatcarOSAnalyzer detects Flatcar Container Linux by ID=flatcar of os-release.
+// The version is its own one such as 2905.2.3 instead of the one of the upstream.
+type flatcarOSAnalyzer struct{}
+
+func (a flatcarOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	for _, filename := range a.RequiredFiles() {
+		file, ok := fileMap.Get(filename)
+		if !ok {
+			continue
+		}
+		fields := os.ParseOSRelease(file)
+		if fields["ID"] != "flatcar" {
+			continue
+		}
+		if fields["VERSION_ID"] == "" {
+			return analyzer.OS{}, xerrors.Errorf("flatcar: no VERSION_ID in os-release: %w", analyzer.ErrMalformedFile)
+		}
+		return analyzer.OS{Family: os.Flatcar, Name: fields["VERSION_ID"]}, nil
+	}
+	return analyzer.OS{}, xerrors.Errorf("flatcar: %w", analyzer.ErrNoAnalyzerMatch)
+}
+
+func (a flatcarOSAnalyzer) RequiredFiles() []string {
+	return []string{
+		"usr/lib/os-release",
+		"etc/os-release",
+	}
+}
@@ -0,0 +1,53 @@
+package flatcar
+
+import (
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestAnalyze(t *testing.T) {
+	var tests = map[string]struct {
+		fileMap  extractor.FileMap
+		expected analyzer.OS
+		err      error
+	}{
+		"Flatcar": {
+			fileMap: extractor.MapFileMap{"usr/lib/os-release": []byte(`NAME="Flatcar Container Linux by Kinvolk"
+ID=flatcar
+ID_LIKE=coreos
+VERSION=2905.2.3
+VERSION_ID=2905.2.3
+BUILD_ID=2021-09-01-1807
+`)},
+			expected: analyzer.OS{Family: "flatcar", Name: "2905.2.3"},
+		},
+		"NoVersion": {
+			fileMap: extractor.MapFileMap{"etc/os-release": []byte("ID=flatcar\n")},
+			err:     analyzer.ErrMalformedFile,
+		},
+		"Fedora": {
+			fileMap: extractor.MapFileMap{"etc/os-release": []byte("ID=fedora\nVERSION_ID=34\nVARIANT_ID=coreos\n")},
+			err:     analyzer.ErrNoAnalyzerMatch,
+		},
+	}
+	a := flatcarOSAnalyzer{}
+	for testName, v := range tests {
+		actual, err := a.Analyze(v.fileMap)
+		if v.err != nil {
+			if !xerrors.Is(err, v.err) {
+				t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] catch the error : %v", testName, err)
+		}
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package opensuse
 import (
 	"bufio"
 	"bytes"
-	"errors"
+	"regexp"
 	"strings"
 
+	"golang.org/x/xerrors"
+
 	"github.com/knqyf263/fanal/analyzer/os"
 
 	"github.com/knqyf263/fanal/analyzer"
@@ -13,47 +15,120 @@ import (
 )
 
 func init() {
-	analyzer.RegisterOSAnalyzer(&opensuseOSAnalyzer{})
+	analyzer.RegisterOSAnalyzer(&suseOSAnalyzer{})
 }
 
-type opensuseOSAnalyzer struct{}
+// suseOSAnalyzer detects SUSE Linux Enterprise Server and openSUSE, whose packages are read by the RPM analyzer.
+type suseOSAnalyzer struct{}
+
+var (
+	// e.g. VERSION = 11 and PATCHLEVEL = 4 of etc/SuSE-release
+	suseReleaseRe = regexp.MustCompile(`^(VERSION|PATCHLEVEL)\s*=\s*(\S+)`)
+	// Tumbleweed is versioned by the date of the snapshot, e.g. 20191231
+	tumbleweedVersionRe = regexp.MustCompile(`^\d{8}$`)
+)
 
-// TODO : need investigation
-func (a opensuseOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
-	for _, filename := range a.RequiredFiles() {
-		file, ok := fileMap[filename]
+func (a suseOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	for _, filename := range []string{"usr/lib/os-release", "etc/os-release"} {
+		file, ok := fileMap.Get(filename)
 		if !ok {
 			continue
 		}
-		suseName := ""
-		scanner := bufio.NewScanner(bytes.NewBuffer(file))
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.HasPrefix(line, "NAME=\"openSUSE") {
-				if strings.Contains(line, "Leap") {
-					suseName = os.OpenSUSELeap
-				} else if strings.Contains(line, "Tumbleweed") {
-					suseName = os.OpenSUSETumbleweed
-				} else {
-					suseName = os.OpenSUSE
-				}
-				continue
-			}
+		if family, version := parseOSRelease(file); family != "" && version != "" {
+			return analyzer.OS{Family: family, Name: version}, nil
+		}
+	}
+
+	// e.g. SLES 11 and openSUSE 13 without os-release
+	if file, ok := fileMap.Get("etc/SuSE-release"); ok {
+		return parseSuSERelease(file)
+	}
+	return analyzer.OS{}, xerrors.Errorf("suse: %w", analyzer.ErrNoAnalyzerMatch)
+}
+
+// parseOSRelease returns the family and VERSION_ID of SUSE. The family is empty for the other distributions.
+func parseOSRelease(file []byte) (string, string) {
+	fields := os.ParseOSRelease(file)
+	version := fields["VERSION_ID"]
+	return suseFamily(fields["ID"], strings.Fields(fields["ID_LIKE"]), fields["NAME"], version), version
+}
 
-			if suseName != "" && strings.HasPrefix(line, "VERSION_ID=") {
-				return analyzer.OS{
-					Family: suseName,
-					Name:   strings.TrimSpace(line[12 : len(line)-1]),
-				}, nil
+// suseFamily returns os.SUSE for SLES, and openSUSE Leap or Tumbleweed by ID, ID_LIKE, NAME and the version,
+// e.g. ID="opensuse" and NAME="openSUSE Leap" of Leap 42. os.OpenSUSE is returned for openSUSE before Leap.
+func suseFamily(id string, idLike []string, name, version string) string {
+	switch id {
+	case "sles", "sles_sap", "sled":
+		return os.SUSE
+	case "opensuse-leap":
+		return os.OpenSUSELeap
+	case "opensuse-tumbleweed":
+		return os.OpenSUSETumbleweed
+	}
+
+	if id != "opensuse" && !strings.HasPrefix(name, "openSUSE") && !contains(idLike, "opensuse") {
+		// e.g. SLE Micro with ID_LIKE="suse"
+		if contains(idLike, "suse") || contains(idLike, "sles") {
+			return os.SUSE
+		}
+		return ""
+	}
+	switch {
+	case contains(idLike, "opensuse-tumbleweed"), strings.Contains(name, "Tumbleweed"), tumbleweedVersionRe.MatchString(version):
+		return os.OpenSUSETumbleweed
+	case contains(idLike, "opensuse-leap"), strings.Contains(name, "Leap"):
+		return os.OpenSUSELeap
+	}
+	return os.OpenSUSE
+}
+
+// parseSuSERelease parses etc/SuSE-release, e.g. "SUSE Linux Enterprise Server 11 (x86_64)" followed by
+// "VERSION = 11" and "PATCHLEVEL = 4", which is 11.4.
+func parseSuSERelease(file []byte) (analyzer.OS, error) {
+	var family, version, patchLevel string
+	scanner := bufio.NewScanner(bytes.NewBuffer(file))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if family == "" {
+			switch {
+			case strings.HasPrefix(line, "SUSE Linux Enterprise"):
+				family = os.SUSE
+			case strings.HasPrefix(line, "openSUSE"):
+				family = suseFamily("opensuse", nil, line, "")
+			default:
+				return analyzer.OS{}, xerrors.Errorf("suse: invalid SuSE-release: %w", analyzer.ErrMalformedFile)
+			}
+			continue
+		}
+		if result := suseReleaseRe.FindStringSubmatch(line); result != nil {
+			if result[1] == "VERSION" {
+				version = result[2]
+			} else {
+				patchLevel = result[2]
 			}
 		}
 	}
-	return analyzer.OS{}, errors.New("opensuse: Not match")
+	if family == "" || version == "" {
+		return analyzer.OS{}, xerrors.Errorf("suse: invalid SuSE-release: %w", analyzer.ErrMalformedFile)
+	}
+	if patchLevel != "" && patchLevel != "0" {
+		version += "." + patchLevel
+	}
+	return analyzer.OS{Family: family, Name: version}, nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
 
-func (a opensuseOSAnalyzer) RequiredFiles() []string {
+func (a suseOSAnalyzer) RequiredFiles() []string {
 	return []string{
 		"usr/lib/os-release",
 		"etc/os-release",
+		"etc/SuSE-release",
 	}
 }
@@ -0,0 +1,111 @@
+package opensuse
+
+import (
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestAnalyze(t *testing.T) {
+	var tests = map[string]struct {
+		fileMap  extractor.FileMap
+		expected analyzer.OS
+		err      error
+	}{
+		"SLES": {
+			fileMap: extractor.MapFileMap{"etc/os-release": []byte(`NAME="SLES"
+VERSION="15-SP1"
+VERSION_ID="15.1"
+ID="sles"
+ID_LIKE="suse"
+`)},
+			expected: analyzer.OS{Family: "suse", Name: "15.1"},
+		},
+		"Leap": {
+			fileMap: extractor.MapFileMap{"usr/lib/os-release": []byte(`NAME="openSUSE Leap"
+VERSION="15.1"
+ID="opensuse-leap"
+ID_LIKE="suse opensuse"
+VERSION_ID="15.1"
+`)},
+			expected: analyzer.OS{Family: "opensuse.leap", Name: "15.1"},
+		},
+		"Leap42": {
+			fileMap: extractor.MapFileMap{"etc/os-release": []byte(`NAME="openSUSE Leap"
+VERSION="42.3"
+ID=opensuse
+ID_LIKE="suse"
+VERSION_ID="42.3"
+`)},
+			expected: analyzer.OS{Family: "opensuse.leap", Name: "42.3"},
+		},
+		"Tumbleweed": {
+			fileMap: extractor.MapFileMap{"etc/os-release": []byte(`NAME="openSUSE Tumbleweed"
+ID="opensuse-tumbleweed"
+ID_LIKE="opensuse suse"
+VERSION_ID="20191231"
+`)},
+			expected: analyzer.OS{Family: "opensuse.tumbleweed", Name: "20191231"},
+		},
+		"TumbleweedDerivative": {
+			fileMap: extractor.MapFileMap{"etc/os-release": []byte(`NAME="openSUSE MicroOS"
+ID="opensuse-microos"
+ID_LIKE="suse opensuse opensuse-tumbleweed microos"
+VERSION_ID="20200101"
+`)},
+			expected: analyzer.OS{Family: "opensuse.tumbleweed", Name: "20200101"},
+		},
+		"OpenSUSE13": {
+			fileMap: extractor.MapFileMap{"etc/os-release": []byte(`NAME=openSUSE
+VERSION="13.2 (Harlequin)"
+ID=opensuse
+VERSION_ID="13.2"
+`)},
+			expected: analyzer.OS{Family: "opensuse", Name: "13.2"},
+		},
+		"SLES11": {
+			fileMap: extractor.MapFileMap{"etc/SuSE-release": []byte(`SUSE Linux Enterprise Server 11 (x86_64)
+VERSION = 11
+PATCHLEVEL = 4
+`)},
+			expected: analyzer.OS{Family: "suse", Name: "11.4"},
+		},
+		"OpenSUSE13SuSERelease": {
+			fileMap: extractor.MapFileMap{"etc/SuSE-release": []byte(`openSUSE 13.2 (x86_64)
+VERSION = 13.2
+CODENAME = Harlequin
+`)},
+			expected: analyzer.OS{Family: "opensuse", Name: "13.2"},
+		},
+		"InvalidSuSERelease": {
+			fileMap: extractor.MapFileMap{"etc/SuSE-release": []byte("foo\n")},
+			err:     analyzer.ErrMalformedFile,
+		},
+		"Debian": {
+			fileMap: extractor.MapFileMap{"etc/os-release": []byte(`NAME="Debian GNU/Linux"
+VERSION_ID="10"
+ID=debian
+`)},
+			err: analyzer.ErrNoAnalyzerMatch,
+		},
+	}
+	a := suseOSAnalyzer{}
+	for testName, v := range tests {
+		actual, err := a.Analyze(v.fileMap)
+		if v.err != nil {
+			if !xerrors.Is(err, v.err) {
+				t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] catch the error : %v", testName, err)
+		}
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package os
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// ParseOSRelease returns the fields of os-release keyed by the names, e.g. "ID" and "VERSION_ID".
+// The quotes of the values are removed, e.g. VERSION_ID="15.1" is 15.1.
+func ParseOSRelease(file []byte) map[string]string {
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewBuffer(file))
+	for scanner.Scan() {
+		kv := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		fields[kv[0]] = strings.Trim(kv[1], `"'`)
+	}
+	return fields
+}
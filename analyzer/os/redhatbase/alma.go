@@ -0,0 +1,25 @@
+package redhatbase
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer/os"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+// almaOSAnalyzer detects AlmaLinux by etc/almalinux-release, e.g. "AlmaLinux release 8.4 (Electric Cheetah)".
+type almaOSAnalyzer struct{}
+
+func (a almaOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	file, ok := fileMap.Get("etc/almalinux-release")
+	if !ok {
+		return analyzer.OS{}, xerrors.Errorf("alma: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+	return parseRelease(file, os.Alma, "almalinux-release")
+}
+
+func (a almaOSAnalyzer) RequiredFiles() []string {
+	return []string{"etc/almalinux-release"}
+}
@@ -0,0 +1,39 @@
+package redhatbase
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer/os"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+// coreosOSAnalyzer detects Fedora CoreOS by VARIANT_ID=coreos of os-release, which has etc/fedora-release
+// of Fedora as well. CoreOS Container Linux with ID=coreos is detected as well.
+type coreosOSAnalyzer struct{}
+
+func (a coreosOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	for _, filename := range a.RequiredFiles() {
+		file, ok := fileMap.Get(filename)
+		if !ok {
+			continue
+		}
+		fields := os.ParseOSRelease(file)
+		if fields["ID"] != "coreos" && (fields["ID"] != "fedora" || fields["VARIANT_ID"] != "coreos") {
+			continue
+		}
+		if fields["VERSION_ID"] == "" {
+			return analyzer.OS{}, xerrors.Errorf("coreos: no VERSION_ID in os-release: %w", analyzer.ErrMalformedFile)
+		}
+		return analyzer.OS{Family: os.CoreOS, Name: fields["VERSION_ID"]}, nil
+	}
+	return analyzer.OS{}, xerrors.Errorf("coreos: %w", analyzer.ErrNoAnalyzerMatch)
+}
+
+func (a coreosOSAnalyzer) RequiredFiles() []string {
+	return []string{
+		"usr/lib/os-release",
+		"etc/os-release",
+	}
+}
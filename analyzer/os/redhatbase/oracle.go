@@ -0,0 +1,25 @@
+package redhatbase
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer/os"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+// oracleOSAnalyzer detects Oracle Linux, whose etc/redhat-release is the same as RHEL up to Oracle Linux 7.
+type oracleOSAnalyzer struct{}
+
+func (a oracleOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	file, ok := fileMap.Get("etc/oracle-release")
+	if !ok {
+		return analyzer.OS{}, xerrors.Errorf("oracle: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+	return parseRelease(file, os.Oracle, "oracle-release")
+}
+
+func (a oracleOSAnalyzer) RequiredFiles() []string {
+	return []string{"etc/oracle-release"}
+}
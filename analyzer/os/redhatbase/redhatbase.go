@@ -3,10 +3,11 @@ package redhatbase
 import (
 	"bufio"
 	"bytes"
-	"errors"
 	"regexp"
 	"strings"
 
+	"golang.org/x/xerrors"
+
 	"github.com/knqyf263/fanal/analyzer/os"
 
 	"github.com/knqyf263/fanal/analyzer"
@@ -14,6 +15,12 @@ import (
 )
 
 func init() {
+	// The rebuilds of RHEL and Fedora CoreOS are detected before etc/redhat-release and etc/fedora-release,
+	// which they may have as well
+	analyzer.RegisterOSAnalyzer(&oracleOSAnalyzer{})
+	analyzer.RegisterOSAnalyzer(&rockyOSAnalyzer{})
+	analyzer.RegisterOSAnalyzer(&almaOSAnalyzer{})
+	analyzer.RegisterOSAnalyzer(&coreosOSAnalyzer{})
 	analyzer.RegisterOSAnalyzer(&redhatOSAnalyzer{})
 }
 
@@ -22,13 +29,13 @@ type redhatOSAnalyzer struct{}
 var redhatRe = regexp.MustCompile(`(.*) release (\d[\d\.]*)`)
 
 func (a redhatOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
-	if file, ok := fileMap["etc/centos-release"]; ok {
+	if file, ok := fileMap.Get("etc/centos-release"); ok {
 		scanner := bufio.NewScanner(bytes.NewBuffer(file))
 		for scanner.Scan() {
 			line := scanner.Text()
 			result := redhatRe.FindStringSubmatch(strings.TrimSpace(line))
 			if len(result) != 3 {
-				return analyzer.OS{}, errors.New("cent: Invalid centos-release")
+				return analyzer.OS{}, xerrors.Errorf("cent: invalid centos-release: %w", analyzer.ErrMalformedFile)
 			}
 
 			switch strings.ToLower(result[1]) {
@@ -38,33 +45,28 @@ func (a redhatOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error
 		}
 	}
 
-	if file, ok := fileMap["etc/oracle-release"]; ok {
-		scanner := bufio.NewScanner(bytes.NewBuffer(file))
-		for scanner.Scan() {
-			line := scanner.Text()
-			result := redhatRe.FindStringSubmatch(strings.TrimSpace(line))
-			if len(result) != 3 {
-				return analyzer.OS{}, errors.New("oracle: Invalid oracle-release")
-			}
-			return analyzer.OS{Family: os.Oracle, Name: result[2]}, nil
+	// e.g. the malformed etc/oracle-release, whose etc/redhat-release may be of RHEL
+	for _, filename := range []string{"etc/oracle-release", "etc/rocky-release", "etc/almalinux-release"} {
+		if _, ok := fileMap.Get(filename); ok {
+			return analyzer.OS{}, xerrors.Errorf("redhatbase: %w", analyzer.ErrNoAnalyzerMatch)
 		}
 	}
 
-	if file, ok := fileMap["usr/lib/fedora-release"]; ok {
+	if file, ok := fileMap.Get("usr/lib/fedora-release"); ok {
 		return parseFedoraRelease(file)
 	}
 
-	if file, ok := fileMap["etc/fedora-release"]; ok {
+	if file, ok := fileMap.Get("etc/fedora-release"); ok {
 		return parseFedoraRelease(file)
 	}
 
-	if file, ok := fileMap["etc/redhat-release"]; ok {
+	if file, ok := fileMap.Get("etc/redhat-release"); ok {
 		scanner := bufio.NewScanner(bytes.NewBuffer(file))
 		for scanner.Scan() {
 			line := scanner.Text()
 			result := redhatRe.FindStringSubmatch(strings.TrimSpace(line))
 			if len(result) != 3 {
-				return analyzer.OS{}, errors.New("redhat: Invalid redhat-release")
+				return analyzer.OS{}, xerrors.Errorf("redhat: invalid redhat-release: %w", analyzer.ErrMalformedFile)
 			}
 
 			switch strings.ToLower(result[1]) {
@@ -72,6 +74,10 @@ func (a redhatOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error
 				return analyzer.OS{Family: os.CentOS, Name: result[2]}, nil
 			case "oracle", "oracle linux", "oracle linux server":
 				return analyzer.OS{Family: os.Oracle, Name: result[2]}, nil
+			case "rocky", "rocky linux":
+				return analyzer.OS{Family: os.Rocky, Name: result[2]}, nil
+			case "almalinux":
+				return analyzer.OS{Family: os.Alma, Name: result[2]}, nil
 			case "fedora", "fedora linux":
 				return analyzer.OS{Family: os.Fedora, Name: result[2]}, nil
 			default:
@@ -80,7 +86,7 @@ func (a redhatOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error
 		}
 	}
 
-	return analyzer.OS{}, errors.New("redhatbase : Not match")
+	return analyzer.OS{}, xerrors.Errorf("redhatbase: %w", analyzer.ErrNoAnalyzerMatch)
 }
 
 func parseFedoraRelease(file []byte) (analyzer.OS, error) {
@@ -89,7 +95,7 @@ func parseFedoraRelease(file []byte) (analyzer.OS, error) {
 		line := scanner.Text()
 		result := redhatRe.FindStringSubmatch(strings.TrimSpace(line))
 		if len(result) != 3 {
-			return analyzer.OS{}, errors.New("cent: Invalid fedora-release")
+			return analyzer.OS{}, xerrors.Errorf("cent: invalid fedora-release: %w", analyzer.ErrMalformedFile)
 		}
 
 		switch strings.ToLower(result[1]) {
@@ -97,13 +103,26 @@ func parseFedoraRelease(file []byte) (analyzer.OS, error) {
 			return analyzer.OS{Family: os.Fedora, Name: result[2]}, nil
 		}
 	}
-	return analyzer.OS{}, errors.New("cent: Invalid fedora-release")
+	return analyzer.OS{}, xerrors.Errorf("cent: invalid fedora-release: %w", analyzer.ErrMalformedFile)
+}
+
+// parseRelease parses the release file of the family, e.g. "Oracle Linux Server release 7.8".
+func parseRelease(file []byte, family, filename string) (analyzer.OS, error) {
+	scanner := bufio.NewScanner(bytes.NewBuffer(file))
+	if scanner.Scan() {
+		if result := redhatRe.FindStringSubmatch(strings.TrimSpace(scanner.Text())); len(result) == 3 {
+			return analyzer.OS{Family: family, Name: result[2]}, nil
+		}
+	}
+	return analyzer.OS{}, xerrors.Errorf("%s: invalid %s: %w", family, filename, analyzer.ErrMalformedFile)
 }
 
 func (a redhatOSAnalyzer) RequiredFiles() []string {
 	return []string{
 		"etc/redhat-release",
 		"etc/oracle-release",
+		"etc/rocky-release",
+		"etc/almalinux-release",
 		"etc/fedora-release",
 		"usr/lib/fedora-release",
 		"etc/centos-release",
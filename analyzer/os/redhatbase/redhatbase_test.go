@@ -0,0 +1,108 @@
+package redhatbase
+
+import (
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestAnalyze(t *testing.T) {
+	var tests = map[string]struct {
+		fileMap  extractor.FileMap
+		expected analyzer.OS
+		err      error
+	}{
+		"Oracle7": {
+			fileMap: extractor.MapFileMap{
+				"etc/oracle-release": []byte("Oracle Linux Server release 7.8\n"),
+				"etc/redhat-release": []byte("Red Hat Enterprise Linux Server release 7.8 (Maipo)\n"),
+			},
+			expected: analyzer.OS{Family: "oracle", Name: "7.8"},
+		},
+		"Rocky": {
+			fileMap: extractor.MapFileMap{
+				"etc/rocky-release":  []byte("Rocky Linux release 8.4 (Green Obsidian)\n"),
+				"etc/redhat-release": []byte("Rocky Linux release 8.4 (Green Obsidian)\n"),
+			},
+			expected: analyzer.OS{Family: "rocky", Name: "8.4"},
+		},
+		"Alma": {
+			fileMap: extractor.MapFileMap{
+				"etc/almalinux-release": []byte("AlmaLinux release 8.4 (Electric Cheetah)\n"),
+				"etc/redhat-release":    []byte("AlmaLinux release 8.4 (Electric Cheetah)\n"),
+			},
+			expected: analyzer.OS{Family: "alma", Name: "8.4"},
+		},
+		"RockyRedHatRelease": {
+			fileMap:  extractor.MapFileMap{"etc/redhat-release": []byte("Rocky Linux release 8.4 (Green Obsidian)\n")},
+			expected: analyzer.OS{Family: "rocky", Name: "8.4"},
+		},
+		"FedoraCoreOS": {
+			fileMap: extractor.MapFileMap{
+				"etc/os-release":     []byte("NAME=Fedora\nVERSION=\"34.20210626.3.1 (CoreOS)\"\nID=fedora\nVERSION_ID=34\nVARIANT=\"CoreOS\"\nVARIANT_ID=coreos\n"),
+				"etc/fedora-release": []byte("Fedora release 34 (Thirty Four)\n"),
+			},
+			expected: analyzer.OS{Family: "coreos", Name: "34"},
+		},
+		"Fedora": {
+			fileMap: extractor.MapFileMap{
+				"etc/os-release":     []byte("NAME=Fedora\nVERSION=\"34 (Container Image)\"\nID=fedora\nVERSION_ID=34\nVARIANT_ID=container\n"),
+				"etc/fedora-release": []byte("Fedora release 34 (Thirty Four)\n"),
+			},
+			expected: analyzer.OS{Family: "fedora", Name: "34"},
+		},
+		"RedHat": {
+			fileMap:  extractor.MapFileMap{"etc/redhat-release": []byte("Red Hat Enterprise Linux release 8.4 (Ootpa)\n")},
+			expected: analyzer.OS{Family: "redhat", Name: "8.4"},
+		},
+		// Not RHEL even though etc/redhat-release is of RHEL
+		"MalformedOracleRelease": {
+			fileMap: extractor.MapFileMap{
+				"etc/oracle-release": []byte("foo\n"),
+				"etc/redhat-release": []byte("Red Hat Enterprise Linux Server release 7.8 (Maipo)\n"),
+			},
+			err: analyzer.ErrMalformedFile,
+		},
+		"NoReleaseFile": {
+			fileMap: extractor.MapFileMap{},
+			err:     analyzer.ErrNoAnalyzerMatch,
+		},
+	}
+	for testName, v := range tests {
+		actual, err := analyze(v.fileMap)
+		if v.err != nil {
+			if !xerrors.Is(err, v.err) {
+				t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] catch the error : %v", testName, err)
+		}
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+// analyze runs the analyzers in the order of the registration like analyzer.GetOS.
+// The error of the first analyzer failed is returned if none of them matches.
+func analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	var failed error
+	for _, a := range []analyzer.OSAnalyzer{oracleOSAnalyzer{}, rockyOSAnalyzer{}, almaOSAnalyzer{}, coreosOSAnalyzer{}, redhatOSAnalyzer{}} {
+		os, err := a.Analyze(fileMap)
+		if err == nil {
+			return os, nil
+		}
+		if failed == nil && !xerrors.Is(err, analyzer.ErrNoAnalyzerMatch) {
+			failed = err
+		}
+	}
+	if failed != nil {
+		return analyzer.OS{}, failed
+	}
+	return analyzer.OS{}, analyzer.ErrNoAnalyzerMatch
+}
@@ -0,0 +1,25 @@
+package redhatbase
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer/os"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+// rockyOSAnalyzer detects Rocky Linux by etc/rocky-release, e.g. "Rocky Linux release 8.4 (Green Obsidian)".
+type rockyOSAnalyzer struct{}
+
+func (a rockyOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	file, ok := fileMap.Get("etc/rocky-release")
+	if !ok {
+		return analyzer.OS{}, xerrors.Errorf("rocky: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+	return parseRelease(file, os.Rocky, "rocky-release")
+}
+
+func (a rockyOSAnalyzer) RequiredFiles() []string {
+	return []string{"etc/rocky-release"}
+}
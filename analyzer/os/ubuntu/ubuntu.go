@@ -3,9 +3,10 @@ package ubuntu
 import (
 	"bufio"
 	"bytes"
-	"errors"
 	"strings"
 
+	"golang.org/x/xerrors"
+
 	"github.com/knqyf263/fanal/analyzer/os"
 
 	"github.com/knqyf263/fanal/analyzer"
@@ -20,7 +21,7 @@ type ubuntuOSAnalyzer struct{}
 
 func (a ubuntuOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
 	for _, filename := range a.RequiredFiles() {
-		file, ok := fileMap[filename]
+		file, ok := fileMap.Get(filename)
 		if !ok {
 			continue
 		}
@@ -41,7 +42,7 @@ func (a ubuntuOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error
 			}
 		}
 	}
-	return analyzer.OS{}, errors.New("ubuntu: Not match")
+	return analyzer.OS{}, xerrors.Errorf("ubuntu: %w", analyzer.ErrNoAnalyzerMatch)
 }
 
 func (a ubuntuOSAnalyzer) RequiredFiles() []string {
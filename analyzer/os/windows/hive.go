@@ -0,0 +1,226 @@
+package windows
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/xerrors"
+)
+
+// hive is a minimal reader of registry hive files (regf).
+// It only supports what is needed to read values of a key.
+// ref. https://github.com/msuhanov/regf/blob/master/Windows%20registry%20file%20format%20specification.md
+type hive struct {
+	data []byte
+}
+
+const (
+	baseBlockSize = 4096
+
+	regSZ       = 1
+	regExpandSZ = 2
+	regDWORD    = 4
+
+	keyCompName   = 0x0020
+	valueCompName = 0x0001
+)
+
+func newHive(data []byte) (*hive, error) {
+	if len(data) < baseBlockSize || !bytes.Equal(data[:4], []byte("regf")) {
+		return nil, xerrors.New("not a registry hive")
+	}
+	return &hive{data: data}, nil
+}
+
+// cell returns the data of the cell at the offset relative to the first hive bin.
+func (h *hive) cell(offset uint32) ([]byte, error) {
+	start := int64(baseBlockSize) + int64(offset)
+	if start+4 > int64(len(h.data)) {
+		return nil, xerrors.Errorf("cell out of range: %d", offset)
+	}
+	size := int32(binary.LittleEndian.Uint32(h.data[start:]))
+	if size < 0 {
+		size = -size
+	}
+	end := start + int64(size)
+	if size < 4 || end > int64(len(h.data)) {
+		return nil, xerrors.Errorf("invalid cell size: %d", offset)
+	}
+	return h.data[start+4 : end], nil
+}
+
+// rootKey returns the offset of the root key.
+func (h *hive) rootKey() uint32 {
+	return binary.LittleEndian.Uint32(h.data[0x24:])
+}
+
+// openKey follows the path like `Microsoft\Windows NT\CurrentVersion` from the root key.
+func (h *hive) openKey(path string) (uint32, error) {
+	offset := h.rootKey()
+	for _, name := range strings.Split(path, `\`) {
+		var err error
+		offset, err = h.subkey(offset, name)
+		if err != nil {
+			return 0, xerrors.Errorf("failed to open %s: %w", name, err)
+		}
+	}
+	return offset, nil
+}
+
+func (h *hive) key(offset uint32) ([]byte, error) {
+	nk, err := h.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(nk) < 0x4C || !bytes.Equal(nk[:2], []byte("nk")) {
+		return nil, xerrors.Errorf("not a key node: %d", offset)
+	}
+	return nk, nil
+}
+
+func (h *hive) keyName(nk []byte) string {
+	flags := binary.LittleEndian.Uint16(nk[0x02:])
+	nameLen := int(binary.LittleEndian.Uint16(nk[0x48:]))
+	if 0x4C+nameLen > len(nk) {
+		return ""
+	}
+	return decodeName(nk[0x4C:0x4C+nameLen], flags&keyCompName != 0)
+}
+
+func (h *hive) subkey(offset uint32, name string) (uint32, error) {
+	nk, err := h.key(offset)
+	if err != nil {
+		return 0, err
+	}
+	if binary.LittleEndian.Uint32(nk[0x14:]) == 0 {
+		return 0, xerrors.New("no subkey")
+	}
+	offsets, err := h.subkeyList(binary.LittleEndian.Uint32(nk[0x1C:]))
+	if err != nil {
+		return 0, err
+	}
+	for _, o := range offsets {
+		child, err := h.key(o)
+		if err != nil {
+			return 0, err
+		}
+		if strings.EqualFold(h.keyName(child), name) {
+			return o, nil
+		}
+	}
+	return 0, xerrors.New("no such key")
+}
+
+func (h *hive) subkeyList(offset uint32) ([]uint32, error) {
+	list, err := h.cell(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) < 4 {
+		return nil, xerrors.New("invalid subkey list")
+	}
+	count := int(binary.LittleEndian.Uint16(list[2:]))
+
+	var offsets []uint32
+	switch string(list[:2]) {
+	case "lf", "lh":
+		// offset and hash
+		for i := 0; i < count && 4+i*8+4 <= len(list); i++ {
+			offsets = append(offsets, binary.LittleEndian.Uint32(list[4+i*8:]))
+		}
+	case "li":
+		for i := 0; i < count && 4+i*4+4 <= len(list); i++ {
+			offsets = append(offsets, binary.LittleEndian.Uint32(list[4+i*4:]))
+		}
+	case "ri":
+		// list of subkey lists
+		for i := 0; i < count && 4+i*4+4 <= len(list); i++ {
+			sub, err := h.subkeyList(binary.LittleEndian.Uint32(list[4+i*4:]))
+			if err != nil {
+				return nil, err
+			}
+			offsets = append(offsets, sub...)
+		}
+	default:
+		return nil, xerrors.Errorf("unknown subkey list: %q", list[:2])
+	}
+	return offsets, nil
+}
+
+// values returns REG_SZ, REG_EXPAND_SZ and REG_DWORD values of the key.
+// REG_DWORD values are returned as uint32 and the others as string.
+func (h *hive) values(offset uint32) (map[string]interface{}, error) {
+	nk, err := h.key(offset)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	count := int(binary.LittleEndian.Uint32(nk[0x24:]))
+	if count == 0 {
+		return values, nil
+	}
+	list, err := h.cell(binary.LittleEndian.Uint32(nk[0x28:]))
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < count && i*4+4 <= len(list); i++ {
+		vk, err := h.cell(binary.LittleEndian.Uint32(list[i*4:]))
+		if err != nil {
+			return nil, err
+		}
+		if len(vk) < 0x14 || !bytes.Equal(vk[:2], []byte("vk")) {
+			continue
+		}
+		nameLen := int(binary.LittleEndian.Uint16(vk[0x02:]))
+		dataSize := binary.LittleEndian.Uint32(vk[0x04:])
+		dataType := binary.LittleEndian.Uint32(vk[0x0C:])
+		flags := binary.LittleEndian.Uint16(vk[0x10:])
+		if 0x14+nameLen > len(vk) {
+			continue
+		}
+		name := decodeName(vk[0x14:0x14+nameLen], flags&valueCompName != 0)
+
+		// The data of 4 bytes or less is stored in the offset field
+		var data []byte
+		if dataSize&0x80000000 != 0 {
+			size := dataSize & 0x7fffffff
+			if size > 4 {
+				continue
+			}
+			data = vk[0x08 : 0x08+size]
+		} else {
+			cell, err := h.cell(binary.LittleEndian.Uint32(vk[0x08:]))
+			if err != nil || int(dataSize) > len(cell) {
+				continue
+			}
+			data = cell[:dataSize]
+		}
+
+		switch dataType {
+		case regSZ, regExpandSZ:
+			values[name] = decodeUTF16(data)
+		case regDWORD:
+			if len(data) == 4 {
+				values[name] = binary.LittleEndian.Uint32(data)
+			}
+		}
+	}
+	return values, nil
+}
+
+func decodeName(b []byte, ascii bool) string {
+	if ascii {
+		return string(b)
+	}
+	return decodeUTF16(b)
+}
+
+func decodeUTF16(b []byte) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+}
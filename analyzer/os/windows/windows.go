@@ -0,0 +1,67 @@
+package windows
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer/os"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+const currentVersionKey = `Microsoft\Windows NT\CurrentVersion`
+
+func init() {
+	analyzer.RegisterOSAnalyzer(&windowsOSAnalyzer{})
+}
+
+type windowsOSAnalyzer struct{}
+
+// Analyze reads the build number from the SOFTWARE registry hive, e.g. "10.0.17763.1234".
+func (a windowsOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	for _, filename := range a.RequiredFiles() {
+		file, ok := fileMap.Get(filename)
+		if !ok {
+			continue
+		}
+		h, err := newHive(file)
+		if err != nil {
+			return analyzer.OS{}, xerrors.Errorf("windows: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		key, err := h.openKey(currentVersionKey)
+		if err != nil {
+			return analyzer.OS{}, xerrors.Errorf("windows: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		values, err := h.values(key)
+		if err != nil {
+			return analyzer.OS{}, xerrors.Errorf("windows: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+
+		build, _ := values["CurrentBuildNumber"].(string)
+		if build == "" {
+			build, _ = values["CurrentBuild"].(string)
+		}
+		if build == "" {
+			return analyzer.OS{}, xerrors.Errorf("windows: no build number: %w", analyzer.ErrMalformedFile)
+		}
+
+		name := build
+		major, okMajor := values["CurrentMajorVersionNumber"].(uint32)
+		minor, okMinor := values["CurrentMinorVersionNumber"].(uint32)
+		if okMajor && okMinor {
+			name = fmt.Sprintf("%d.%d.%s", major, minor, build)
+		}
+		if ubr, ok := values["UBR"].(uint32); ok {
+			name = fmt.Sprintf("%s.%d", name, ubr)
+		}
+		return analyzer.OS{Family: os.Windows, Name: name}, nil
+	}
+	return analyzer.OS{}, xerrors.Errorf("windows: %w", analyzer.ErrNoAnalyzerMatch)
+}
+
+func (a windowsOSAnalyzer) RequiredFiles() []string {
+	// Windows image layers have files under Files/
+	return []string{"Files/Windows/System32/config/SOFTWARE"}
+}
@@ -0,0 +1,53 @@
+package windows
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestAnalyze(t *testing.T) {
+	b, err := ioutil.ReadFile("./testdata/SOFTWARE")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		fileMap  extractor.FileMap
+		expected analyzer.OS
+		err      error
+	}{
+		"Server2019": {
+			fileMap:  extractor.MapFileMap{"Files/Windows/System32/config/SOFTWARE": b},
+			expected: analyzer.OS{Family: "windows", Name: "10.0.17763.1282"},
+		},
+		"NotHive": {
+			fileMap: extractor.MapFileMap{"Files/Windows/System32/config/SOFTWARE": []byte("foo")},
+			err:     analyzer.ErrMalformedFile,
+		},
+		"NoHive": {
+			fileMap: extractor.MapFileMap{},
+			err:     analyzer.ErrNoAnalyzerMatch,
+		},
+	}
+	a := windowsOSAnalyzer{}
+	for testName, v := range tests {
+		actual, err := a.Analyze(v.fileMap)
+		if v.err != nil {
+			if !xerrors.Is(err, v.err) {
+				t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] catch the error : %v", testName, err)
+		}
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"bytes"
 	"log"
 
-	"github.com/pkg/errors"
+	"golang.org/x/xerrors"
 
 	"github.com/coreos/clair/ext/versionfmt"
 	clairDpkg "github.com/coreos/clair/ext/versionfmt/dpkg"
@@ -24,7 +24,7 @@ func (a alpinePkgAnalyzer) Analyze(fileMap extractor.FileMap) (pkgs []analyzer.P
 	var parsedPkgs []analyzer.Package
 	detected := false
 	for _, filename := range a.RequiredFiles() {
-		file, ok := fileMap[filename]
+		file, ok := fileMap.Get(filename)
 		if !ok {
 			continue
 		}
@@ -34,7 +34,7 @@ func (a alpinePkgAnalyzer) Analyze(fileMap extractor.FileMap) (pkgs []analyzer.P
 		detected = true
 	}
 	if !detected {
-		return pkgs, errors.New("No package detected")
+		return pkgs, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
 	}
 	return pkgs, nil
 }
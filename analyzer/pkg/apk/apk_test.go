@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/knqyf263/fanal/analyzer"
+	atesting "github.com/knqyf263/fanal/analyzer/testing"
 )
 
 func TestParseApkInfo(t *testing.T) {
@@ -47,3 +48,7 @@ func TestParseApkInfo(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkAlpinePkg(b *testing.B) {
+	atesting.RunAnalyzerBenchmark(b, alpinePkgAnalyzer{}, map[string]string{"lib/apk/db/installed": "testdata/apk"})
+}
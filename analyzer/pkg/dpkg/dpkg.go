@@ -3,12 +3,14 @@ package dpkg
 import (
 	"bufio"
 	"bytes"
-	"errors"
 	"log"
+	"path"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
-	"github.com/deckarep/golang-set"
+	"golang.org/x/xerrors"
 
 	"github.com/coreos/clair/ext/versionfmt"
 	"github.com/coreos/clair/ext/versionfmt/dpkg"
@@ -24,31 +26,181 @@ var (
 
 func init() {
 	analyzer.RegisterPkgAnalyzer(&debianPkgAnalyzer{})
+	analyzer.RegisterSourceAnalyzer(&debianPkgAnalyzer{})
 }
 
 type debianPkgAnalyzer struct{}
 
+const (
+	statusFile    = "var/lib/dpkg/status"
+	statusDir     = "var/lib/dpkg/status.d"
+	logFile       = "var/log/dpkg.log"
+	copyrightFile = "usr/share/doc/*/copyright"
+	conffilesFile = "var/lib/dpkg/info/*.conffiles"
+	listFile      = "var/lib/dpkg/info/*.list"
+)
+
 func (a debianPkgAnalyzer) Analyze(fileMap extractor.FileMap) (pkgs []analyzer.Package, err error) {
-	detected := false
-	for _, filename := range a.RequiredFiles() {
-		file, ok := fileMap[filename]
-		if !ok {
+	file, ok := a.statusDatabase(fileMap)
+	if !ok {
+		return pkgs, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+	scanner := bufio.NewScanner(bytes.NewBuffer(file))
+	pkgs = a.parseDpkginfo(scanner)
+
+	for i, pkg := range pkgs {
+		if pkg.Type != analyzer.TypeBinary {
 			continue
 		}
-		scanner := bufio.NewScanner(bytes.NewBuffer(file))
-		pkgs = a.parseDpkginfo(scanner)
-		detected = true
+		if copyright, ok := fileMap.Get("usr/share/doc/" + pkg.Name + "/copyright"); ok {
+			pkgs[i].License = a.parseCopyright(bufio.NewScanner(bytes.NewBuffer(copyright)))
+		}
 	}
-	if !detected {
-		return pkgs, errors.New("No package detected")
+
+	conffiles := a.parseConffiles(fileMap)
+	for i, pkg := range pkgs {
+		if pkg.Type == analyzer.TypeBinary {
+			pkgs[i].ConfigFiles = conffiles[pkg.Name]
+		}
+	}
+
+	// dpkg.log is often removed from images, so install times are optional
+	if log, ok := fileMap.Get(logFile); ok {
+		installedAt := a.parseDpkgLog(bufio.NewScanner(bytes.NewBuffer(log)))
+		for i, pkg := range pkgs {
+			if pkg.Type != analyzer.TypeBinary {
+				continue
+			}
+			if t, ok := installedAt[pkg.Name+" "+pkg.Version]; ok {
+				t := t
+				pkgs[i].InstalledAt = &t
+			}
+		}
 	}
 	return pkgs, nil
 }
 
+// statusDatabase returns var/lib/dpkg/status followed by the files in var/lib/dpkg/status.d, which distroless
+// images have instead of the status file with a package per file. The md5sums files in the directory are skipped.
+func (a debianPkgAnalyzer) statusDatabase(fileMap extractor.FileMap) ([]byte, bool) {
+	var files [][]byte
+	if file, ok := fileMap.Get(statusFile); ok {
+		files = append(files, file)
+	}
+	for _, filePath := range fileMap.Paths() {
+		if !strings.HasPrefix(filePath, statusDir+"/") || strings.HasSuffix(filePath, ".md5sums") {
+			continue
+		}
+		if file, ok := fileMap.Get(filePath); ok {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, false
+	}
+	// The paragraphs are separated by the empty lines
+	return bytes.Join(files, []byte("\n\n")), true
+}
+
+// parseCopyright returns the first License: field in the machine-readable copyright file.
+// ref. https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+func (a debianPkgAnalyzer) parseCopyright(scanner *bufio.Scanner) string {
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "License:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "License:"))
+		}
+	}
+	return ""
+}
+
+// parseConffiles returns the config files keyed by the package name.
+func (a debianPkgAnalyzer) parseConffiles(fileMap extractor.FileMap) map[string][]string {
+	return parseInfoFiles(fileMap, conffilesFile)
+}
+
+// parseInfoFiles returns the paths in the info files matching the pattern, keyed by the package name.
+// The file name of a Multi-Arch: same package has the architecture, e.g. info/libc6:amd64.conffiles
+func parseInfoFiles(fileMap extractor.FileMap, pattern string) map[string][]string {
+	ext := path.Ext(pattern)
+	files := map[string][]string{}
+	for _, filePath := range fileMap.Paths() {
+		if !extractor.Match(pattern, filePath) {
+			continue
+		}
+		content, _ := fileMap.Get(filePath)
+		name := strings.SplitN(strings.TrimSuffix(path.Base(filePath), ext), ":", 2)[0]
+		scanner := bufio.NewScanner(bytes.NewBuffer(content))
+		for scanner.Scan() {
+			// Newer dpkg may prefix "remove-on-upgrade" to the path in conffiles.
+			// Paths can contain spaces.
+			line := strings.TrimSpace(scanner.Text())
+			i := strings.Index(line, "/")
+			if i < 0 {
+				continue
+			}
+			files[name] = append(files[name], line[i:])
+		}
+	}
+	for name := range files {
+		sort.Strings(files[name])
+	}
+	return files
+}
+
+// FileListProvider returns the files in /var/lib/dpkg/info/<pkg>.list.
+type FileListProvider struct {
+	files map[string][]string
+}
+
+// FileListRequiredFiles returns the patterns of the file lists read by NewFileListProvider.
+// They are not required by the analyzer, so pass them to the extractor along with
+// analyzer.RequiredFilenames when building a soname map.
+func FileListRequiredFiles() []string {
+	return []string{listFile}
+}
+
+// NewFileListProvider reads the file lists in the extracted files, see analyzer.BuildSonameMap.
+// The files must be extracted with FileListRequiredFiles, otherwise no files are returned.
+func NewFileListProvider(fileMap extractor.FileMap) FileListProvider {
+	return FileListProvider{files: parseInfoFiles(fileMap, listFile)}
+}
+
+func (p FileListProvider) Files(pkg analyzer.Package) []string {
+	return p.files[pkg.Name]
+}
+
+// parseDpkgLog returns the last time each package was installed, keyed by "<name> <version>".
+// e.g. 2019-05-07 07:24:33 status installed bash:amd64 4.4.18-2ubuntu1
+func (a debianPkgAnalyzer) parseDpkgLog(scanner *bufio.Scanner) map[string]time.Time {
+	installedAt := map[string]time.Time{}
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 6 || fields[2] != "status" || fields[3] != "installed" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02 15:04:05", fields[0]+" "+fields[1])
+		if err != nil {
+			continue
+		}
+		name := strings.SplitN(fields[4], ":", 2)[0]
+		installedAt[name+" "+fields[5]] = t
+	}
+	return installedAt
+}
+
 func (a debianPkgAnalyzer) parseDpkginfo(scanner *bufio.Scanner) (pkgs []analyzer.Package) {
 	var bin, src *analyzer.Package
-	pkgMap := mapset.NewSet()
-	srcPkgMap := mapset.NewSet()
+	var binPkgs, srcPkgs []analyzer.Package
+	seen := map[pkgKey]struct{}{}
+	add := func(pkgs []analyzer.Package, pkg analyzer.Package) []analyzer.Package {
+		key := pkgKey{name: pkg.Name, version: pkg.Version, typ: pkg.Type}
+		if _, ok := seen[key]; ok {
+			return pkgs
+		}
+		seen[key] = struct{}{}
+		return append(pkgs, pkg)
+	}
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -60,31 +212,29 @@ func (a debianPkgAnalyzer) parseDpkginfo(scanner *bufio.Scanner) (pkgs []analyze
 
 		bin, src = a.parseDpkgPkg(scanner)
 		if bin != nil {
-			pkgMap.Add(*bin)
+			binPkgs = add(binPkgs, *bin)
 		}
 
 		if src != nil {
-			srcPkgMap.Add(*src)
+			srcPkgs = add(srcPkgs, *src)
 		}
 	}
-	pkgs = mapsetToSlice(pkgMap)
-	pkgs = append(pkgs, mapsetToSlice(srcPkgMap)...)
-	return pkgs
+	return append(binPkgs, srcPkgs...)
 }
 
-func mapsetToSlice(features mapset.Set) []analyzer.Package {
-	uniqueLayerFeatures := make([]analyzer.Package, 0, features.Cardinality())
-	for f := range features.Iter() {
-		feature := f.(analyzer.Package)
-		uniqueLayerFeatures = append(uniqueLayerFeatures, feature)
-	}
-	return uniqueLayerFeatures
+// pkgKey identifies a package in the status file. A source package is shared by binary packages.
+type pkgKey struct {
+	name    string
+	version string
+	typ     string
 }
 
 func (a debianPkgAnalyzer) parseDpkgPkg(scanner *bufio.Scanner) (binPkg *analyzer.Package, srcPkg *analyzer.Package) {
 	var (
 		name          string
 		version       string
+		homepage      string
+		maintainer    string
 		sourceName    string
 		sourceVersion string
 	)
@@ -113,6 +263,10 @@ func (a debianPkgAnalyzer) parseDpkgPkg(scanner *bufio.Scanner) (binPkg *analyze
 			}
 		} else if strings.HasPrefix(line, "Version: ") {
 			version = strings.TrimPrefix(line, "Version: ")
+		} else if strings.HasPrefix(line, "Homepage: ") {
+			homepage = strings.TrimSpace(strings.TrimPrefix(line, "Homepage: "))
+		} else if strings.HasPrefix(line, "Maintainer: ") {
+			maintainer = strings.TrimSpace(strings.TrimPrefix(line, "Maintainer: "))
 		}
 
 		if !scanner.Scan() {
@@ -124,7 +278,13 @@ func (a debianPkgAnalyzer) parseDpkgPkg(scanner *bufio.Scanner) (binPkg *analyze
 		if err := versionfmt.Valid(clairDpkg.ParserName, version); err != nil {
 			log.Printf("Invalid Version Found : OS %s, Package %s, Version %s", "debian", name, version)
 		} else {
-			binPkg = &analyzer.Package{Name: name, Version: version, Type: analyzer.TypeBinary}
+			binPkg = &analyzer.Package{
+				Name:       name,
+				Version:    version,
+				Type:       analyzer.TypeBinary,
+				Homepage:   homepage,
+				Maintainer: maintainer,
+			}
 		}
 	}
 
@@ -152,6 +312,78 @@ func (a debianPkgAnalyzer) parseDpkgPkg(scanner *bufio.Scanner) (binPkg *analyze
 	return binPkg, srcPkg
 }
 
+// AnalyzeSource maps binary packages to the source packages given in "Source:" fields.
+// A binary package without "Source:" is built from the source package with the same name and version.
+func (a debianPkgAnalyzer) AnalyzeSource(fileMap extractor.FileMap) (map[string]analyzer.SrcPackage, error) {
+	file, ok := a.statusDatabase(fileMap)
+	if !ok {
+		return nil, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+	return a.parseSourceMap(bufio.NewScanner(bytes.NewBuffer(file))), nil
+}
+
+func (a debianPkgAnalyzer) parseSourceMap(scanner *bufio.Scanner) map[string]analyzer.SrcPackage {
+	type srcKey struct{ name, version string }
+	var keys []srcKey
+	srcs := map[srcKey]*analyzer.SrcPackage{}
+	binToSrc := map[string]srcKey{}
+
+	var name, version, sourceName, sourceVersion string
+	flush := func() {
+		if name != "" && version != "" {
+			if sourceName == "" {
+				sourceName = name
+			}
+			if sourceVersion == "" {
+				sourceVersion = version
+			}
+			key := srcKey{sourceName, sourceVersion}
+			src, ok := srcs[key]
+			if !ok {
+				src = &analyzer.SrcPackage{Name: sourceName, Version: sourceVersion}
+				srcs[key] = src
+				keys = append(keys, key)
+			}
+			src.BinaryNames = append(src.BinaryNames, name)
+			binToSrc[name] = key
+		}
+		name, version, sourceName, sourceVersion = "", "", "", ""
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "Package: "):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "Package: "))
+		case strings.HasPrefix(line, "Version: "):
+			version = strings.TrimPrefix(line, "Version: ")
+		case strings.HasPrefix(line, "Source: "):
+			srcCapture := dpkgSrcCaptureRegexp.FindStringSubmatch(line)
+			for i, n := range srcCapture {
+				switch dpkgSrcCaptureRegexpNames[i] {
+				case "name":
+					sourceName = strings.TrimSpace(n)
+				case "version":
+					sourceVersion = strings.TrimSpace(n)
+				}
+			}
+		}
+	}
+	flush()
+
+	srcMap := map[string]analyzer.SrcPackage{}
+	for binName, key := range binToSrc {
+		srcMap[binName] = *srcs[key]
+	}
+	return srcMap
+}
+
+func (a debianPkgAnalyzer) RequiredDirs() []string {
+	return []string{statusDir}
+}
+
 func (a debianPkgAnalyzer) RequiredFiles() []string {
-	return []string{"var/lib/dpkg/status"}
+	return []string{statusFile, logFile, copyrightFile, conffilesFile}
 }
@@ -2,13 +2,17 @@ package dpkg
 
 import (
 	"bufio"
+	"io/ioutil"
 	"os"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/d4l3k/messagediff"
 
 	"github.com/knqyf263/fanal/analyzer"
+	atesting "github.com/knqyf263/fanal/analyzer/testing"
+	"github.com/knqyf263/fanal/extractor"
 )
 
 func TestParseApkInfo(t *testing.T) {
@@ -20,164 +24,164 @@ func TestParseApkInfo(t *testing.T) {
 			path: "./testdata/dpkg",
 			pkgs: []analyzer.Package{
 				{Name: "acl", Version: "2.2.52-3build1", Type: "source"},
-				{Name: "adduser", Version: "3.116ubuntu1", Type: "binary"},
+				{Name: "adduser", Version: "3.116ubuntu1", Type: "binary", Homepage: "http://alioth.debian.org/projects/adduser/", Maintainer: "Ubuntu Core Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "adduser", Version: "3.116ubuntu1", Type: "source"},
-				{Name: "apt", Version: "1.6.3ubuntu0.1", Type: "binary"},
+				{Name: "apt", Version: "1.6.3ubuntu0.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "apt", Version: "1.6.3ubuntu0.1", Type: "source"},
 				{Name: "attr", Version: "1:2.4.47-2build1", Type: "source"},
 				{Name: "audit", Version: "1:2.8.2-1ubuntu1", Type: "source"},
-				{Name: "base-files", Version: "10.1ubuntu2.2", Type: "binary"},
+				{Name: "base-files", Version: "10.1ubuntu2.2", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "base-files", Version: "10.1ubuntu2.2", Type: "source"},
-				{Name: "base-passwd", Version: "3.5.44", Type: "binary"},
+				{Name: "base-passwd", Version: "3.5.44", Type: "binary", Maintainer: "Colin Watson <cjwatson@debian.org>"},
 				{Name: "base-passwd", Version: "3.5.44", Type: "source"},
-				{Name: "bash", Version: "4.4.18-2ubuntu1", Type: "binary"},
+				{Name: "bash", Version: "4.4.18-2ubuntu1", Type: "binary", Homepage: "http://tiswww.case.edu/php/chet/bash/bashtop.html", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "bash", Version: "4.4.18-2ubuntu1", Type: "source"},
-				{Name: "bsdutils", Version: "1:2.31.1-0.4ubuntu3.1", Type: "binary"},
-				{Name: "bzip2", Version: "1.0.6-8.1", Type: "binary"},
+				{Name: "bsdutils", Version: "1:2.31.1-0.4ubuntu3.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "bzip2", Version: "1.0.6-8.1", Type: "binary", Homepage: "http://www.bzip.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "bzip2", Version: "1.0.6-8.1", Type: "source"},
 				{Name: "cdebconf", Version: "0.213ubuntu1", Type: "source"},
-				{Name: "coreutils", Version: "8.28-1ubuntu1", Type: "binary"},
+				{Name: "coreutils", Version: "8.28-1ubuntu1", Type: "binary", Homepage: "http://gnu.org/software/coreutils", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "coreutils", Version: "8.28-1ubuntu1", Type: "source"},
-				{Name: "dash", Version: "0.5.8-2.10", Type: "binary"},
+				{Name: "dash", Version: "0.5.8-2.10", Type: "binary", Homepage: "http://gondor.apana.org.au/~herbert/dash/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "dash", Version: "0.5.8-2.10", Type: "source"},
 				{Name: "db5.3", Version: "5.3.28-13.1ubuntu1", Type: "source"},
-				{Name: "debconf", Version: "1.5.66", Type: "binary"},
+				{Name: "debconf", Version: "1.5.66", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "debconf", Version: "1.5.66", Type: "source"},
-				{Name: "debianutils", Version: "4.8.4", Type: "binary"},
+				{Name: "debianutils", Version: "4.8.4", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "debianutils", Version: "4.8.4", Type: "source"},
-				{Name: "diffutils", Version: "1:3.6-1", Type: "binary"},
+				{Name: "diffutils", Version: "1:3.6-1", Type: "binary", Homepage: "http://www.gnu.org/software/diffutils/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "diffutils", Version: "1:3.6-1", Type: "source"},
-				{Name: "dpkg", Version: "1.19.0.5ubuntu2", Type: "binary"},
+				{Name: "dpkg", Version: "1.19.0.5ubuntu2", Type: "binary", Homepage: "https://wiki.debian.org/Teams/Dpkg", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "dpkg", Version: "1.19.0.5ubuntu2", Type: "source"},
-				{Name: "e2fsprogs", Version: "1.44.1-1", Type: "binary"},
+				{Name: "e2fsprogs", Version: "1.44.1-1", Type: "binary", Homepage: "http://e2fsprogs.sourceforge.net", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "e2fsprogs", Version: "1.44.1-1", Type: "source"},
-				{Name: "fdisk", Version: "2.31.1-0.4ubuntu3.1", Type: "binary"},
-				{Name: "findutils", Version: "4.6.0+git+20170828-2", Type: "binary"},
+				{Name: "fdisk", Version: "2.31.1-0.4ubuntu3.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "findutils", Version: "4.6.0+git+20170828-2", Type: "binary", Homepage: "https://savannah.gnu.org/projects/findutils/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "findutils", Version: "4.6.0+git+20170828-2", Type: "source"},
 				{Name: "gcc-8", Version: "8-20180414-1ubuntu2", Type: "source"},
-				{Name: "gcc-8-base", Version: "8-20180414-1ubuntu2", Type: "binary"},
+				{Name: "gcc-8-base", Version: "8-20180414-1ubuntu2", Type: "binary", Homepage: "http://gcc.gnu.org/", Maintainer: "Ubuntu Core developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "glibc", Version: "2.27-3ubuntu1", Type: "source"},
 				{Name: "gmp", Version: "2:6.1.2+dfsg-2", Type: "source"},
 				{Name: "gnupg2", Version: "2.2.4-1ubuntu1.1", Type: "source"},
 				{Name: "gnutls28", Version: "3.5.18-1ubuntu1", Type: "source"},
-				{Name: "gpgv", Version: "2.2.4-1ubuntu1.1", Type: "binary"},
-				{Name: "grep", Version: "3.1-2", Type: "binary"},
+				{Name: "gpgv", Version: "2.2.4-1ubuntu1.1", Type: "binary", Homepage: "https://www.gnupg.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "grep", Version: "3.1-2", Type: "binary", Homepage: "http://www.gnu.org/software/grep/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "grep", Version: "3.1-2", Type: "source"},
-				{Name: "gzip", Version: "1.6-5ubuntu1", Type: "binary"},
+				{Name: "gzip", Version: "1.6-5ubuntu1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "gzip", Version: "1.6-5ubuntu1", Type: "source"},
-				{Name: "hostname", Version: "3.20", Type: "binary"},
+				{Name: "hostname", Version: "3.20", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "hostname", Version: "3.20", Type: "source"},
-				{Name: "init-system-helpers", Version: "1.51", Type: "binary"},
+				{Name: "init-system-helpers", Version: "1.51", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "init-system-helpers", Version: "1.51", Type: "source"},
-				{Name: "libacl1", Version: "2.2.52-3build1", Type: "binary"},
-				{Name: "libapt-pkg5.0", Version: "1.6.3ubuntu0.1", Type: "binary"},
-				{Name: "libattr1", Version: "1:2.4.47-2build1", Type: "binary"},
-				{Name: "libaudit-common", Version: "1:2.8.2-1ubuntu1", Type: "binary"},
-				{Name: "libaudit1", Version: "1:2.8.2-1ubuntu1", Type: "binary"},
-				{Name: "libblkid1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary"},
-				{Name: "libbz2-1.0", Version: "1.0.6-8.1", Type: "binary"},
-				{Name: "libc-bin", Version: "2.27-3ubuntu1", Type: "binary"},
-				{Name: "libc6", Version: "2.27-3ubuntu1", Type: "binary"},
+				{Name: "libacl1", Version: "2.2.52-3build1", Type: "binary", Homepage: "http://savannah.nongnu.org/projects/acl/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libapt-pkg5.0", Version: "1.6.3ubuntu0.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libattr1", Version: "1:2.4.47-2build1", Type: "binary", Homepage: "http://savannah.nongnu.org/projects/attr/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libaudit-common", Version: "1:2.8.2-1ubuntu1", Type: "binary", Homepage: "https://people.redhat.com/sgrubb/audit/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libaudit1", Version: "1:2.8.2-1ubuntu1", Type: "binary", Homepage: "https://people.redhat.com/sgrubb/audit/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libblkid1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libbz2-1.0", Version: "1.0.6-8.1", Type: "binary", Homepage: "http://www.bzip.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libc-bin", Version: "2.27-3ubuntu1", Type: "binary", Homepage: "https://www.gnu.org/software/libc/libc.html", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libc6", Version: "2.27-3ubuntu1", Type: "binary", Homepage: "https://www.gnu.org/software/libc/libc.html", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libcap-ng", Version: "0.7.7-3.1", Type: "source"},
-				{Name: "libcap-ng0", Version: "0.7.7-3.1", Type: "binary"},
-				{Name: "libcom-err2", Version: "1.44.1-1", Type: "binary"},
-				{Name: "libdb5.3", Version: "5.3.28-13.1ubuntu1", Type: "binary"},
-				{Name: "libdebconfclient0", Version: "0.213ubuntu1", Type: "binary"},
-				{Name: "libext2fs2", Version: "1.44.1-1", Type: "binary"},
-				{Name: "libfdisk1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary"},
+				{Name: "libcap-ng0", Version: "0.7.7-3.1", Type: "binary", Homepage: "http://people.redhat.com/sgrubb/libcap-ng", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libcom-err2", Version: "1.44.1-1", Type: "binary", Homepage: "http://e2fsprogs.sourceforge.net", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libdb5.3", Version: "5.3.28-13.1ubuntu1", Type: "binary", Homepage: "http://www.oracle.com/technetwork/database/database-technologies/berkeleydb/overview/index.html", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libdebconfclient0", Version: "0.213ubuntu1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libext2fs2", Version: "1.44.1-1", Type: "binary", Homepage: "http://e2fsprogs.sourceforge.net", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libfdisk1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libffi", Version: "3.2.1-8", Type: "source"},
-				{Name: "libffi6", Version: "3.2.1-8", Type: "binary"},
-				{Name: "libgcc1", Version: "1:8-20180414-1ubuntu2", Type: "binary"},
-				{Name: "libgcrypt20", Version: "1.8.1-4ubuntu1.1", Type: "binary"},
+				{Name: "libffi6", Version: "3.2.1-8", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libgcc1", Version: "1:8-20180414-1ubuntu2", Type: "binary", Homepage: "http://gcc.gnu.org/", Maintainer: "Ubuntu Core developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libgcrypt20", Version: "1.8.1-4ubuntu1.1", Type: "binary", Homepage: "http://directory.fsf.org/project/libgcrypt/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libgcrypt20", Version: "1.8.1-4ubuntu1.1", Type: "source"},
-				{Name: "libgmp10", Version: "2:6.1.2+dfsg-2", Type: "binary"},
-				{Name: "libgnutls30", Version: "3.5.18-1ubuntu1", Type: "binary"},
+				{Name: "libgmp10", Version: "2:6.1.2+dfsg-2", Type: "binary", Homepage: "http://gmplib.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libgnutls30", Version: "3.5.18-1ubuntu1", Type: "binary", Homepage: "http://www.gnutls.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libgpg-error", Version: "1.27-6", Type: "source"},
-				{Name: "libgpg-error0", Version: "1.27-6", Type: "binary"},
-				{Name: "libhogweed4", Version: "3.4-1", Type: "binary"},
+				{Name: "libgpg-error0", Version: "1.27-6", Type: "binary", Homepage: "https://www.gnupg.org/related_software/libgpg-error/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libhogweed4", Version: "3.4-1", Type: "binary", Homepage: "http://www.lysator.liu.se/~nisse/nettle/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libidn2", Version: "2.0.4-1.1build2", Type: "source"},
-				{Name: "libidn2-0", Version: "2.0.4-1.1build2", Type: "binary"},
-				{Name: "liblz4-1", Version: "0.0~r131-2ubuntu3", Type: "binary"},
-				{Name: "liblzma5", Version: "5.2.2-1.3", Type: "binary"},
-				{Name: "libmount1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary"},
-				{Name: "libncurses5", Version: "6.1-1ubuntu1.18.04", Type: "binary"},
-				{Name: "libncursesw5", Version: "6.1-1ubuntu1.18.04", Type: "binary"},
-				{Name: "libnettle6", Version: "3.4-1", Type: "binary"},
-				{Name: "libp11-kit0", Version: "0.23.9-2", Type: "binary"},
-				{Name: "libpam-modules", Version: "1.1.8-3.6ubuntu2", Type: "binary"},
-				{Name: "libpam-modules-bin", Version: "1.1.8-3.6ubuntu2", Type: "binary"},
-				{Name: "libpam-runtime", Version: "1.1.8-3.6ubuntu2", Type: "binary"},
-				{Name: "libpam0g", Version: "1.1.8-3.6ubuntu2", Type: "binary"},
-				{Name: "libpcre3", Version: "2:8.39-9", Type: "binary"},
-				{Name: "libprocps6", Version: "2:3.3.12-3ubuntu1.1", Type: "binary"},
+				{Name: "libidn2-0", Version: "2.0.4-1.1build2", Type: "binary", Homepage: "https://www.gnu.org/software/libidn/#libidn2", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "liblz4-1", Version: "0.0~r131-2ubuntu3", Type: "binary", Homepage: "https://github.com/Cyan4973/lz4", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "liblzma5", Version: "5.2.2-1.3", Type: "binary", Homepage: "http://tukaani.org/xz/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libmount1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libncurses5", Version: "6.1-1ubuntu1.18.04", Type: "binary", Homepage: "https://invisible-island.net/ncurses/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libncursesw5", Version: "6.1-1ubuntu1.18.04", Type: "binary", Homepage: "https://invisible-island.net/ncurses/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libnettle6", Version: "3.4-1", Type: "binary", Homepage: "http://www.lysator.liu.se/~nisse/nettle/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libp11-kit0", Version: "0.23.9-2", Type: "binary", Homepage: "http://p11-glue.freedesktop.org/p11-kit.html", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libpam-modules", Version: "1.1.8-3.6ubuntu2", Type: "binary", Homepage: "http://www.linux-pam.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libpam-modules-bin", Version: "1.1.8-3.6ubuntu2", Type: "binary", Homepage: "http://www.linux-pam.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libpam-runtime", Version: "1.1.8-3.6ubuntu2", Type: "binary", Homepage: "http://www.linux-pam.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libpam0g", Version: "1.1.8-3.6ubuntu2", Type: "binary", Homepage: "http://www.linux-pam.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libpcre3", Version: "2:8.39-9", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libprocps6", Version: "2:3.3.12-3ubuntu1.1", Type: "binary", Homepage: "https://gitlab.com/procps-ng/procps", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libseccomp", Version: "2.3.1-2.1ubuntu4", Type: "source"},
-				{Name: "libseccomp2", Version: "2.3.1-2.1ubuntu4", Type: "binary"},
+				{Name: "libseccomp2", Version: "2.3.1-2.1ubuntu4", Type: "binary", Homepage: "https://github.com/seccomp/libseccomp", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libselinux", Version: "2.7-2build2", Type: "source"},
-				{Name: "libselinux1", Version: "2.7-2build2", Type: "binary"},
+				{Name: "libselinux1", Version: "2.7-2build2", Type: "binary", Homepage: "http://userspace.selinuxproject.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libsemanage", Version: "2.7-2build2", Type: "source"},
-				{Name: "libsemanage-common", Version: "2.7-2build2", Type: "binary"},
-				{Name: "libsemanage1", Version: "2.7-2build2", Type: "binary"},
+				{Name: "libsemanage-common", Version: "2.7-2build2", Type: "binary", Homepage: "http://userspace.selinuxproject.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libsemanage1", Version: "2.7-2build2", Type: "binary", Homepage: "http://userspace.selinuxproject.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libsepol", Version: "2.7-1", Type: "source"},
-				{Name: "libsepol1", Version: "2.7-1", Type: "binary"},
-				{Name: "libsmartcols1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary"},
-				{Name: "libss2", Version: "1.44.1-1", Type: "binary"},
-				{Name: "libstdc++6", Version: "8-20180414-1ubuntu2", Type: "binary"},
-				{Name: "libsystemd0", Version: "237-3ubuntu10.3", Type: "binary"},
-				{Name: "libtasn1-6", Version: "4.13-2", Type: "binary"},
+				{Name: "libsepol1", Version: "2.7-1", Type: "binary", Homepage: "http://userspace.selinuxproject.org/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libsmartcols1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libss2", Version: "1.44.1-1", Type: "binary", Homepage: "http://e2fsprogs.sourceforge.net", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libstdc++6", Version: "8-20180414-1ubuntu2", Type: "binary", Homepage: "http://gcc.gnu.org/", Maintainer: "Ubuntu Core developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libsystemd0", Version: "237-3ubuntu10.3", Type: "binary", Homepage: "https://www.freedesktop.org/wiki/Software/systemd", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libtasn1-6", Version: "4.13-2", Type: "binary", Homepage: "http://www.gnu.org/software/libtasn1/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libtasn1-6", Version: "4.13-2", Type: "source"},
-				{Name: "libtinfo5", Version: "6.1-1ubuntu1.18.04", Type: "binary"},
-				{Name: "libudev1", Version: "237-3ubuntu10.3", Type: "binary"},
+				{Name: "libtinfo5", Version: "6.1-1ubuntu1.18.04", Type: "binary", Homepage: "https://invisible-island.net/ncurses/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libudev1", Version: "237-3ubuntu10.3", Type: "binary", Homepage: "https://www.freedesktop.org/wiki/Software/systemd", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libunistring", Version: "0.9.9-0ubuntu1", Type: "source"},
-				{Name: "libunistring2", Version: "0.9.9-0ubuntu1", Type: "binary"},
-				{Name: "libuuid1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary"},
+				{Name: "libunistring2", Version: "0.9.9-0ubuntu1", Type: "binary", Homepage: "http://www.gnu.org/software/libunistring/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libuuid1", Version: "2.31.1-0.4ubuntu3.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "libzstd", Version: "1.3.3+dfsg-2ubuntu1", Type: "source"},
-				{Name: "libzstd1", Version: "1.3.3+dfsg-2ubuntu1", Type: "binary"},
-				{Name: "login", Version: "1:4.5-1ubuntu1", Type: "binary"},
+				{Name: "libzstd1", Version: "1.3.3+dfsg-2ubuntu1", Type: "binary", Homepage: "https://github.com/facebook/zstd", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "login", Version: "1:4.5-1ubuntu1", Type: "binary", Homepage: "https://github.com/shadow-maint/shadow", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "lsb", Version: "9.20170808ubuntu1", Type: "source"},
-				{Name: "lsb-base", Version: "9.20170808ubuntu1", Type: "binary"},
+				{Name: "lsb-base", Version: "9.20170808ubuntu1", Type: "binary", Homepage: "https://wiki.linuxfoundation.org/lsb/start", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "lz4", Version: "0.0~r131-2ubuntu3", Type: "source"},
-				{Name: "mawk", Version: "1.3.3-17ubuntu3", Type: "binary"},
+				{Name: "mawk", Version: "1.3.3-17ubuntu3", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "mawk", Version: "1.3.3-17ubuntu3", Type: "source"},
-				{Name: "mount", Version: "2.31.1-0.4ubuntu3.1", Type: "binary"},
+				{Name: "mount", Version: "2.31.1-0.4ubuntu3.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "ncurses", Version: "6.1-1ubuntu1.18.04", Type: "source"},
-				{Name: "ncurses-base", Version: "6.1-1ubuntu1.18.04", Type: "binary"},
-				{Name: "ncurses-bin", Version: "6.1-1ubuntu1.18.04", Type: "binary"},
+				{Name: "ncurses-base", Version: "6.1-1ubuntu1.18.04", Type: "binary", Homepage: "https://invisible-island.net/ncurses/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "ncurses-bin", Version: "6.1-1ubuntu1.18.04", Type: "binary", Homepage: "https://invisible-island.net/ncurses/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "nettle", Version: "3.4-1", Type: "source"},
 				{Name: "p11-kit", Version: "0.23.9-2", Type: "source"},
 				{Name: "pam", Version: "1.1.8-3.6ubuntu2", Type: "source"},
-				{Name: "passwd", Version: "1:4.5-1ubuntu1", Type: "binary"},
+				{Name: "passwd", Version: "1:4.5-1ubuntu1", Type: "binary", Homepage: "https://github.com/shadow-maint/shadow", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "pcre3", Version: "2:8.39-9", Type: "source"},
 				{Name: "perl", Version: "5.26.1-6ubuntu0.2", Type: "source"},
-				{Name: "perl-base", Version: "5.26.1-6ubuntu0.2", Type: "binary"},
-				{Name: "procps", Version: "2:3.3.12-3ubuntu1.1", Type: "binary"},
+				{Name: "perl-base", Version: "5.26.1-6ubuntu0.2", Type: "binary", Homepage: "http://dev.perl.org/perl5/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "procps", Version: "2:3.3.12-3ubuntu1.1", Type: "binary", Homepage: "https://gitlab.com/procps-ng/procps", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "procps", Version: "2:3.3.12-3ubuntu1.1", Type: "source"},
-				{Name: "sed", Version: "4.4-2", Type: "binary"},
+				{Name: "sed", Version: "4.4-2", Type: "binary", Homepage: "https://www.gnu.org/software/sed/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "sed", Version: "4.4-2", Type: "source"},
-				{Name: "sensible-utils", Version: "0.0.12", Type: "binary"},
+				{Name: "sensible-utils", Version: "0.0.12", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "sensible-utils", Version: "0.0.12", Type: "source"},
 				{Name: "shadow", Version: "1:4.5-1ubuntu1", Type: "source"},
 				{Name: "systemd", Version: "237-3ubuntu10.3", Type: "source"},
 				{Name: "sysvinit", Version: "2.88dsf-59.10ubuntu1", Type: "source"},
-				{Name: "sysvinit-utils", Version: "2.88dsf-59.10ubuntu1", Type: "binary"},
-				{Name: "tar", Version: "1.29b-2", Type: "binary"},
+				{Name: "sysvinit-utils", Version: "2.88dsf-59.10ubuntu1", Type: "binary", Homepage: "http://savannah.nongnu.org/projects/sysvinit", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "tar", Version: "1.29b-2", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "tar", Version: "1.29b-2", Type: "source"},
-				{Name: "ubuntu-keyring", Version: "2018.02.28", Type: "binary"},
+				{Name: "ubuntu-keyring", Version: "2018.02.28", Type: "binary", Maintainer: "Dimitri John Ledkov <dimitri.ledkov@canonical.com>"},
 				{Name: "ubuntu-keyring", Version: "2018.02.28", Type: "source"},
-				{Name: "util-linux", Version: "2.31.1-0.4ubuntu3.1", Type: "binary"},
+				{Name: "util-linux", Version: "2.31.1-0.4ubuntu3.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "util-linux", Version: "2.31.1-0.4ubuntu3.1", Type: "source"},
 				{Name: "xz-utils", Version: "5.2.2-1.3", Type: "source"},
 				{Name: "zlib", Version: "1:1.2.11.dfsg-0ubuntu2", Type: "source"},
-				{Name: "zlib1g", Version: "1:1.2.11.dfsg-0ubuntu2", Type: "binary"},
+				{Name: "zlib1g", Version: "1:1.2.11.dfsg-0ubuntu2", Type: "binary", Homepage: "http://zlib.net/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 			},
 		},
 		"Corrupsed": {
 			path: "./testdata/corrupsed",
 			pkgs: []analyzer.Package{
 				{Name: "gcc-5", Version: "5.1.1-12ubuntu1", Type: "source"},
-				{Name: "libgcc1", Version: "1:5.1.1-12ubuntu1", Type: "binary"},
-				{Name: "libpam-modules-bin", Version: "1.1.8-3.1ubuntu3", Type: "binary"},
-				{Name: "libpam-runtime", Version: "1.1.8-3.1ubuntu3", Type: "binary"},
-				{Name: "makedev", Version: "2.3.1-93ubuntu1", Type: "binary"},
+				{Name: "libgcc1", Version: "1:5.1.1-12ubuntu1", Type: "binary", Homepage: "http://gcc.gnu.org/", Maintainer: "Ubuntu Core developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libpam-modules-bin", Version: "1.1.8-3.1ubuntu3", Type: "binary", Homepage: "http://pam.sourceforge.net/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "libpam-runtime", Version: "1.1.8-3.1ubuntu3", Type: "binary", Homepage: "http://pam.sourceforge.net/", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
+				{Name: "makedev", Version: "2.3.1-93ubuntu1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "makedev", Version: "2.3.1-93ubuntu1", Type: "source"},
 				{Name: "pam", Version: "1.1.8-3.1ubuntu3", Type: "source"},
 			},
@@ -185,7 +189,7 @@ func TestParseApkInfo(t *testing.T) {
 		"OnlyApt": {
 			path: "./testdata/dpkg_apt",
 			pkgs: []analyzer.Package{
-				{Name: "apt", Version: "1.6.3ubuntu0.1", Type: "binary"},
+				{Name: "apt", Version: "1.6.3ubuntu0.1", Type: "binary", Maintainer: "Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>"},
 				{Name: "apt", Version: "1.6.3ubuntu0.1", Type: "source"},
 			},
 		},
@@ -217,3 +221,240 @@ func sortPkgByName(pkgs []analyzer.Package) []analyzer.Package {
 	})
 	return pkgs
 }
+
+func TestParseSourceMap(t *testing.T) {
+	f, err := os.Open("./testdata/dpkg_source")
+	if err != nil {
+		t.Fatalf("can't open file: %v", err)
+	}
+	defer f.Close()
+
+	utilLinux := analyzer.SrcPackage{
+		Name:        "util-linux",
+		Version:     "2.31.1-0.4ubuntu3.1",
+		BinaryNames: []string{"bsdutils", "fdisk"},
+	}
+	expected := map[string]analyzer.SrcPackage{
+		"bsdutils": utilLinux,
+		"fdisk":    utilLinux,
+		"bash":     {Name: "bash", Version: "4.4.18-2ubuntu1", BinaryNames: []string{"bash"}},
+	}
+
+	a := debianPkgAnalyzer{}
+	actual := a.parseSourceMap(bufio.NewScanner(f))
+	if diff, equal := messagediff.PrettyDiff(expected, actual); !equal {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestAnalyzeInstalledAt(t *testing.T) {
+	status, err := ioutil.ReadFile("./testdata/dpkg_source")
+	if err != nil {
+		t.Fatalf("can't open file: %v", err)
+	}
+	log, err := ioutil.ReadFile("./testdata/dpkg.log")
+	if err != nil {
+		t.Fatalf("can't open file: %v", err)
+	}
+
+	bashInstalledAt := time.Date(2019, 6, 1, 10, 0, 2, 0, time.UTC)
+	fdiskInstalledAt := time.Date(2019, 6, 1, 10, 0, 3, 0, time.UTC)
+	var tests = map[string]struct {
+		fileMap     extractor.FileMap
+		installedAt map[string]*time.Time
+	}{
+		"WithLog": {
+			fileMap: extractor.MapFileMap{statusFile: status, logFile: log},
+			installedAt: map[string]*time.Time{
+				"bash":     &bashInstalledAt,
+				"bsdutils": nil,
+				"fdisk":    &fdiskInstalledAt,
+			},
+		},
+		"WithoutLog": {
+			fileMap: extractor.MapFileMap{statusFile: status},
+			installedAt: map[string]*time.Time{
+				"bash":     nil,
+				"bsdutils": nil,
+				"fdisk":    nil,
+			},
+		},
+	}
+	a := debianPkgAnalyzer{}
+	for testName, v := range tests {
+		pkgs, err := a.Analyze(v.fileMap)
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		actual := map[string]*time.Time{}
+		for _, pkg := range pkgs {
+			if pkg.Type == analyzer.TypeBinary {
+				actual[pkg.Name] = pkg.InstalledAt
+			}
+		}
+		if diff, equal := messagediff.PrettyDiff(v.installedAt, actual); !equal {
+			t.Errorf("[%s]\n diff: %v", testName, diff)
+		}
+	}
+}
+
+// Distroless images have a file per package in var/lib/dpkg/status.d instead of var/lib/dpkg/status
+func TestAnalyzeStatusDir(t *testing.T) {
+	fileMap := extractor.MapFileMap{}
+	for _, name := range []string{"libc6", "tzdata", "tzdata.md5sums"} {
+		b, err := ioutil.ReadFile("./testdata/status.d/" + name)
+		if err != nil {
+			t.Fatalf("can't open file: %v", err)
+		}
+		fileMap[statusDir+"/"+name] = b
+	}
+
+	a := debianPkgAnalyzer{}
+	pkgs, err := a.Analyze(fileMap)
+	if err != nil {
+		t.Fatalf("catch the error : %v", err)
+	}
+	actual := map[string]string{}
+	for _, pkg := range pkgs {
+		actual[pkg.Type+"/"+pkg.Name] = pkg.Version
+	}
+	expected := map[string]string{
+		"binary/libc6":  "2.31-13+deb11u5",
+		"binary/tzdata": "2021a-1+deb11u10",
+		"source/glibc":  "2.31-13+deb11u5",
+		"source/tzdata": "2021a-1+deb11u10",
+	}
+	if diff, equal := messagediff.PrettyDiff(expected, actual); !equal {
+		t.Errorf("diff: %v", diff)
+	}
+
+	srcMap, err := a.AnalyzeSource(fileMap)
+	if err != nil {
+		t.Fatalf("catch the error : %v", err)
+	}
+	if src := srcMap["libc6"]; src.Name != "glibc" {
+		t.Errorf("glibc is expected for libc6: %v", srcMap)
+	}
+}
+
+func TestAnalyzeLicense(t *testing.T) {
+	status, err := ioutil.ReadFile("./testdata/dpkg_source")
+	if err != nil {
+		t.Fatalf("can't open file: %v", err)
+	}
+	copyright, err := ioutil.ReadFile("./testdata/copyright")
+	if err != nil {
+		t.Fatalf("can't open file: %v", err)
+	}
+
+	fileMap := extractor.MapFileMap{
+		statusFile:                      status,
+		"usr/share/doc/bash/copyright":  copyright,
+		"usr/share/doc/fdisk/copyright": []byte("This is not a machine-readable copyright file.\n"),
+	}
+	expected := map[string]string{
+		"bash":     "GPL-3+",
+		"bsdutils": "",
+		"fdisk":    "",
+	}
+
+	a := debianPkgAnalyzer{}
+	pkgs, err := a.Analyze(fileMap)
+	if err != nil {
+		t.Errorf("catch the error : %v", err)
+	}
+	actual := map[string]string{}
+	for _, pkg := range pkgs {
+		if pkg.Type == analyzer.TypeBinary {
+			actual[pkg.Name] = pkg.License
+		}
+	}
+	if diff, equal := messagediff.PrettyDiff(expected, actual); !equal {
+		t.Errorf("diff: %v", diff)
+	}
+}
+
+func TestAnalyzeConfigFiles(t *testing.T) {
+	status, err := ioutil.ReadFile("./testdata/dpkg_source")
+	if err != nil {
+		t.Fatalf("can't open file: %v", err)
+	}
+	conffiles, err := ioutil.ReadFile("./testdata/bash.conffiles")
+	if err != nil {
+		t.Fatalf("can't open file: %v", err)
+	}
+
+	fileMap := extractor.MapFileMap{
+		statusFile:                                  status,
+		"var/lib/dpkg/info/bash.conffiles":          conffiles,
+		"var/lib/dpkg/info/fdisk:amd64.conffiles":   []byte("remove-on-upgrade /etc/fdisk.conf\n\n"),
+		"var/lib/dpkg/info/bsdutils.list":           []byte("/usr/bin/logger\n"),
+		"var/lib/dpkg/info/not-installed.conffiles": []byte("/etc/not-installed.conf\n"),
+	}
+	expected := map[string][]string{
+		"bash":     {"/etc/bash.bashrc", "/etc/skel/.bash_logout", "/etc/skel/.bashrc", "/etc/skel/.profile"},
+		"bsdutils": nil,
+		"fdisk":    {"/etc/fdisk.conf"},
+	}
+
+	a := debianPkgAnalyzer{}
+	pkgs, err := a.Analyze(fileMap)
+	if err != nil {
+		t.Errorf("catch the error : %v", err)
+	}
+	actual := map[string][]string{}
+	for _, pkg := range pkgs {
+		if pkg.Type == analyzer.TypeBinary {
+			actual[pkg.Name] = pkg.ConfigFiles
+		}
+	}
+	if diff, equal := messagediff.PrettyDiff(expected, actual); !equal {
+		t.Errorf("diff: %v", diff)
+	}
+}
+
+func TestFileListProvider(t *testing.T) {
+	fileMap := extractor.MapFileMap{
+		"var/lib/dpkg/info/libc6:amd64.list": []byte("/.\n/lib\n/lib/x86_64-linux-gnu\n/lib/x86_64-linux-gnu/libc-2.28.so\n/lib/x86_64-linux-gnu/libc.so.6\n"),
+		"var/lib/dpkg/info/fonts.list":       []byte("/usr/share/fonts/Noto Sans.ttf\n"),
+		"var/lib/dpkg/info/bash.conffiles":   []byte("/etc/bash.bashrc\n"),
+	}
+	var tests = map[string]struct {
+		pkg      analyzer.Package
+		expected []string
+	}{
+		"MultiArch": {
+			pkg:      analyzer.Package{Name: "libc6", Version: "2.28-10"},
+			expected: []string{"/.", "/lib", "/lib/x86_64-linux-gnu", "/lib/x86_64-linux-gnu/libc-2.28.so", "/lib/x86_64-linux-gnu/libc.so.6"},
+		},
+		"Space": {
+			pkg:      analyzer.Package{Name: "fonts"},
+			expected: []string{"/usr/share/fonts/Noto Sans.ttf"},
+		},
+		"NoList": {
+			pkg: analyzer.Package{Name: "bash"},
+		},
+	}
+	p := NewFileListProvider(fileMap)
+	for testName, v := range tests {
+		actual := p.Files(v.pkg)
+		if diff, equal := messagediff.PrettyDiff(v.expected, actual); !equal {
+			t.Errorf("[%s]\n diff: %v", testName, diff)
+		}
+	}
+}
+
+func TestFileListRequiredFiles(t *testing.T) {
+	required := debianPkgAnalyzer{}.RequiredFiles()
+	for _, pattern := range FileListRequiredFiles() {
+		for _, r := range required {
+			if r == pattern {
+				t.Errorf("%s must not be required by the analyzer", pattern)
+			}
+		}
+	}
+}
+
+func BenchmarkDebianPkg(b *testing.B) {
+	atesting.RunAnalyzerBenchmark(b, debianPkgAnalyzer{}, map[string]string{"var/lib/dpkg/status": "testdata/dpkg"})
+}
@@ -0,0 +1,134 @@
+package flatpak
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+const appDir = "var/lib/flatpak/app/"
+
+// e.g. <release version="44.0" date="2023-03-17"/>
+var releaseRegexp = regexp.MustCompile(`<release[^>]*\sversion="([^"]+)"`)
+
+func init() {
+	analyzer.RegisterPkgAnalyzer(&flatpakPkgAnalyzer{})
+}
+
+type flatpakPkgAnalyzer struct{}
+
+type ref struct {
+	id, arch, branch string
+}
+
+// Analyze returns the applications installed under var/lib/flatpak/app/<id>/<arch>/<branch>/<commit>/.
+// The version is the latest release in the AppStream metadata if any, otherwise the branch.
+func (a flatpakPkgAnalyzer) Analyze(fileMap extractor.FileMap) ([]analyzer.Package, error) {
+	apps := newInstalledApps()
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		apps.add(filename, content)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(apps.refs) == 0 {
+		return nil, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+	return apps.packages(), nil
+}
+
+// installedApps collects the installed refs and their versions from the files, which come in any order.
+type installedApps struct {
+	refs      []ref
+	installed map[ref]bool
+	versions  map[ref]string
+}
+
+func newInstalledApps() *installedApps {
+	return &installedApps{installed: map[ref]bool{}, versions: map[ref]string{}}
+}
+
+// add reads the metadata or the AppStream metadata of a ref. The other files are ignored.
+func (s *installedApps) add(filename string, content []byte) {
+	if !strings.HasPrefix(filename, appDir) {
+		return
+	}
+	// <id>/<arch>/<branch>/<commit>/...
+	dirs := strings.Split(strings.TrimPrefix(filename, appDir), "/")
+	if len(dirs) < 5 {
+		return
+	}
+	r := ref{id: dirs[0], arch: dirs[1], branch: dirs[2]}
+	rest := strings.Join(dirs[4:], "/")
+
+	switch {
+	case rest == "metadata":
+		if name := parseMetadata(content); name != "" && name != r.id {
+			return
+		}
+		if !s.installed[r] {
+			s.refs = append(s.refs, r)
+			s.installed[r] = true
+		}
+	case strings.HasPrefix(rest, "files/share/metainfo/") || strings.HasPrefix(rest, "files/share/appdata/"):
+		if m := releaseRegexp.FindSubmatch(content); m != nil && s.versions[r] == "" {
+			s.versions[r] = string(m[1])
+		}
+	}
+}
+
+// packages returns the packages of the installed refs sorted by the refs.
+func (s *installedApps) packages() []analyzer.Package {
+	refs := s.refs
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].id+"/"+refs[i].arch+"/"+refs[i].branch < refs[j].id+"/"+refs[j].arch+"/"+refs[j].branch
+	})
+
+	var pkgs []analyzer.Package
+	for _, r := range refs {
+		version := s.versions[r]
+		if version == "" {
+			version = r.branch
+		}
+		pkgs = append(pkgs, analyzer.Package{
+			Name:    r.id,
+			Version: version,
+			Arch:    r.arch,
+			Type:    analyzer.TypeFlatpak,
+		})
+	}
+	return pkgs
+}
+
+// parseMetadata returns the name in the [Application] group.
+func parseMetadata(content []byte) string {
+	scanner := bufio.NewScanner(bytes.NewBuffer(content))
+	inApplication := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inApplication = line == "[Application]"
+			continue
+		}
+		if inApplication && strings.HasPrefix(line, "name=") {
+			return strings.TrimPrefix(line, "name=")
+		}
+	}
+	return ""
+}
+
+func (a flatpakPkgAnalyzer) RequiredFiles() []string {
+	return []string{
+		appDir + "*/*/*/*/metadata",
+		appDir + "*/*/*/*/files/share/metainfo/*.xml",
+		appDir + "*/*/*/*/files/share/appdata/*.xml",
+	}
+}
@@ -0,0 +1,64 @@
+package flatpak
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestAnalyze(t *testing.T) {
+	fileMap := extractor.MapFileMap{}
+	for path, testdata := range map[string]string{
+		"var/lib/flatpak/app/org.gnome.Calculator/x86_64/stable/4f3b2a1c/metadata":                                               "./testdata/metadata",
+		"var/lib/flatpak/app/org.gnome.Calculator/x86_64/stable/4f3b2a1c/files/share/metainfo/org.gnome.Calculator.metainfo.xml": "./testdata/org.gnome.Calculator.metainfo.xml",
+		"var/lib/flatpak/app/com.example.Tool/x86_64/master/9a8b7c6d/metadata":                                                   "./testdata/metadata_nometainfo",
+	} {
+		b, err := ioutil.ReadFile(testdata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileMap[path] = b
+	}
+
+	expected := []analyzer.Package{
+		{Name: "com.example.Tool", Version: "master", Arch: "x86_64", Type: "flatpak"},
+		{Name: "org.gnome.Calculator", Version: "44.0", Arch: "x86_64", Type: "flatpak"},
+	}
+
+	a := flatpakPkgAnalyzer{}
+	pkgs, err := a.Analyze(fileMap)
+	if err != nil {
+		t.Errorf("catch the error : %v", err)
+	}
+	if !reflect.DeepEqual(expected, pkgs) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, pkgs)
+	}
+
+	if _, err := a.Analyze(extractor.MapFileMap{}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// The files of a ref are read in the order of the map, e.g. the AppStream metadata before the metadata
+func TestInstalledApps_MetainfoFirst(t *testing.T) {
+	metainfo, err := ioutil.ReadFile("./testdata/org.gnome.Calculator.metainfo.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := ioutil.ReadFile("./testdata/metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apps := newInstalledApps()
+	apps.add("var/lib/flatpak/app/org.gnome.Calculator/x86_64/stable/4f3b2a1c/files/share/metainfo/org.gnome.Calculator.metainfo.xml", metainfo)
+	apps.add("var/lib/flatpak/app/org.gnome.Calculator/x86_64/stable/4f3b2a1c/metadata", metadata)
+
+	expected := []analyzer.Package{{Name: "org.gnome.Calculator", Version: "44.0", Arch: "x86_64", Type: "flatpak"}}
+	if pkgs := apps.packages(); !reflect.DeepEqual(expected, pkgs) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, pkgs)
+	}
+}
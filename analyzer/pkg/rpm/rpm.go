@@ -22,7 +22,7 @@ func (a rpmPkgAnalyzer) Analyze(fileMap extractor.FileMap) (pkgs []analyzer.Pack
 	var parsedPkgs []analyzer.Package
 	detected := false
 	for _, filename := range a.RequiredFiles() {
-		file, ok := fileMap[filename]
+		file, ok := fileMap.Get(filename)
 		if !ok {
 			continue
 		}
@@ -31,7 +31,7 @@ func (a rpmPkgAnalyzer) Analyze(fileMap extractor.FileMap) (pkgs []analyzer.Pack
 		detected = true
 	}
 	if !detected {
-		return nil, xerrors.New("No package detected")
+		return nil, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
 	}
 	return pkgs, err
 }
@@ -2,13 +2,13 @@ package rpmcmd
 
 import (
 	"bufio"
-	"errors"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/xerrors"
 
@@ -18,6 +18,7 @@ import (
 
 func init() {
 	analyzer.RegisterPkgAnalyzer(&rpmCmdPkgAnalyzer{})
+	analyzer.RegisterSourceAnalyzer(&rpmCmdPkgAnalyzer{})
 }
 
 type rpmCmdPkgAnalyzer struct{}
@@ -26,7 +27,7 @@ func (a rpmCmdPkgAnalyzer) Analyze(fileMap extractor.FileMap) (pkgs []analyzer.P
 	var parsedPkgs []analyzer.Package
 	detected := false
 	for _, filename := range a.RequiredFiles() {
-		file, ok := fileMap[filename]
+		file, ok := fileMap.Get(filename)
 		if !ok {
 			continue
 		}
@@ -35,7 +36,7 @@ func (a rpmCmdPkgAnalyzer) Analyze(fileMap extractor.FileMap) (pkgs []analyzer.P
 		detected = true
 	}
 	if !detected {
-		return pkgs, errors.New("No package detected")
+		return pkgs, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
 	}
 	return pkgs, err
 }
@@ -70,37 +71,193 @@ func (a rpmCmdPkgAnalyzer) parsePkgInfo(packageBytes []byte) (pkgs []analyzer.Pa
 		}
 		pkgs = append(pkgs, pkg)
 	}
+
+	out, err = exec.Command("rpm", "--dbpath", tmpDir, "-qa", "--qf", scriptsQueryFormat).Output()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to query scripts: %w", err)
+	}
+	scripts := parseScripts(string(out))
+	for i, pkg := range pkgs {
+		pkgs[i].Scripts = scripts[pkg.Name+"-"+pkg.Version+"-"+pkg.Release]
+	}
+
+	out, err = exec.Command("rpm", "--dbpath", tmpDir, "-qa", "--qf", configFilesQueryFormat).Output()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to query config files: %w", err)
+	}
+	configFiles := parseConfigFiles(string(out))
+	for i, pkg := range pkgs {
+		pkgs[i].ConfigFiles = configFiles[pkg.Name+"-"+pkg.Version+"-"+pkg.Release]
+	}
 	return pkgs, nil
 }
 
+// The files of a package are iterated in the brackets, each followed by the unit separator.
+const configFilesQueryFormat = "%{NAME}-%{VERSION}-%{RELEASE}\x1f[%{FILEFLAGS}\t%{FILENAMES}\x1f]\x1e"
+
+// rpmFileConfig is RPMFILE_CONFIG in FILEFLAGS, i.e. %config in the spec file.
+const rpmFileConfig = 1 << 0
+
+// parseConfigFiles returns the config files keyed by NAME-VERSION-RELEASE.
+func parseConfigFiles(out string) map[string][]string {
+	configFiles := map[string][]string{}
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.Split(record, "\x1f")
+		if len(fields) < 2 {
+			continue
+		}
+		for _, file := range fields[1:] {
+			ss := strings.SplitN(file, "\t", 2)
+			if len(ss) != 2 {
+				continue
+			}
+			flags, err := strconv.ParseUint(ss[0], 10, 32)
+			if err != nil || flags&rpmFileConfig == 0 {
+				continue
+			}
+			configFiles[fields[0]] = append(configFiles[fields[0]], ss[1])
+		}
+	}
+	return configFiles
+}
+
+// FileListProvider returns FILENAMES of the packages in the RPM database.
+type FileListProvider struct {
+	files map[string][]string
+}
+
+// NewFileListProvider queries the files of all the packages at once, see analyzer.BuildSonameMap.
+func NewFileListProvider(fileMap extractor.FileMap) (FileListProvider, error) {
+	a := rpmCmdPkgAnalyzer{}
+	for _, filename := range a.RequiredFiles() {
+		file, ok := fileMap.Get(filename)
+		if !ok {
+			continue
+		}
+		tmpDir, err := ioutil.TempDir("", "rpm")
+		if err != nil {
+			return FileListProvider{}, err
+		}
+		defer os.RemoveAll(tmpDir)
+		if err = ioutil.WriteFile(filepath.Join(tmpDir, "Packages"), file, 0700); err != nil {
+			return FileListProvider{}, err
+		}
+
+		out, err := exec.Command("rpm", "--dbpath", tmpDir, "-qa", "--qf", fileListQueryFormat).Output()
+		if err != nil {
+			return FileListProvider{}, xerrors.Errorf("failed to query files: %w", err)
+		}
+		return FileListProvider{files: parseFileLists(string(out))}, nil
+	}
+	return FileListProvider{}, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
+}
+
+func (p FileListProvider) Files(pkg analyzer.Package) []string {
+	return p.files[pkg.Name+"-"+pkg.Version+"-"+pkg.Release]
+}
+
+const fileListQueryFormat = "%{NAME}-%{VERSION}-%{RELEASE}\x1f[%{FILENAMES}\x1f]\x1e"
+
+// parseFileLists returns the files keyed by NAME-VERSION-RELEASE.
+func parseFileLists(out string) map[string][]string {
+	files := map[string][]string{}
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.Split(record, "\x1f")
+		for _, file := range fields[1:] {
+			if file != "" {
+				files[fields[0]] = append(files[fields[0]], file)
+			}
+		}
+	}
+	return files
+}
+
+// Scripts contain newlines, so the fields and the packages are separated by the unit and record separators.
+const scriptsQueryFormat = "%{NAME}-%{VERSION}-%{RELEASE}\x1f%{PREIN}\x1f%{POSTIN}\x1f%{PREUN}\x1f%{POSTUN}\x1e"
+
+var scriptNames = []string{"prein", "postin", "preun", "postun"}
+
+// parseScripts returns the scripts keyed by NAME-VERSION-RELEASE.
+func parseScripts(out string) map[string]map[string]string {
+	scripts := map[string]map[string]string{}
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != len(scriptNames)+1 {
+			continue
+		}
+		for i, name := range scriptNames {
+			script := fields[i+1]
+			if script == "" || script == "(none)" {
+				continue
+			}
+			if scripts[fields[0]] == nil {
+				scripts[fields[0]] = map[string]string{}
+			}
+			scripts[fields[0]][name] = script
+		}
+	}
+	return scripts
+}
+
 func parseRPMOutput(line string) (pkg analyzer.Package, err error) {
-	fields := strings.Fields(line)
-	if len(fields) != 4 {
+	// Fields are separated by tabs because LICENSE, VENDOR and PACKAGER can contain spaces
+	fields := strings.Split(line, "\t")
+	if len(fields) < 4 {
 		return pkg, xerrors.Errorf("Failed to parse package line: %s", line)
 	}
+	for i := range fields {
+		if fields[i] == "(none)" {
+			fields[i] = ""
+		}
+	}
 
 	var epoch int
 	epochStr := fields[1]
-	if epochStr == "0" || epochStr == "(none)" {
-		epoch = 0
-	} else {
+	if epochStr != "" && epochStr != "0" {
 		epoch, err = strconv.Atoi(epochStr)
 		if err != nil {
 			return pkg, err
 		}
 	}
 
+	var installedAt *time.Time
+	if len(fields) >= 5 && fields[4] != "" {
+		sec, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return pkg, xerrors.Errorf("invalid install time: %s", line)
+		}
+		t := time.Unix(sec, 0).UTC()
+		installedAt = &t
+	}
+
+	// URL is stored verbatim
+	var homepage, license, maintainer string
+	if len(fields) >= 7 {
+		homepage, license = fields[5], fields[6]
+	}
+	// VENDOR is preferred over PACKAGER
+	if len(fields) >= 9 {
+		maintainer = fields[7]
+		if maintainer == "" {
+			maintainer = fields[8]
+		}
+	}
+
 	return analyzer.Package{
-		Name:    fields[0],
-		Epoch:   epoch,
-		Version: fields[2],
-		Release: fields[3],
+		Name:        fields[0],
+		Epoch:       epoch,
+		Version:     fields[2],
+		Release:     fields[3],
+		InstalledAt: installedAt,
+		License:     license,
+		Homepage:    homepage,
+		Maintainer:  maintainer,
 	}, nil
 }
 
 func outputPkgInfo(dir string) (out []byte, err error) {
-	const old = "%{NAME} %{EPOCH} %{VERSION} %{RELEASE}\n"
-	const new = "%{NAME} %{EPOCHNUM} %{VERSION} %{RELEASE}\n"
+	const old = "%{NAME}\t%{EPOCH}\t%{VERSION}\t%{RELEASE}\t%{INSTALLTIME}\t%{URL}\t%{LICENSE}\t%{VENDOR}\t%{PACKAGER}\n"
+	const new = "%{NAME}\t%{EPOCHNUM}\t%{VERSION}\t%{RELEASE}\t%{INSTALLTIME}\t%{URL}\t%{LICENSE}\t%{VENDOR}\t%{PACKAGER}\n"
 	out, err = exec.Command("rpm", "--dbpath", dir, "-qa", "--qf", new).Output()
 	if err != nil {
 		return exec.Command("rpm", "--dbpath", dir, "-qa", "--qf", old).Output()
@@ -108,6 +265,79 @@ func outputPkgInfo(dir string) (out []byte, err error) {
 	return out, nil
 }
 
+// AnalyzeSource maps binary packages to the source packages given in the SOURCERPM tag.
+func (a rpmCmdPkgAnalyzer) AnalyzeSource(fileMap extractor.FileMap) (map[string]analyzer.SrcPackage, error) {
+	for _, filename := range a.RequiredFiles() {
+		file, ok := fileMap.Get(filename)
+		if !ok {
+			continue
+		}
+		return a.parseSourceMap(file)
+	}
+	return nil, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
+}
+
+func (a rpmCmdPkgAnalyzer) parseSourceMap(packageBytes []byte) (map[string]analyzer.SrcPackage, error) {
+	tmpDir, err := ioutil.TempDir("", "rpm")
+	defer os.RemoveAll(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := filepath.Join(tmpDir, "Packages")
+	err = ioutil.WriteFile(filename, packageBytes, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := exec.Command("rpm", "--dbpath", tmpDir, "-qa", "--qf", "%{NAME} %{SOURCERPM}\n").Output()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to query source rpms: %w", err)
+	}
+
+	srcs := map[string]*analyzer.SrcPackage{}
+	binToSrc := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// gpg-pubkey doesn't have a source rpm
+		if len(fields) != 2 || fields[1] == "(none)" {
+			continue
+		}
+		name, version, err := parseSourceRPM(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		src, ok := srcs[fields[1]]
+		if !ok {
+			src = &analyzer.SrcPackage{Name: name, Version: version}
+			srcs[fields[1]] = src
+		}
+		src.BinaryNames = append(src.BinaryNames, fields[0])
+		binToSrc[fields[0]] = fields[1]
+	}
+
+	srcMap := map[string]analyzer.SrcPackage{}
+	for binName, sourceRPM := range binToSrc {
+		srcMap[binName] = *srcs[sourceRPM]
+	}
+	return srcMap, nil
+}
+
+// parseSourceRPM splits e.g. "rpm-4.11.3-35.el7.src.rpm" into "rpm" and "4.11.3-35.el7".
+func parseSourceRPM(sourceRPM string) (name, version string, err error) {
+	nvr := strings.TrimSuffix(strings.TrimSuffix(sourceRPM, ".rpm"), ".src")
+	releaseIndex := strings.LastIndex(nvr, "-")
+	if releaseIndex < 0 {
+		return "", "", xerrors.Errorf("invalid source rpm: %s", sourceRPM)
+	}
+	versionIndex := strings.LastIndex(nvr[:releaseIndex], "-")
+	if versionIndex < 0 {
+		return "", "", xerrors.Errorf("invalid source rpm: %s", sourceRPM)
+	}
+	return nvr[:versionIndex], nvr[versionIndex+1:], nil
+}
+
 func (a rpmCmdPkgAnalyzer) RequiredFiles() []string {
 	return []string{
 		"usr/lib/sysimage/rpm/Packages",
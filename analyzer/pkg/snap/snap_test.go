@@ -0,0 +1,108 @@
+package snap
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestNewerRevision(t *testing.T) {
+	var tests = map[string]struct {
+		a, b     string
+		expected bool
+	}{
+		"Number":      {a: "29", b: "9", expected: true},
+		"OlderNumber": {a: "9", b: "29", expected: false},
+		"Local":       {a: "x2", b: "x10", expected: false},
+		"StoreNewer":  {a: "1", b: "x10", expected: true},
+		"LocalOlder":  {a: "x10", b: "1", expected: false},
+	}
+	for testName, v := range tests {
+		actual := newerRevision(v.a, v.b)
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	stateJSON, err := ioutil.ReadFile("./testdata/state.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapYAML, err := ioutil.ReadFile("./testdata/snap.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		fileMap extractor.FileMap
+		pkgs    []analyzer.Package
+	}{
+		"StateWithSnapYAML": {
+			fileMap: extractor.MapFileMap{
+				"var/lib/snapd/state.json":                stateJSON,
+				"snap/hello-world/29/meta/snap.yaml":      snapYAML,
+				"snap/hello-world/current/meta/snap.yaml": snapYAML,
+			},
+			pkgs: []analyzer.Package{
+				{Name: "core18", Version: "2128", Release: "2128", Type: "snap"},
+				{Name: "hello-world", Version: "6.4", Release: "29", Type: "snap"},
+			},
+		},
+		"SnapYAMLOnly": {
+			fileMap: extractor.MapFileMap{
+				"snap/hello-world/29/meta/snap.yaml": snapYAML,
+			},
+			pkgs: []analyzer.Package{
+				{Name: "hello-world", Version: "6.4", Release: "29", Type: "snap"},
+			},
+		},
+		"HighestRevision": {
+			fileMap: extractor.MapFileMap{
+				"snap/hello-world/9/meta/snap.yaml":  []byte("name: hello-world\nversion: 6.1\n"),
+				"snap/hello-world/29/meta/snap.yaml": snapYAML,
+				"snap/hello-world/x1/meta/snap.yaml": []byte("name: hello-world\nversion: 7.0\n"),
+				"snap/hello-world/27/meta/snap.yaml": []byte("name: hello-world\nversion: 6.3\n"),
+			},
+			pkgs: []analyzer.Package{
+				{Name: "hello-world", Version: "6.4", Release: "29", Type: "snap"},
+			},
+		},
+		"CurrentInState": {
+			fileMap: extractor.MapFileMap{
+				"var/lib/snapd/state.json":           stateJSON,
+				"snap/hello-world/27/meta/snap.yaml": []byte("name: hello-world\nversion: 6.3\n"),
+				"snap/hello-world/29/meta/snap.yaml": snapYAML,
+				"snap/hello-world/30/meta/snap.yaml": []byte("name: hello-world\nversion: 6.5\n"),
+			},
+			pkgs: []analyzer.Package{
+				{Name: "core18", Version: "2128", Release: "2128", Type: "snap"},
+				{Name: "hello-world", Version: "6.4", Release: "29", Type: "snap"},
+			},
+		},
+		"InactiveInState": {
+			fileMap: extractor.MapFileMap{
+				"var/lib/snapd/state.json":       stateJSON,
+				"snap/disabled/3/meta/snap.yaml": []byte("name: disabled\nversion: 1.0\n"),
+			},
+			pkgs: []analyzer.Package{
+				{Name: "core18", Version: "2128", Release: "2128", Type: "snap"},
+				{Name: "hello-world", Version: "29", Release: "29", Type: "snap"},
+			},
+		},
+	}
+	a := snapPkgAnalyzer{}
+	for testName, v := range tests {
+		pkgs, err := a.Analyze(v.fileMap)
+		if err != nil {
+			t.Errorf("%s : catch the error : %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.pkgs, pkgs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.pkgs, pkgs)
+		}
+	}
+}
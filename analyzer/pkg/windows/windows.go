@@ -0,0 +1,65 @@
+package windows
+
+import (
+	"bytes"
+	"encoding/xml"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func init() {
+	analyzer.RegisterPkgAnalyzer(&windowsPkgAnalyzer{})
+}
+
+type windowsPkgAnalyzer struct{}
+
+type appxManifest struct {
+	Identity struct {
+		Name                  string `xml:"Name,attr"`
+		Version               string `xml:"Version,attr"`
+		ProcessorArchitecture string `xml:"ProcessorArchitecture,attr"`
+	} `xml:"Identity"`
+}
+
+// Analyze returns Windows Store apps installed under Program Files/WindowsApps/<package full name>/.
+// Broken manifests are skipped.
+func (a windowsPkgAnalyzer) Analyze(fileMap extractor.FileMap) ([]analyzer.Package, error) {
+	var pkgs []analyzer.Package
+	for _, filename := range a.targetFiles(fileMap) {
+		content, _ := fileMap.Get(filename)
+		var m appxManifest
+		if err := xml.NewDecoder(bytes.NewBuffer(content)).Decode(&m); err != nil {
+			continue
+		}
+		if m.Identity.Name == "" || m.Identity.Version == "" {
+			continue
+		}
+		pkgs = append(pkgs, analyzer.Package{
+			Name:    m.Identity.Name,
+			Version: m.Identity.Version,
+			Arch:    m.Identity.ProcessorArchitecture,
+			Type:    analyzer.TypeAppx,
+		})
+	}
+	if len(pkgs) == 0 {
+		return nil, xerrors.Errorf("no package detected: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+	return pkgs, nil
+}
+
+func (a windowsPkgAnalyzer) targetFiles(fileMap extractor.FileMap) []string {
+	var filenames []string
+	for _, filename := range fileMap.Paths() {
+		if extractor.MatchAny(a.RequiredFiles(), filename) {
+			filenames = append(filenames, filename)
+		}
+	}
+	return filenames
+}
+
+func (a windowsPkgAnalyzer) RequiredFiles() []string {
+	return []string{"Files/Program Files/WindowsApps/*/AppxManifest.xml"}
+}
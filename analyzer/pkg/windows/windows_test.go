@@ -0,0 +1,43 @@
+package windows
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestAnalyze(t *testing.T) {
+	fileMap := extractor.MapFileMap{}
+	for path, testdata := range map[string]string{
+		"Files/Program Files/WindowsApps/Microsoft.WindowsCalculator_10.1906.55.0_x64__8wekyb3d8bbwe/AppxManifest.xml": "./testdata/AppxManifest.xml",
+		"Files/Program Files/WindowsApps/Microsoft.VCLibs.140.00_14.0.27810.0_neutral__8wekyb3d8bbwe/AppxManifest.xml": "./testdata/AppxManifest_neutral.xml",
+		"Files/Program Files/WindowsApps/Broken_1.0.0.0_x64__8wekyb3d8bbwe/AppxManifest.xml":                           "./testdata/AppxManifest_broken.xml",
+	} {
+		b, err := ioutil.ReadFile(testdata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileMap[path] = b
+	}
+
+	expected := []analyzer.Package{
+		{Name: "Microsoft.VCLibs.140.00", Version: "14.0.27810.0", Arch: "neutral", Type: "appx"},
+		{Name: "Microsoft.WindowsCalculator", Version: "10.1906.55.0", Arch: "x64", Type: "appx"},
+	}
+
+	a := windowsPkgAnalyzer{}
+	pkgs, err := a.Analyze(fileMap)
+	if err != nil {
+		t.Errorf("catch the error : %v", err)
+	}
+	if !reflect.DeepEqual(expected, pkgs) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, pkgs)
+	}
+
+	if _, err := a.Analyze(extractor.MapFileMap{}); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,67 @@
+//go:build cgo
+// +build cgo
+
+package analyzer
+
+import (
+	"plugin"
+
+	"golang.org/x/xerrors"
+)
+
+// LoadAnalyzerPlugin loads the analyzers from the Go plugin built with "go build -buildmode=plugin",
+// e.g. for proprietary analyzers which are not in this repository. The plugin exports any of the variables
+//
+//	var OSAnalyzers []analyzer.OSAnalyzer
+//	var PkgAnalyzers []analyzer.PkgAnalyzer
+//	var LibraryAnalyzers []analyzer.LibraryAnalyzer
+//
+// which are registered like the built-in analyzers. Nothing is registered if any of them has another type.
+// The plugin must be built with the same version of Go and of this package as the program loading it.
+// Plugins are supported only on Linux, FreeBSD and macOS with cgo.
+func LoadAnalyzerPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return xerrors.Errorf("failed to open the plugin(%s): %w", path, err)
+	}
+
+	var osAs []OSAnalyzer
+	var pkgAs []PkgAnalyzer
+	var libAs []LibraryAnalyzer
+	var found bool
+	if sym, err := p.Lookup("OSAnalyzers"); err == nil {
+		v, ok := sym.(*[]OSAnalyzer)
+		if !ok {
+			return xerrors.Errorf("invalid type of OSAnalyzers in the plugin(%s): %T", path, sym)
+		}
+		osAs, found = *v, true
+	}
+	if sym, err := p.Lookup("PkgAnalyzers"); err == nil {
+		v, ok := sym.(*[]PkgAnalyzer)
+		if !ok {
+			return xerrors.Errorf("invalid type of PkgAnalyzers in the plugin(%s): %T", path, sym)
+		}
+		pkgAs, found = *v, true
+	}
+	if sym, err := p.Lookup("LibraryAnalyzers"); err == nil {
+		v, ok := sym.(*[]LibraryAnalyzer)
+		if !ok {
+			return xerrors.Errorf("invalid type of LibraryAnalyzers in the plugin(%s): %T", path, sym)
+		}
+		libAs, found = *v, true
+	}
+	if !found {
+		return xerrors.Errorf("no analyzers in the plugin(%s)", path)
+	}
+
+	for _, a := range osAs {
+		RegisterOSAnalyzer(a)
+	}
+	for _, a := range pkgAs {
+		RegisterPkgAnalyzer(a)
+	}
+	for _, a := range libAs {
+		RegisterLibraryAnalyzer(a)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !cgo
+// +build !cgo
+
+package analyzer
+
+import "golang.org/x/xerrors"
+
+// LoadAnalyzerPlugin loads nothing as Go plugins require cgo. It returns ErrPluginNotSupported.
+func LoadAnalyzerPlugin(path string) error {
+	return xerrors.Errorf("failed to load the plugin(%s): %w", path, ErrPluginNotSupported)
+}
@@ -0,0 +1,3 @@
+// Package plugintest tests analyzer.LoadAnalyzerPlugin outside of the analyzer package,
+// because a plugin can't be loaded into the test binary with the internal tests of the packages it imports.
+package plugintest
@@ -0,0 +1,59 @@
+//go:build cgo
+// +build cgo
+
+package plugintest
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+)
+
+func TestLoadAnalyzerPlugin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building the plugin takes time")
+	}
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" && runtime.GOOS != "freebsd" {
+		t.Skipf("plugins are not supported on %s", runtime.GOOS)
+	}
+
+	dir, err := ioutil.TempDir("", "plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "custom.so")
+	// The plugin must be built with the same flags as the test binary, otherwise it can't be loaded
+	args := []string{"build", "-buildmode=plugin", "-o", path}
+	if raceEnabled {
+		args = append(args, "-race")
+	}
+	if out, err := exec.Command("go", append(args, "./testdata/plugin")...).CombinedOutput(); err != nil {
+		t.Fatalf("failed to build the plugin: %s: %v", out, err)
+	}
+
+	if err = analyzer.LoadAnalyzerPlugin(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	detected, err := analyzer.GetOS(extractor.MapFileMap{"etc/custom-release": []byte("1.0\n")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := (analyzer.OS{Family: "custom", Name: "1.0"}); !reflect.DeepEqual(expected, detected) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, detected)
+	}
+	if expected, actual := []string{"etc/custom-release"}, analyzer.RequiredFilenames(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+
+	if err = analyzer.LoadAnalyzerPlugin(filepath.Join(dir, "nonexistent.so")); err == nil {
+		t.Error("an error is expected for the nonexistent plugin")
+	}
+}
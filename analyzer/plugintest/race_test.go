@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package plugintest
+
+// raceEnabled is true when the test binary is built with -race, and the plugin must be built with it, too.
+const raceEnabled = true
@@ -0,0 +1,26 @@
+// The plugin for the test of LoadAnalyzerPlugin.
+package main
+
+import (
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"golang.org/x/xerrors"
+)
+
+type customOSAnalyzer struct{}
+
+func (a customOSAnalyzer) Analyze(fileMap extractor.FileMap) (analyzer.OS, error) {
+	b, ok := fileMap.Get("etc/custom-release")
+	if !ok {
+		return analyzer.OS{}, xerrors.Errorf("custom: %w", analyzer.ErrNoAnalyzerMatch)
+	}
+	return analyzer.OS{Family: "custom", Name: strings.TrimSpace(string(b))}, nil
+}
+
+func (a customOSAnalyzer) RequiredFiles() []string {
+	return []string{"etc/custom-release"}
+}
+
+var OSAnalyzers = []analyzer.OSAnalyzer{customOSAnalyzer{}}
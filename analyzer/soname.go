@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"bytes"
+	"debug/elf"
+	"path"
+	"regexp"
+
+	"golang.org/x/xerrors"
+)
+
+// e.g. libc.so.6, libssl.so.1.1, libz.so
+var sharedLibraryRegexp = regexp.MustCompile(`^[^/]+\.so(?:\.[0-9]+)*$`)
+
+// FileListProvider returns the absolute paths of the files installed by the package,
+// e.g. from /var/lib/dpkg/info/<pkg>.list or FILENAMES of RPM.
+type FileListProvider interface {
+	Files(pkg Package) []string
+}
+
+// BuildSonameMap maps the sonames to the packages providing the shared libraries, e.g. "libc.so.6" => libc6.
+// The file name is used as the soname, which is the name of the symlink created by ldconfig.
+// When multiple packages provide the same name, e.g. for different architectures, the first package wins.
+// The file lists may not be required by the analyzers, e.g. dpkg.FileListRequiredFiles must be
+// passed to the extractor explicitly.
+func BuildSonameMap(pkgs []Package, fileListProvider FileListProvider) map[string]Package {
+	sonames := map[string]Package{}
+	for _, pkg := range pkgs {
+		if pkg.Type == TypeSource {
+			continue
+		}
+		for _, file := range fileListProvider.Files(pkg) {
+			soname := path.Base(file)
+			if !sharedLibraryRegexp.MatchString(soname) {
+				continue
+			}
+			if _, ok := sonames[soname]; !ok {
+				sonames[soname] = pkg
+			}
+		}
+	}
+	return sonames
+}
+
+// ReadELFDependencies returns the sonames in DT_NEEDED of the ELF binary.
+func ReadELFDependencies(b []byte) ([]string, error) {
+	f, err := elf.NewFile(bytes.NewReader(b))
+	if err != nil {
+		return nil, xerrors.Errorf("invalid ELF format: %v: %w", err, ErrMalformedFile)
+	}
+	defer f.Close()
+
+	needed, err := f.ImportedLibraries()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read DT_NEEDED: %w", err)
+	}
+	return needed, nil
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/xerrors"
+)
+
+type mapFileListProvider map[string][]string
+
+func (m mapFileListProvider) Files(pkg Package) []string {
+	return m[pkg.Name]
+}
+
+func TestBuildSonameMap(t *testing.T) {
+	libc6 := Package{Name: "libc6", Version: "2.28-10", Type: TypeBinary}
+	libc6i386 := Package{Name: "libc6-i386", Version: "2.28-10", Type: TypeBinary}
+	libssl := Package{Name: "libssl1.1", Version: "1.1.1d-0+deb10u2", Type: TypeBinary}
+	libsslDev := Package{Name: "libssl-dev", Version: "1.1.1d-0+deb10u2", Type: TypeBinary}
+	glibc := Package{Name: "glibc", Version: "2.28-10", Type: TypeSource}
+
+	provider := mapFileListProvider{
+		"libc6":      {"/lib/x86_64-linux-gnu", "/lib/x86_64-linux-gnu/libc-2.28.so", "/lib/x86_64-linux-gnu/libc.so.6"},
+		"libc6-i386": {"/lib32/libc.so.6"},
+		"libssl1.1":  {"/usr/lib/x86_64-linux-gnu/libssl.so.1.1", "/usr/share/doc/libssl1.1/changelog.gz"},
+		"libssl-dev": {"/usr/lib/x86_64-linux-gnu/libssl.so", "/usr/lib/x86_64-linux-gnu/libssl.a"},
+		"glibc":      {"/lib/x86_64-linux-gnu/libm.so.6"},
+	}
+	expected := map[string]Package{
+		"libc-2.28.so":  libc6,
+		"libc.so.6":     libc6,
+		"libssl.so.1.1": libssl,
+		"libssl.so":     libsslDev,
+	}
+	actual := BuildSonameMap([]Package{libc6, libc6i386, libssl, libsslDev, glibc}, provider)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+}
+
+func TestReadELFDependencies(t *testing.T) {
+	_, err := ReadELFDependencies([]byte("#!/bin/sh\n"))
+	if !xerrors.Is(err, ErrMalformedFile) {
+		t.Errorf("\nexpected : %v\nactual : %v", ErrMalformedFile, err)
+	}
+}
@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+// Packages sorts packages by Type, Name, Version and Arch in ascending order.
+// Ties are broken by Release and Epoch. The order is a part of the API and will not change,
+// so sorted results can be compared across versions of fanal.
+// Versions are compared as strings, not by the version schemes of the distributions.
+type Packages []Package
+
+func (p Packages) Len() int      { return len(p) }
+func (p Packages) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p Packages) Less(i, j int) bool {
+	switch {
+	case p[i].Type != p[j].Type:
+		return p[i].Type < p[j].Type
+	case p[i].Name != p[j].Name:
+		return p[i].Name < p[j].Name
+	case p[i].Version != p[j].Version:
+		return p[i].Version < p[j].Version
+	case p[i].Arch != p[j].Arch:
+		return p[i].Arch < p[j].Arch
+	case p[i].Release != p[j].Release:
+		return p[i].Release < p[j].Release
+	}
+	return p[i].Epoch < p[j].Epoch
+}
+
+// SortPackages sorts the packages in place in the order of Packages.
+func SortPackages(pkgs []Package) {
+	sort.Stable(Packages(pkgs))
+}
+
+// PackagesEqual reports whether the packages are the same regardless of the order.
+// The arguments are not modified.
+func PackagesEqual(a, b []Package) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]Package(nil), a...)
+	b = append([]Package(nil), b...)
+	SortPackages(a)
+	SortPackages(b)
+	for i := range a {
+		if !reflect.DeepEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Libraries sorts libraries by Name and Version in ascending order. The order will not change.
+type Libraries []types.Library
+
+func (l Libraries) Len() int      { return len(l) }
+func (l Libraries) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l Libraries) Less(i, j int) bool {
+	if l[i].Name != l[j].Name {
+		return l[i].Name < l[j].Name
+	}
+	return l[i].Version < l[j].Version
+}
+
+// SortLibraries sorts the libraries in place in the order of Libraries.
+func SortLibraries(libs []types.Library) {
+	sort.Stable(Libraries(libs))
+}
+
+// LibrariesEqual reports whether the libraries are the same regardless of the order.
+// The arguments are not modified.
+func LibrariesEqual(a, b []types.Library) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]types.Library(nil), a...)
+	b = append([]types.Library(nil), b...)
+	SortLibraries(a)
+	SortLibraries(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestSortPackages(t *testing.T) {
+	pkgs := []Package{
+		{Name: "openssl", Version: "1.1.1", Release: "2", Type: TypeBinary},
+		{Name: "openssl", Version: "1.1.1", Type: TypeSource},
+		{Name: "bash", Version: "5.0", Arch: "x86_64", Type: TypeBinary},
+		{Name: "openssl", Version: "1.1.1", Release: "1", Type: TypeBinary},
+		{Name: "bash", Version: "5.0", Arch: "i686", Type: TypeBinary},
+		{Name: "bash", Version: "4.4", Type: TypeBinary},
+	}
+	expected := []Package{
+		{Name: "bash", Version: "4.4", Type: TypeBinary},
+		{Name: "bash", Version: "5.0", Arch: "i686", Type: TypeBinary},
+		{Name: "bash", Version: "5.0", Arch: "x86_64", Type: TypeBinary},
+		{Name: "openssl", Version: "1.1.1", Release: "1", Type: TypeBinary},
+		{Name: "openssl", Version: "1.1.1", Release: "2", Type: TypeBinary},
+		{Name: "openssl", Version: "1.1.1", Type: TypeSource},
+	}
+	SortPackages(pkgs)
+	if !reflect.DeepEqual(expected, pkgs) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, pkgs)
+	}
+}
+
+func TestPackagesEqual(t *testing.T) {
+	a := []Package{{Name: "bash", Version: "5.0"}, {Name: "zlib", Version: "1.2.11"}}
+	var tests = map[string]struct {
+		b        []Package
+		expected bool
+	}{
+		"same order":      {b: []Package{{Name: "bash", Version: "5.0"}, {Name: "zlib", Version: "1.2.11"}}, expected: true},
+		"different order": {b: []Package{{Name: "zlib", Version: "1.2.11"}, {Name: "bash", Version: "5.0"}}, expected: true},
+		"different field": {b: []Package{{Name: "zlib", Version: "1.2.11"}, {Name: "bash", Version: "5.0", License: "GPL-3+"}}},
+		"different len":   {b: []Package{{Name: "bash", Version: "5.0"}}},
+	}
+	for testname, v := range tests {
+		b := append([]Package(nil), v.b...)
+		if actual := PackagesEqual(a, b); actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.expected, actual)
+		}
+		if !reflect.DeepEqual(b, v.b) {
+			t.Errorf("[%s]\nthe argument was modified: %v", testname, b)
+		}
+	}
+}
+
+func TestLibrariesEqual(t *testing.T) {
+	a := []types.Library{{Name: "rails", Version: "5.2.3"}, {Name: "rack", Version: "2.0.7"}, {Name: "rack", Version: "1.6.11"}}
+	var tests = map[string]struct {
+		b        []types.Library
+		expected bool
+	}{
+		"different order":   {b: []types.Library{{Name: "rack", Version: "1.6.11"}, {Name: "rack", Version: "2.0.7"}, {Name: "rails", Version: "5.2.3"}}, expected: true},
+		"different version": {b: []types.Library{{Name: "rack", Version: "1.6.11"}, {Name: "rack", Version: "2.0.8"}, {Name: "rails", Version: "5.2.3"}}},
+		"different len":     {b: []types.Library{{Name: "rails", Version: "5.2.3"}}},
+	}
+	for testname, v := range tests {
+		if actual := LibrariesEqual(a, v.b); actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.expected, actual)
+		}
+	}
+	if a[0].Name != "rails" {
+		t.Errorf("the argument was modified: %v", a)
+	}
+}
@@ -0,0 +1,64 @@
+// Package testing provides the helpers to test and benchmark analyzers with fixtures.
+package testing
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/extractor"
+	"golang.org/x/xerrors"
+)
+
+// LoadFixture reads the files of the fixture, which maps the paths in the image to the files,
+// e.g. {"lib/apk/db/installed": "testdata/apk"}, so the existing testdata is reused for benchmarks.
+func LoadFixture(fixture map[string]string) (extractor.MapFileMap, error) {
+	filesMap := extractor.MapFileMap{}
+	for filePath, file := range fixture {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load the fixture(%s): %w", file, err)
+		}
+		filesMap[filePath] = b
+	}
+	return filesMap, nil
+}
+
+// RunAnalyzerBenchmark runs the analyzer b.N times on the files of the fixture, which is loaded by LoadFixture.
+// The throughput is the total size of the files, so MB/s is comparable between fixtures of different sizes.
+// The analyzer is one of OSAnalyzer, PkgAnalyzer, LibraryAnalyzer and SourceAnalyzer.
+// The benchmark fails if the analyzer fails, e.g. the fixture doesn't have the required files.
+func RunAnalyzerBenchmark(b *testing.B, a interface{}, fixture map[string]string) {
+	b.Helper()
+	var analyze analyzer.AnalyzeFunc
+	switch a := a.(type) {
+	case analyzer.OSAnalyzer:
+		analyze = func(fm extractor.FileMap) (interface{}, error) { return a.Analyze(fm) }
+	case analyzer.PkgAnalyzer:
+		analyze = func(fm extractor.FileMap) (interface{}, error) { return a.Analyze(fm) }
+	case analyzer.LibraryAnalyzer:
+		analyze = func(fm extractor.FileMap) (interface{}, error) { return a.Analyze(fm) }
+	case analyzer.SourceAnalyzer:
+		analyze = func(fm extractor.FileMap) (interface{}, error) { return a.AnalyzeSource(fm) }
+	default:
+		b.Fatalf("unknown analyzer: %T", a)
+	}
+
+	filesMap, err := LoadFixture(fixture)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var size int64
+	for _, content := range filesMap {
+		size += int64(len(content))
+	}
+
+	b.SetBytes(size)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := analyze(filesMap); err != nil {
+			b.Fatalf("%T failed: %v", a, err)
+		}
+	}
+}
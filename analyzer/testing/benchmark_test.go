@@ -0,0 +1,24 @@
+package testing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadFixture(t *testing.T) {
+	filesMap, err := LoadFixture(map[string]string{"lib/apk/db/installed": "../pkg/apk/testdata/apk"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var paths []string
+	for path := range filesMap {
+		paths = append(paths, path)
+	}
+	if expected := []string{"lib/apk/db/installed"}; !reflect.DeepEqual(expected, paths) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, paths)
+	}
+
+	if _, err = LoadFixture(map[string]string{"lib/apk/db/installed": "nonexistent"}); err == nil {
+		t.Error("an error is expected for the nonexistent file")
+	}
+}
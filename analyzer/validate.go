@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// e.g. 1.2.3, v2.0.0-rc1, 4.17.15+build.1, 2019.3
+var libraryVersionRegexp = regexp.MustCompile(`^v?[0-9]+(?:\.[0-9]+)*(?:[-+.~_]?[0-9A-Za-z]+(?:[.-][0-9A-Za-z]+)*)?$`)
+
+// ValidationIssue is an inconsistency in AnalyzeResult.
+type ValidationIssue struct {
+	Severity Severity
+	// Field is the path to the value, e.g. "Packages[3].Version" and "Libraries[app/Gemfile.lock][0].Version".
+	Field   string
+	Message string
+}
+
+// ValidateResult checks that the result is consistent, e.g. before it is stored or sent to a server.
+// The result is not modified.
+func ValidateResult(r AnalyzeResult) []ValidationIssue {
+	var issues []ValidationIssue
+	issues = append(issues, validateOS(r.OS)...)
+	issues = append(issues, validatePackages(r.Packages)...)
+	issues = append(issues, validateLibraries(r.Libraries)...)
+	return issues
+}
+
+func validateOS(o OS) []ValidationIssue {
+	if o.Family != "" && o.Name == "" {
+		return []ValidationIssue{{
+			Severity: SeverityWarning,
+			Field:    "OS.Name",
+			Message:  fmt.Sprintf("the version of %s is empty", o.Family),
+		}}
+	}
+	return nil
+}
+
+func validatePackages(pkgs []Package) []ValidationIssue {
+	var issues []ValidationIssue
+	seen := map[string]int{}
+	for i, pkg := range pkgs {
+		field := fmt.Sprintf("Packages[%d]", i)
+		if pkg.Name == "" {
+			issues = append(issues, ValidationIssue{Severity: SeverityError, Field: field + ".Name", Message: "empty package name"})
+		}
+		if pkg.Version == "" {
+			issues = append(issues, ValidationIssue{Severity: SeverityError, Field: field + ".Version",
+				Message: fmt.Sprintf("empty version of %s", pkg.Name)})
+		}
+
+		key := strings.Join([]string{pkg.Name, pkg.VersionString(), pkg.Arch, pkg.Type}, "\x00")
+		if j, ok := seen[key]; ok {
+			issues = append(issues, ValidationIssue{Severity: SeverityWarning, Field: field,
+				Message: fmt.Sprintf("duplicate of Packages[%d]: %s %s", j, pkg.Name, pkg.VersionString())})
+			continue
+		}
+		seen[key] = i
+	}
+	return issues
+}
+
+// validateLibraries checks the file paths are relative to the root like the other file paths of fanal,
+// e.g. "app/Gemfile.lock" rather than "/app/Gemfile.lock" or "./app/Gemfile.lock".
+func validateLibraries(libraries map[FilePath][]types.Library) []ValidationIssue {
+	var filePaths []string
+	for filePath := range libraries {
+		filePaths = append(filePaths, string(filePath))
+	}
+	sort.Strings(filePaths)
+
+	var issues []ValidationIssue
+	normalized := map[string]string{}
+	for _, filePath := range filePaths {
+		field := fmt.Sprintf("Libraries[%s]", filePath)
+		n := strings.TrimPrefix(path.Clean("/"+filePath), "/")
+		if n != filePath {
+			issues = append(issues, ValidationIssue{Severity: SeverityWarning, Field: field,
+				Message: fmt.Sprintf("the file path should be relative to the root: %s", n)})
+		}
+		if other, ok := normalized[n]; ok {
+			issues = append(issues, ValidationIssue{Severity: SeverityWarning, Field: field,
+				Message: fmt.Sprintf("the same file as %s", other)})
+		} else {
+			normalized[n] = filePath
+		}
+
+		for i, lib := range libraries[FilePath(filePath)] {
+			libField := fmt.Sprintf("%s[%d]", field, i)
+			switch {
+			case lib.Name == "":
+				issues = append(issues, ValidationIssue{Severity: SeverityError, Field: libField + ".Name", Message: "empty library name"})
+			case lib.Version == "":
+				issues = append(issues, ValidationIssue{Severity: SeverityError, Field: libField + ".Version",
+					Message: fmt.Sprintf("empty version of %s", lib.Name)})
+			case !libraryVersionRegexp.MatchString(lib.Version):
+				// e.g. a version range or a git commit which the vulnerability detection may not handle
+				issues = append(issues, ValidationIssue{Severity: SeverityInfo, Field: libField + ".Version",
+					Message: fmt.Sprintf("unusual version of %s: %s", lib.Name, lib.Version)})
+			}
+		}
+	}
+	return issues
+}
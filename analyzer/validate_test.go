@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestValidateResult(t *testing.T) {
+	var tests = map[string]struct {
+		result   AnalyzeResult
+		expected []ValidationIssue
+	}{
+		"valid": {
+			result: AnalyzeResult{
+				OS: OS{Family: "alpine", Name: "3.10.2"},
+				Packages: []Package{
+					{Name: "musl", Version: "1.1.22", Release: "r3"},
+					{Name: "musl", Version: "1.1.22", Release: "r3", Type: TypeSource},
+				},
+				Libraries: map[FilePath][]types.Library{
+					"app/Gemfile.lock":  {{Name: "rails", Version: "5.2.3"}},
+					"app/package.json":  {{Name: "lodash", Version: "4.17.15"}, {Name: "foo", Version: "v1.0.0-rc.1"}},
+					"app/requirements":  {{Name: "django", Version: "2.2.4"}, {Name: "tzdata", Version: "2019c"}},
+					"usr/lib/app.jar":   {{Name: "log4j", Version: "1.2.17"}},
+					"app/composer.lock": {{Name: "symfony/yaml", Version: "v4.3.4"}},
+				},
+			},
+		},
+		"os": {
+			result: AnalyzeResult{OS: OS{Family: "debian"}},
+			expected: []ValidationIssue{
+				{Severity: SeverityWarning, Field: "OS.Name", Message: "the version of debian is empty"},
+			},
+		},
+		"packages": {
+			result: AnalyzeResult{
+				Packages: []Package{
+					{Name: "bash", Version: "5.0-4"},
+					{Version: "1.0"},
+					{Name: "zlib"},
+					{Name: "bash", Version: "5.0-4"},
+				},
+			},
+			expected: []ValidationIssue{
+				{Severity: SeverityError, Field: "Packages[1].Name", Message: "empty package name"},
+				{Severity: SeverityError, Field: "Packages[2].Version", Message: "empty version of zlib"},
+				{Severity: SeverityWarning, Field: "Packages[3]", Message: "duplicate of Packages[0]: bash 5.0-4"},
+			},
+		},
+		"libraries": {
+			result: AnalyzeResult{
+				Libraries: map[FilePath][]types.Library{
+					"/app/Gemfile.lock": {{Name: "rails"}},
+					"app/Gemfile.lock":  {{Name: "rails", Version: "5.2.3"}},
+					"app/package.json":  {{Name: "lodash", Version: "^4.17.0"}, {Version: "1.0.0"}},
+				},
+			},
+			expected: []ValidationIssue{
+				{Severity: SeverityWarning, Field: "Libraries[/app/Gemfile.lock]", Message: "the file path should be relative to the root: app/Gemfile.lock"},
+				{Severity: SeverityError, Field: "Libraries[/app/Gemfile.lock][0].Version", Message: "empty version of rails"},
+				{Severity: SeverityWarning, Field: "Libraries[app/Gemfile.lock]", Message: "the same file as /app/Gemfile.lock"},
+				{Severity: SeverityInfo, Field: "Libraries[app/package.json][0].Version", Message: "unusual version of lodash: ^4.17.0"},
+				{Severity: SeverityError, Field: "Libraries[app/package.json][1].Name", Message: "empty library name"},
+			},
+		},
+	}
+	for testname, v := range tests {
+		actual := ValidateResult(v.result)
+		if !reflect.DeepEqual(v.expected, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package acr
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/auth"
+	"github.com/knqyf263/fanal/token"
+)
+
+// Option configures the provider of ACRCredentialProvider.
+type Option func(*credentialProvider)
+
+// WithAuthorityHost sets the host of Azure Active Directory of the sovereign clouds, e.g. login.microsoftonline.us.
+// By default, the authority of the cloud of the registry is used.
+func WithAuthorityHost(host string) Option {
+	return func(p *credentialProvider) {
+		p.authorityHost = host
+	}
+}
+
+type credentialProvider struct {
+	tenantID      string
+	clientID      string
+	clientSecret  string
+	authorityHost string
+	client        *http.Client
+}
+
+// ACRCredentialProvider returns the provider of the credentials of Azure Container Registry for the service principal.
+// The AAD token of the service principal is exchanged for the refresh token of the registry,
+// which is cached until shortly before it expires. The other registries get no credentials.
+func ACRCredentialProvider(tenantID, clientID, clientSecret string, opts ...Option) auth.CredentialProvider {
+	p := &credentialProvider{
+		tenantID:     tenantID,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *credentialProvider) Credentials(registryHost string) (string, string, error) {
+	if !token.IsACR(registryHost) {
+		return "", "", nil
+	}
+
+	a := token.NewACR(registryHost)
+	a.Client = p.client
+	a.Tenant = p.tenantID
+	a.AADToken = func(ctx context.Context, resource string) (string, error) {
+		return a.ClientSecretAADToken(ctx, p.authorityHost, p.tenantID, p.clientID, p.clientSecret, resource)
+	}
+	username, password, err := a.GetCredential(context.Background())
+	if err != nil {
+		return "", "", xerrors.Errorf("failed to get the credential of %s: %w", registryHost, err)
+	}
+	return username, password, nil
+}
@@ -0,0 +1,68 @@
+package acr
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestACRCredentialProvider(t *testing.T) {
+	var requests []string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Host+r.URL.Path)
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		switch r.Host + r.URL.Path {
+		case "login.microsoftonline.us/tenant-id/oauth2/token":
+			if r.PostForm.Get("client_id") != "client-id" || r.PostForm.Get("client_secret") != "secret" ||
+				r.PostForm.Get("resource") != "https://management.usgovcloudapi.net/" {
+				t.Errorf("unexpected token request: %v", r.PostForm)
+			}
+			w.Write([]byte(`{"access_token": "aad-token"}`))
+		case "myregistry.azurecr.us/oauth2/exchange":
+			if r.PostForm.Get("service") != "myregistry.azurecr.us" || r.PostForm.Get("access_token") != "aad-token" ||
+				r.PostForm.Get("tenant") != "tenant-id" {
+				t.Errorf("unexpected exchange request: %v", r.PostForm)
+			}
+			// without the expiry, the token is not cached
+			w.Write([]byte(`{"refresh_token": "refresh-token"}`))
+		default:
+			t.Errorf("unexpected request: %s%s", r.Host, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	p := ACRCredentialProvider("tenant-id", "client-id", "secret", WithAuthorityHost("login.microsoftonline.us")).(*credentialProvider)
+	// All the hosts are resolved to the test server
+	p.client = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, ts.Listener.Addr().String())
+		},
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+
+	username, password, err := p.Credentials("myregistry.azurecr.us")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if username != "00000000-0000-0000-0000-000000000000" || password != "refresh-token" {
+		t.Errorf("unexpected credential: %s:%s", username, password)
+	}
+	if len(requests) != 2 {
+		t.Errorf("expected 2 requests, got %v", requests)
+	}
+
+	// The token must not be sent to the other registries
+	requests = nil
+	if username, password, err = p.Credentials("gcr.io"); err != nil || username != "" || password != "" {
+		t.Errorf("unexpected credential: %s:%s, %v", username, password, err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("unexpected requests: %v", requests)
+	}
+}
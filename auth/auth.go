@@ -0,0 +1,8 @@
+package auth
+
+// CredentialProvider returns the credentials of registries, e.g. short-lived tokens of cloud registries.
+type CredentialProvider interface {
+	// Credentials returns the user name and the password for the host of the registry,
+	// e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com.
+	Credentials(registryHost string) (username, password string, err error)
+}
@@ -0,0 +1,88 @@
+package ecr
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/auth"
+	"github.com/knqyf263/fanal/token"
+)
+
+type credentialProvider struct {
+	region string
+	cfg    aws.Config
+
+	mu      sync.Mutex
+	clients map[string]ecriface.ECRAPI
+	// newClient is replaced in tests
+	newClient func(cfg aws.Config) (ecriface.ECRAPI, error)
+}
+
+// ECRCredentialProvider returns the provider of the credentials of ECR, which are equivalent to
+// "aws ecr get-login-password". The AWS credentials are taken from cfg and the default credential chain.
+// The region of the host, e.g. us-west-2 of 123456789012.dkr.ecr.us-west-2.amazonaws.com, takes precedence
+// over region, which is used for the other hosts. The tokens are cached and refreshed before they expire in 12 hours.
+func ECRCredentialProvider(region string, cfg aws.Config) auth.CredentialProvider {
+	return &credentialProvider{
+		region:    region,
+		cfg:       cfg,
+		clients:   map[string]ecriface.ECRAPI{},
+		newClient: newClient,
+	}
+}
+
+func newClient(cfg aws.Config) (ecriface.ECRAPI, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            cfg,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create an AWS session: %w", err)
+	}
+	return ecr.New(sess), nil
+}
+
+func (p *credentialProvider) Credentials(registryHost string) (username, password string, err error) {
+	registryID, region, ok := token.ParseECRHost(registryHost)
+	if !ok {
+		// The default registry of the account
+		registryID, region = "", p.region
+	}
+
+	client, err := p.client(region)
+	if err != nil {
+		return "", "", err
+	}
+	e := &token.ECR{Client: client, RegistryID: registryID, Region: region}
+	username, password, err = e.GetCredential(context.Background())
+	if err != nil {
+		return "", "", xerrors.Errorf("failed to get the credential of %s: %w", registryHost, err)
+	}
+	return username, password, nil
+}
+
+// client returns the client of the region, which is shared by the registries in the region.
+func (p *credentialProvider) client(region string) (ecriface.ECRAPI, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.clients[region]; ok {
+		return c, nil
+	}
+
+	cfg := p.cfg.Copy()
+	if region != "" {
+		cfg.Region = aws.String(region)
+	}
+	c, err := p.newClient(*cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[region] = c
+	return c, nil
+}
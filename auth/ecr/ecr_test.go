@@ -0,0 +1,83 @@
+package ecr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+)
+
+type mockedECR struct {
+	ecriface.ECRAPI
+	region      string
+	calls       int
+	registryIDs []string
+}
+
+func (m *mockedECR) GetAuthorizationTokenWithContext(ctx context.Context, input *ecr.GetAuthorizationTokenInput, options ...request.Option) (*ecr.GetAuthorizationTokenOutput, error) {
+	m.calls++
+	m.registryIDs = aws.StringValueSlice(input.RegistryIds)
+	return &ecr.GetAuthorizationTokenOutput{
+		AuthorizationData: []*ecr.AuthorizationData{
+			// AWS:password
+			{AuthorizationToken: aws.String("QVdTOnBhc3N3b3Jk"), ExpiresAt: aws.Time(time.Now().Add(12 * time.Hour))},
+		},
+	}, nil
+}
+
+func TestECRCredentialProvider(t *testing.T) {
+	clients := map[string]*mockedECR{}
+	p := ECRCredentialProvider("us-east-1", aws.Config{}).(*credentialProvider)
+	p.newClient = func(cfg aws.Config) (ecriface.ECRAPI, error) {
+		c := &mockedECR{region: aws.StringValue(cfg.Region)}
+		clients[c.region] = c
+		return c, nil
+	}
+
+	var tests = map[string]struct {
+		host                string
+		expectedRegion      string
+		expectedRegistryIDs []string
+		expectedCalls       int
+	}{
+		"region of the host": {
+			host:                "210987654321.dkr.ecr.ap-northeast-1.amazonaws.com",
+			expectedRegion:      "ap-northeast-1",
+			expectedRegistryIDs: []string{"210987654321"},
+			expectedCalls:       1,
+		},
+		"other host": {
+			host:                "registry.example.com",
+			expectedRegion:      "us-east-1",
+			expectedRegistryIDs: []string{},
+			expectedCalls:       1,
+		},
+	}
+	for testname, v := range tests {
+		// The second call uses the cached token
+		for i := 0; i < 2; i++ {
+			username, password, err := p.Credentials(v.host)
+			if err != nil {
+				t.Fatalf("[%s] unexpected error: %v", testname, err)
+			}
+			if username != "AWS" || password != "password" {
+				t.Errorf("[%s] unexpected credential: %s:%s", testname, username, password)
+			}
+		}
+		c, ok := clients[v.expectedRegion]
+		if !ok {
+			t.Fatalf("[%s] no client of %s: %v", testname, v.expectedRegion, clients)
+		}
+		if c.calls != v.expectedCalls {
+			t.Errorf("[%s]\nexpected calls : %d\nactual : %d", testname, v.expectedCalls, c.calls)
+		}
+		if !reflect.DeepEqual(v.expectedRegistryIDs, c.registryIDs) {
+			t.Errorf("[%s]\nexpected registryIds : %v\nactual : %v", testname, v.expectedRegistryIDs, c.registryIDs)
+		}
+	}
+}
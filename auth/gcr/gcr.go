@@ -0,0 +1,60 @@
+package gcr
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/xerrors"
+
+	"github.com/knqyf263/fanal/auth"
+	"github.com/knqyf263/fanal/token"
+)
+
+const (
+	// username is the user name for OAuth2 access tokens accepted by GCR and Artifact Registry.
+	username = "oauth2accesstoken"
+	// refreshMargin is how long before the expiry the token is refreshed.
+	refreshMargin = 60 * time.Second
+)
+
+var now = time.Now
+
+type credentialProvider struct {
+	ts oauth2.TokenSource
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// GCRCredentialProvider returns the provider of the credentials of GCR and Artifact Registry,
+// e.g. with the token source of google.FindDefaultCredentials. The access token is reused
+// until 60 seconds before it expires. The other registries get no credentials.
+func GCRCredentialProvider(ts oauth2.TokenSource) auth.CredentialProvider {
+	return &credentialProvider{ts: ts}
+}
+
+func (p *credentialProvider) Credentials(registryHost string) (string, string, error) {
+	if !token.IsGCR(registryHost) {
+		return "", "", nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token == nil || !p.valid() {
+		t, err := p.ts.Token()
+		if err != nil {
+			return "", "", xerrors.Errorf("failed to get the access token: %w", err)
+		}
+		p.token = t
+	}
+	return username, p.token.AccessToken, nil
+}
+
+// valid reports whether the token does not expire within the margin. Tokens without the expiry never expire.
+func (p *credentialProvider) valid() bool {
+	if p.token.AccessToken == "" {
+		return false
+	}
+	return p.token.Expiry.IsZero() || now().Add(refreshMargin).Before(p.token.Expiry)
+}
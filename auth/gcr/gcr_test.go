@@ -0,0 +1,53 @@
+package gcr
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type countingTokenSource struct {
+	calls  int
+	expiry time.Time
+}
+
+func (ts *countingTokenSource) Token() (*oauth2.Token, error) {
+	ts.calls++
+	return &oauth2.Token{AccessToken: "token", Expiry: ts.expiry}, nil
+}
+
+func TestGCRCredentialProvider(t *testing.T) {
+	defer func() { now = time.Now }()
+	issuedAt := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	ts := &countingTokenSource{expiry: issuedAt.Add(time.Hour)}
+	p := GCRCredentialProvider(ts)
+
+	var tests = []struct {
+		host             string
+		now              time.Time
+		expectedUsername string
+		expectedPassword string
+		expectedCalls    int
+	}{
+		{host: "gcr.io", now: issuedAt, expectedUsername: "oauth2accesstoken", expectedPassword: "token", expectedCalls: 1},
+		{host: "us-central1-docker.pkg.dev", now: issuedAt.Add(58 * time.Minute), expectedUsername: "oauth2accesstoken", expectedPassword: "token", expectedCalls: 1},
+		// within 60 seconds before the expiry
+		{host: "asia.gcr.io", now: issuedAt.Add(59*time.Minute + 30*time.Second), expectedUsername: "oauth2accesstoken", expectedPassword: "token", expectedCalls: 2},
+		// the token must not be sent to the other registries
+		{host: "index.docker.io", now: issuedAt, expectedCalls: 2},
+	}
+	for _, v := range tests {
+		now = func() time.Time { return v.now }
+		username, password, err := p.Credentials(v.host)
+		if err != nil {
+			t.Fatalf("[%s] unexpected error: %v", v.host, err)
+		}
+		if username != v.expectedUsername || password != v.expectedPassword {
+			t.Errorf("[%s]\nexpected : %s:%s\nactual : %s:%s", v.host, v.expectedUsername, v.expectedPassword, username, password)
+		}
+		if ts.calls != v.expectedCalls {
+			t.Errorf("[%s]\nexpected calls : %d\nactual : %d", v.host, v.expectedCalls, ts.calls)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package auth
+
+// Keychain resolves the credentials of registries, e.g. from the docker config or secret stores.
+// Keychains return no credentials without errors for the registries they do not know.
+type Keychain interface {
+	Resolve(registry string) (username, password string, err error)
+}
+
+// KeychainFunc adapts the function to Keychain.
+type KeychainFunc func(registry string) (username, password string, err error)
+
+// Resolve calls f(registry).
+func (f KeychainFunc) Resolve(registry string) (string, string, error) {
+	return f(registry)
+}
+
+// StaticKeychain returns the same credentials for all the registries.
+func StaticKeychain(username, password string) Keychain {
+	return KeychainFunc(func(string) (string, string, error) {
+		return username, password, nil
+	})
+}
+
+// ProviderKeychain adapts CredentialProvider to Keychain, e.g. ProviderKeychain(ecr.ECRCredentialProvider(...)).
+func ProviderKeychain(p CredentialProvider) Keychain {
+	return KeychainFunc(p.Credentials)
+}
+
+// MultiKeychain returns the credentials of the first keychain that resolves them.
+// The keychains after an error are still consulted, and the first error is returned if none of them resolves.
+func MultiKeychain(keychains ...Keychain) Keychain {
+	return KeychainFunc(func(registry string) (string, string, error) {
+		var firstErr error
+		for _, k := range keychains {
+			username, password, err := k.Resolve(registry)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if username != "" || password != "" {
+				return username, password, nil
+			}
+		}
+		return "", "", firstErr
+	})
+}
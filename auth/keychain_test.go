@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+type staticProvider struct{}
+
+func (staticProvider) Credentials(registryHost string) (string, string, error) {
+	if registryHost != "provider.example.com" {
+		return "", "", nil
+	}
+	return "provider", "provider-pass", nil
+}
+
+func TestMultiKeychain(t *testing.T) {
+	errKeychain := KeychainFunc(func(string) (string, string, error) {
+		return "", "", errors.New("error")
+	})
+	var tests = map[string]struct {
+		keychains        []Keychain
+		registry         string
+		expectedUsername string
+		expectedPassword string
+		wantErr          bool
+	}{
+		"first": {
+			keychains:        []Keychain{StaticKeychain("first", "first-pass"), StaticKeychain("second", "second-pass")},
+			expectedUsername: "first",
+			expectedPassword: "first-pass",
+		},
+		"skip empty": {
+			keychains:        []Keychain{StaticKeychain("", ""), ProviderKeychain(staticProvider{}), StaticKeychain("static", "static-pass")},
+			registry:         "provider.example.com",
+			expectedUsername: "provider",
+			expectedPassword: "provider-pass",
+		},
+		"skip error": {
+			keychains:        []Keychain{errKeychain, StaticKeychain("static", "static-pass")},
+			expectedUsername: "static",
+			expectedPassword: "static-pass",
+		},
+		"error": {
+			keychains: []Keychain{errKeychain, ProviderKeychain(staticProvider{})},
+			registry:  "other.example.com",
+			wantErr:   true,
+		},
+		"none": {
+			registry: "other.example.com",
+		},
+	}
+	for testname, v := range tests {
+		username, password, err := MultiKeychain(v.keychains...).Resolve(v.registry)
+		if (err != nil) != v.wantErr {
+			t.Errorf("[%s] unexpected error: %v", testname, err)
+		}
+		if username != v.expectedUsername || password != v.expectedPassword {
+			t.Errorf("[%s]\nexpected : %s:%s\nactual : %s:%s", testname, v.expectedUsername, v.expectedPassword, username, password)
+		}
+	}
+}
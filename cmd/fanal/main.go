@@ -9,18 +9,40 @@ import (
 
 	"github.com/knqyf263/fanal/analyzer"
 	_ "github.com/knqyf263/fanal/analyzer/library/bundler"
+	_ "github.com/knqyf263/fanal/analyzer/library/cargo"
+	_ "github.com/knqyf263/fanal/analyzer/library/cocoapods"
 	_ "github.com/knqyf263/fanal/analyzer/library/composer"
+	_ "github.com/knqyf263/fanal/analyzer/library/conan"
+	_ "github.com/knqyf263/fanal/analyzer/library/dotnet"
+	_ "github.com/knqyf263/fanal/analyzer/library/gemspec"
+	_ "github.com/knqyf263/fanal/analyzer/library/gradle"
+	"github.com/knqyf263/fanal/analyzer/library/jar"
+	_ "github.com/knqyf263/fanal/analyzer/library/mix"
+	_ "github.com/knqyf263/fanal/analyzer/library/nodepkg"
 	_ "github.com/knqyf263/fanal/analyzer/library/npm"
+	_ "github.com/knqyf263/fanal/analyzer/library/nuget"
+	_ "github.com/knqyf263/fanal/analyzer/library/pip"
 	_ "github.com/knqyf263/fanal/analyzer/library/pipenv"
+	_ "github.com/knqyf263/fanal/analyzer/library/poetry"
+	_ "github.com/knqyf263/fanal/analyzer/library/pom"
+	_ "github.com/knqyf263/fanal/analyzer/library/pub"
+	_ "github.com/knqyf263/fanal/analyzer/library/pythonpkg"
+	_ "github.com/knqyf263/fanal/analyzer/library/swift"
 	_ "github.com/knqyf263/fanal/analyzer/os/alpine"
 	_ "github.com/knqyf263/fanal/analyzer/os/amazonlinux"
 	_ "github.com/knqyf263/fanal/analyzer/os/debian"
+	_ "github.com/knqyf263/fanal/analyzer/os/distroless"
+	_ "github.com/knqyf263/fanal/analyzer/os/flatcar"
 	_ "github.com/knqyf263/fanal/analyzer/os/opensuse"
 	_ "github.com/knqyf263/fanal/analyzer/os/redhatbase"
 	_ "github.com/knqyf263/fanal/analyzer/os/ubuntu"
+	_ "github.com/knqyf263/fanal/analyzer/os/windows"
 	_ "github.com/knqyf263/fanal/analyzer/pkg/apk"
 	_ "github.com/knqyf263/fanal/analyzer/pkg/dpkg"
+	_ "github.com/knqyf263/fanal/analyzer/pkg/flatpak"
 	_ "github.com/knqyf263/fanal/analyzer/pkg/rpm"
+	_ "github.com/knqyf263/fanal/analyzer/pkg/snap"
+	_ "github.com/knqyf263/fanal/analyzer/pkg/windows"
 	"github.com/knqyf263/fanal/extractor"
 	"golang.org/x/crypto/ssh/terminal"
 )
@@ -34,6 +56,7 @@ func main() {
 func run() (err error) {
 	ctx := context.Background()
 	tarPath := flag.String("f", "-", "layer.tar path")
+	flag.BoolVar(&jar.Enabled, "jar", false, "analyze Java archives")
 	flag.Parse()
 
 	args := flag.Args()
@@ -68,11 +91,15 @@ func run() (err error) {
 	}
 	fmt.Printf("Packages: %d\n", len(pkgs))
 
-	libs, err := analyzer.GetLibraries(files)
+	findings, libErrs, err := analyzer.GetLibraryFindingsWithErrors(files)
 	if err != nil {
-		return err
+		log.Print(err)
+	}
+	for name, err := range libErrs {
+		// Show the libraries detected by the other analyzers
+		log.Printf("%s: %s", name, err)
 	}
-	for filepath, libList := range libs {
+	for filepath, libList := range analyzer.FindingsToLibraries(findings) {
 		fmt.Printf("%s: %d\n", filepath, len(libList))
 	}
 	return nil
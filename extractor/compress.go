@@ -0,0 +1,99 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"golang.org/x/xerrors"
+)
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decompressStream returns the decompressed stream if it is compressed with gzip, bzip2, xz or zstd,
+// e.g. "docker save alpine | gzip > alpine.tar.gz". Otherwise the stream is returned as it is.
+func decompressStream(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(xzMagic))
+	if err != nil && err != io.EOF {
+		return nil, xerrors.Errorf("failed to read the header: %w", err)
+	}
+
+	if bytes.HasPrefix(magic, bzip2Magic) {
+		return ioutil.NopCloser(bzip2.NewReader(br)), nil
+	}
+	return decompress(br)
+}
+
+// decompress returns the decompressed layer if it is compressed with gzip, xz or zstd, which is detected by
+// the magic bytes, e.g. the layers in the archive of "docker save". Otherwise the layer is returned as it is.
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(xzMagic))
+	if err != nil && err != io.EOF {
+		return nil, xerrors.Errorf("failed to read the header: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return newGzipReader(br)
+	case bytes.HasPrefix(magic, xzMagic):
+		return newXZReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		return newZstdReader(br)
+	}
+	return ioutil.NopCloser(br), nil
+}
+
+// decompressLayer returns the decompressed layer by the media type, e.g. application/vnd.oci.image.layer.v1.tar+zstd
+// and application/vnd.oci.image.layer.v1.tar without compression. The compression of the other media types,
+// e.g. xz of old tools, is detected by the magic bytes.
+func decompressLayer(r io.Reader, mediaType string) (io.ReadCloser, error) {
+	switch {
+	case strings.HasSuffix(mediaType, "+gzip"), strings.HasSuffix(mediaType, ".tar.gzip"):
+		return newGzipReader(r)
+	case strings.HasSuffix(mediaType, "+zstd"):
+		return newZstdReader(r)
+	case strings.HasSuffix(mediaType, "+xz"):
+		return newXZReader(r)
+	case strings.HasSuffix(mediaType, ".tar"):
+		return ioutil.NopCloser(r), nil
+	}
+	return decompress(r)
+}
+
+func newGzipReader(r io.Reader) (io.ReadCloser, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid gzip: %w", err)
+	}
+	return gzipReader, nil
+}
+
+func newXZReader(r io.Reader) (io.ReadCloser, error) {
+	xzReader, err := xz.NewReader(r)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid xz: %w", err)
+	}
+	return ioutil.NopCloser(xzReader), nil
+}
+
+// newZstdReader returns the zstd decoder, which must be closed to stop its goroutines.
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid zstd: %w", err)
+	}
+	return decoder.IOReadCloser(), nil
+}
@@ -0,0 +1,98 @@
+package extractor
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// tarLayer returns the tar archive of the files.
+func tarLayer(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// compressLayer compresses the layer with "gzip", "xz" or "zstd". The layer is returned as it is otherwise.
+func compressLayer(t *testing.T, layer []byte, compression string) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch compression {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "xz":
+		w, err = xz.NewWriter(&buf)
+	case "zstd":
+		w, err = zstd.NewWriter(&buf)
+	default:
+		return layer
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(layer); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompressLayer(t *testing.T) {
+	layer := tarLayer(t, map[string]string{"etc/test/foo": "foo\n"})
+	var tests = map[string]struct {
+		compression string
+		mediaType   string
+		err         bool
+	}{
+		"Uncompressed":        {mediaType: "application/vnd.oci.image.layer.v1.tar"},
+		"Gzip":                {compression: "gzip", mediaType: "application/vnd.oci.image.layer.v1.tar+gzip"},
+		"DockerGzip":          {compression: "gzip", mediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip"},
+		"Zstd":                {compression: "zstd", mediaType: "application/vnd.oci.image.layer.v1.tar+zstd"},
+		"XZ":                  {compression: "xz", mediaType: "application/vnd.oci.image.layer.v1.tar+xz"},
+		"SniffedXZ":           {compression: "xz"},
+		"SniffedZstd":         {compression: "zstd", mediaType: "application/octet-stream"},
+		"SniffedUncompressed": {},
+		"WrongMediaType":      {compression: "zstd", mediaType: "application/vnd.oci.image.layer.v1.tar+gzip", err: true},
+	}
+	for testName, v := range tests {
+		r, err := decompressLayer(bytes.NewReader(compressLayer(t, layer, v.compression)), v.mediaType)
+		if v.err {
+			if err == nil {
+				t.Errorf("[%s] an error is expected", testName)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+			continue
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+		}
+		if !bytes.Equal(layer, b) {
+			t.Errorf("[%s] the layer is not decompressed", testName)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package extractor
+
+import (
+	"context"
+
+	"github.com/docker/docker/client"
+	"golang.org/x/xerrors"
+)
+
+// ErrNoSuchContainer occurs when the container doesn't exist in the daemon.
+var ErrNoSuchContainer = xerrors.New("no such container")
+
+// ExtractFromContainer extracts files from the filesystem of the container including its writable layer,
+// e.g. packages installed at runtime. The image of the container doesn't have to exist any more.
+// Stopped and paused containers can be exported as well as running ones.
+func (d DockerExtractor) ExtractFromContainer(ctx context.Context, containerID string, filenames []string) (FileMap, error) {
+	c, err := d.createDockerClient()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to initialize docker client: %w", err)
+	}
+
+	container, err := c.ContainerInspect(ctx, containerID)
+	if client.IsErrNotFound(err) {
+		return nil, xerrors.Errorf("%s: %w", containerID, ErrNoSuchContainer)
+	} else if err != nil {
+		return nil, xerrors.Errorf("failed to inspect the container: %w", err)
+	}
+
+	rc, err := c.ContainerExport(ctx, container.ID)
+	if err != nil {
+		// e.g. an authorization plugin of the remote daemon denies the export
+		return nil, xerrors.Errorf("failed to export the container %s, the daemon may not permit the export: %w",
+			containerID, err)
+	}
+	defer rc.Close()
+
+	// The exported archive is the merged filesystem without whiteouts
+	fileMap, _, err := d.ExtractFiles(rc, filenames)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to extract files from the container: %w", err)
+	}
+	return fileMap, nil
+}
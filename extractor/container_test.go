@@ -0,0 +1,87 @@
+package extractor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/xerrors"
+)
+
+func TestExtractFromContainer(t *testing.T) {
+	archive := writeTar(t, []tarEntry{
+		{name: "etc/os-release", mode: 0644, content: "ID=alpine\n"},
+		{name: "lib/apk/db/installed", mode: 0644, content: "P:musl\n"},
+		{name: "usr/bin/foo", mode: 0755, content: "foo"},
+	})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/containers/running/json"),
+			strings.HasSuffix(r.URL.Path, "/containers/forbidden/json"):
+			id := path.Base(path.Dir(r.URL.Path))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"Id":"` + id + `","State":{"Status":"paused","Paused":true}}`))
+		case strings.HasSuffix(r.URL.Path, "/containers/running/export"):
+			w.Write(archive)
+		case strings.HasSuffix(r.URL.Path, "/containers/forbidden/export"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"authorization denied by plugin"}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No such container"}`))
+		}
+	}))
+	defer ts.Close()
+
+	var tests = map[string]struct {
+		containerID string
+		fileMap     FileMap
+		err         error
+		errMessage  string
+	}{
+		"paused": {
+			containerID: "running",
+			fileMap: MapFileMap{
+				"etc/os-release":       []byte("ID=alpine\n"),
+				"lib/apk/db/installed": []byte("P:musl\n"),
+			},
+		},
+		"not found": {
+			containerID: "missing",
+			err:         ErrNoSuchContainer,
+		},
+		"export denied": {
+			containerID: "forbidden",
+			errMessage:  "the daemon may not permit the export",
+		},
+	}
+	for testname, v := range tests {
+		d := NewDockerExtractor(WithDockerOption(DockerOption{DockerHost: "tcp://" + ts.Listener.Addr().String()}))
+		actual, err := d.ExtractFromContainer(context.Background(), v.containerID,
+			[]string{"etc/os-release", "lib/apk/db/installed"})
+		if v.err != nil || v.errMessage != "" {
+			if err == nil {
+				t.Errorf("[%s]\nexpected error but got nil", testname)
+			} else if v.err != nil && !xerrors.Is(err, v.err) {
+				t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.err, err)
+			} else if v.errMessage != "" && !strings.Contains(err.Error(), v.errMessage) {
+				t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.errMessage, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s]\nunexpected error: %v", testname, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, v.fileMap) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.fileMap, actual)
+		}
+	}
+}
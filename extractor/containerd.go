@@ -0,0 +1,174 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultContainerdAddress is the default address of the containerd socket
+	DefaultContainerdAddress = "/run/containerd/containerd.sock"
+	// DefaultContainerdNamespace is the namespace used by Kubernetes (CRI plugin)
+	DefaultContainerdNamespace = "k8s.io"
+)
+
+// ContainerdExtractor extracts files from images stored in containerd with the ctr command, not the containerd API.
+// The image is resolved with "ctr images list" in the namespace and exported with "ctr images export",
+// so containerd takes care of the leases of the blobs during the export.
+type ContainerdExtractor struct {
+	Address   string
+	Namespace string
+	docker    DockerExtractor
+}
+
+// NewContainerdExtractor returns the extractor of the images in the namespace of containerd at the address.
+// The ctr binary must be in PATH and have access to the containerd socket, which usually requires root.
+func NewContainerdExtractor(address string, namespace string) Extractor {
+	return newContainerdExtractor(address, namespace, NewDockerExtractor())
+}
+
+func newContainerdExtractor(address string, namespace string, docker DockerExtractor) ContainerdExtractor {
+	if address == "" {
+		address = DefaultContainerdAddress
+	}
+	if namespace == "" {
+		namespace = DefaultContainerdNamespace
+	}
+	return ContainerdExtractor{
+		Address:   address,
+		Namespace: namespace,
+		docker:    docker,
+	}
+}
+
+// useContainerd reports whether images should be read from containerd before the registry. It is true when
+// ContainerdAddress is set, or with LocalImage when the containerd socket exists and the Docker socket doesn't,
+// e.g. on Kubernetes nodes without Docker and Podman. It is always false without ctr in PATH.
+func useContainerd(option DockerOption) bool {
+	if _, err := exec.LookPath("ctr"); err != nil {
+		return false
+	}
+	if option.ContainerdAddress != "" {
+		return true
+	}
+	if !option.LocalImage || daemonHost(option) != "" {
+		return false
+	}
+	_, err := os.Stat(DefaultContainerdAddress)
+	return err == nil
+}
+
+func (c ContainerdExtractor) Extract(ctx context.Context, imageName string, filenames []string) (FileMap, error) {
+	fileMap, _, err := c.extractWithMetadata(ctx, imageName, filenames)
+	return fileMap, err
+}
+
+func (c ContainerdExtractor) extractWithMetadata(ctx context.Context, imageName string, filenames []string) (FileMap, ImageMetadata, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// ctr requires the fully qualified name, e.g. docker.io/library/alpine:latest
+	ref, err := c.resolve(ctx, imageName)
+	if err != nil {
+		return nil, ImageMetadata{}, err
+	}
+
+	cmd := c.command(ctx, "images", "export", "-", ref)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, ImageMetadata{}, xerrors.Errorf("failed to open stdout: %w", err)
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, ImageMetadata{}, xerrors.Errorf("failed to export the image: %w", err)
+	}
+
+	fileMap, metadata, err := c.docker.ExtractFromFileWithMetadata(ctx, stdout, filenames)
+	if err != nil {
+		// drain the rest so that ctr can exit
+		io.Copy(ioutil.Discard, stdout)
+		cmd.Wait()
+		return nil, ImageMetadata{}, err
+	}
+	if err = cmd.Wait(); err != nil {
+		return nil, ImageMetadata{}, xerrors.Errorf("failed to export the image: %s: %w", stderr.String(), err)
+	}
+	return fileMap, metadata, nil
+}
+
+func (c ContainerdExtractor) ExtractFromFile(ctx context.Context, r io.ReadCloser, filenames []string) (FileMap, error) {
+	return c.docker.ExtractFromFile(ctx, r, filenames)
+}
+
+func (c ContainerdExtractor) command(ctx context.Context, args ...string) *exec.Cmd {
+	args = append([]string{"--address", c.Address, "--namespace", c.Namespace}, args...)
+	return exec.CommandContext(ctx, "ctr", args...)
+}
+
+// resolve returns the reference name of the image in the namespace.
+func (c ContainerdExtractor) resolve(ctx context.Context, imageName string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := c.command(ctx, "images", "list")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", xerrors.Errorf("failed to list images: %s: %w", stderr.String(), err)
+	}
+
+	images, err := parseImageList(bytes.NewReader(out))
+	if err != nil {
+		return "", err
+	}
+	for _, name := range []string{imageName, normalizeImageName(imageName)} {
+		if _, ok := images[name]; ok {
+			return name, nil
+		}
+	}
+	return "", xerrors.Errorf("no such image in the namespace %s: %s", c.Namespace, imageName)
+}
+
+// parseImageList parses the output of "ctr images list", e.g.
+// REF                             TYPE                                                 DIGEST                  SIZE    PLATFORMS   LABELS
+// docker.io/library/alpine:3.9    application/vnd.docker.distribution.manifest.list... sha256:769fddc7cc2f... 2.6 MiB linux/amd64 -
+func parseImageList(r io.Reader) (map[string]digest.Digest, error) {
+	images := map[string]digest.Digest{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] == "REF" {
+			continue
+		}
+		dgst := digest.Digest(fields[2])
+		if err := dgst.Validate(); err != nil {
+			continue
+		}
+		images[fields[0]] = dgst
+	}
+	return images, scanner.Err()
+}
+
+// normalizeImageName returns the fully qualified name stored in containerd,
+// e.g. "alpine" => "docker.io/library/alpine:latest"
+func normalizeImageName(imageName string) string {
+	name := imageName
+	if i := strings.Index(name, "/"); i < 0 {
+		name = "docker.io/library/" + name
+	} else if domain := name[:i]; !strings.ContainsAny(domain, ".:") && domain != "localhost" {
+		name = "docker.io/" + name
+	}
+	if !strings.Contains(name, "@") && !strings.Contains(name[strings.LastIndex(name, "/")+1:], ":") {
+		name += ":latest"
+	}
+	return name
+}
@@ -0,0 +1,173 @@
+package extractor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+func TestParseImageList(t *testing.T) {
+	out := `REF                          TYPE                                                      DIGEST                                                                  SIZE    PLATFORMS   LABELS
+docker.io/library/alpine:3.9 application/vnd.docker.distribution.manifest.list.v2+json sha256:769fddc7cc2f0a1c35abb2f91432e8beecf83916c421420e6a6da9f8975464b6 2.6 MiB linux/386,linux/amd64 io.cri-containerd.image=managed
+sha256:055936d3920576da37aa9bc460d70c5f212028bda1c08c0879aedf03d7a66ea1 application/vnd.docker.distribution.manifest.v2+json sha256:e7a56131d409fa318e6f5570be244cee963176f3f90942298eefbf1ed3245488 2.6 MiB linux/amd64 io.cri-containerd.image=managed
+broken
+`
+	images, err := parseImageList(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("catch the error : %v", err)
+	}
+	expected := map[string]digest.Digest{
+		"docker.io/library/alpine:3.9":                                            "sha256:769fddc7cc2f0a1c35abb2f91432e8beecf83916c421420e6a6da9f8975464b6",
+		"sha256:055936d3920576da37aa9bc460d70c5f212028bda1c08c0879aedf03d7a66ea1": "sha256:e7a56131d409fa318e6f5570be244cee963176f3f90942298eefbf1ed3245488",
+	}
+	if !reflect.DeepEqual(expected, images) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, images)
+	}
+}
+
+func TestNormalizeImageName(t *testing.T) {
+	var tests = map[string]string{
+		"alpine":                       "docker.io/library/alpine:latest",
+		"alpine:3.9":                   "docker.io/library/alpine:3.9",
+		"knqyf263/fanal":               "docker.io/knqyf263/fanal:latest",
+		"gcr.io/distroless/base":       "gcr.io/distroless/base:latest",
+		"localhost:5000/app:1.0":       "localhost:5000/app:1.0",
+		"localhost/app":                "localhost/app:latest",
+		"alpine@sha256:769fddc7cc2f0a": "docker.io/library/alpine@sha256:769fddc7cc2f0a",
+	}
+	for imageName, expected := range tests {
+		actual := normalizeImageName(imageName)
+		if actual != expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", imageName, expected, actual)
+		}
+	}
+}
+
+func TestUseContainerd(t *testing.T) {
+	var tests = map[string]struct {
+		ctr      bool
+		option   DockerOption
+		expected bool
+	}{
+		"ContainerdAddress": {
+			ctr:      true,
+			option:   DockerOption{ContainerdAddress: "/run/k3s/containerd/containerd.sock"},
+			expected: true,
+		},
+		"NoCtr": {
+			option:   DockerOption{ContainerdAddress: "/run/k3s/containerd/containerd.sock"},
+			expected: false,
+		},
+		"NoLocalImage": {
+			ctr:      true,
+			option:   DockerOption{},
+			expected: false,
+		},
+		"DockerHost": {
+			ctr:      true,
+			option:   DockerOption{LocalImage: true, DockerHost: "unix:///nonexistent.sock"},
+			expected: false,
+		},
+	}
+	for testname, v := range tests {
+		file := ""
+		if v.ctr {
+			file = "testdata/containerd.tar"
+		}
+		restore := fakeCtr(t, file, "docker.io/library/test:latest")
+		actual := useContainerd(v.option)
+		restore()
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.expected, actual)
+		}
+	}
+}
+
+// fakeCtr puts the ctr command exporting the image in the tar file as the reference name in PATH.
+// Without the file, PATH has no ctr. The returned function restores PATH.
+func fakeCtr(t *testing.T, file, ref string) func() {
+	// cat is looked up before PATH is replaced
+	cat, err := exec.LookPath("cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "ctr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	restore := func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+	if file == "" {
+		return restore
+	}
+
+	file, err = filepath.Abs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ctr --address <address> --namespace <namespace> images list|export - <ref>
+	script := `#!/bin/sh
+case "$5 $6" in
+"images list")
+	echo "REF TYPE DIGEST SIZE PLATFORMS LABELS"
+	echo "` + ref + ` application/vnd.oci.image.index.v1+json ` + containerdManifest + ` 274 B linux/amd64 -"
+	;;
+"images export")
+	[ "$8" = "` + ref + `" ] || { echo "image not found: $8" >&2; exit 1; }
+	exec ` + cat + ` "` + file + `"
+	;;
+*)
+	exit 1
+	;;
+esac
+`
+	if err = ioutil.WriteFile(filepath.Join(dir, "ctr"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return restore
+}
+
+func TestContainerdExtractor_Extract(t *testing.T) {
+	defer fakeCtr(t, "testdata/containerd.tar", "docker.io/library/test:latest")()
+
+	var tests = map[string]struct {
+		imageName string
+		expected  FileMap
+		wantErr   bool
+	}{
+		"ShortName": {
+			imageName: "test",
+			expected:  MapFileMap{"etc/test/bar": []byte("bar\n")},
+		},
+		"FullName": {
+			imageName: "docker.io/library/test:latest",
+			expected:  MapFileMap{"etc/test/bar": []byte("bar\n")},
+		},
+		"NoSuchImage": {
+			imageName: "alpine",
+			wantErr:   true,
+		},
+	}
+	c := NewContainerdExtractor("", "")
+	for testName, v := range tests {
+		actual, err := c.Extract(context.Background(), v.imageName, []string{"etc/test/bar"})
+		if (err != nil) != v.wantErr {
+			t.Errorf("[%s] unexpected error : %v", testName, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.expected, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
@@ -1,10 +1,26 @@
 package extractor
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"golang.org/x/xerrors"
 )
 
 func TestExtractFromFile(t *testing.T) {
@@ -17,30 +33,43 @@ func TestExtractFromFile(t *testing.T) {
 		{
 			file:      "testdata/image1.tar",
 			filenames: []string{"var/foo", "etc/test/bar"},
-			fileMap:   FileMap{"etc/test/bar": []byte("bar\n")},
+			fileMap:   MapFileMap{"etc/test/bar": []byte("bar\n")},
 			err:       nil,
 		},
 		{
 			file:      "testdata/image2.tar",
 			filenames: []string{"home/app/Gemfile", "home/app2/Gemfile"},
-			fileMap:   FileMap{"home/app2/Gemfile": []byte("gem")},
+			fileMap:   MapFileMap{"home/app2/Gemfile": []byte("gem")},
 			err:       nil,
 		},
 		{
 			file:      "testdata/image3.tar",
 			filenames: []string{"home/app/Gemfile", "home/app2/Pipfile", "home/app/Pipfile"},
-			fileMap:   FileMap{"home/app/Pipfile": []byte("pip")},
+			fileMap:   MapFileMap{"home/app/Pipfile": []byte("pip")},
 			err:       nil,
 		},
 		{
 			file:      "testdata/image4.tar",
 			filenames: []string{".abc", ".def", "foo/.abc", "foo/.def", ".foo/.abc"},
-			fileMap: FileMap{
+			fileMap: MapFileMap{
 				".def":     []byte("def"),
 				"foo/.abc": []byte("abc"),
 			},
 			err: nil,
 		},
+		{
+			file:      "testdata/containerd.tar",
+			filenames: []string{"var/foo", "etc/test/bar"},
+			fileMap:   MapFileMap{"etc/test/bar": []byte("bar\n")},
+			err:       nil,
+		},
+		{
+			// The gzip layer and the zstd layer
+			file:      "testdata/zstd.tar",
+			filenames: []string{"etc/test/foo", "etc/test/bar"},
+			fileMap:   MapFileMap{"etc/test/foo": []byte("foo\n"), "etc/test/bar": []byte("bar\n")},
+			err:       nil,
+		},
 	}
 
 	for _, v := range vectors {
@@ -63,6 +92,120 @@ func TestExtractFromFile(t *testing.T) {
 	}
 }
 
+func TestExtractFromFile_MixedCompression(t *testing.T) {
+	// The layers of docker save compressed by other tools, each overwriting etc/test/bar
+	files := map[string][]byte{}
+	var layerPaths []string
+	for i, compression := range []string{"", "gzip", "xz", "zstd"} {
+		name := "etc/test/" + compression
+		if compression == "" {
+			name = "etc/test/raw"
+		}
+		layerPath := strconv.Itoa(i) + "/layer.tar"
+		files[layerPath] = compressLayer(t, tarLayer(t, map[string]string{name: "ok\n", "etc/test/bar": name + "\n"}), compression)
+		layerPaths = append(layerPaths, layerPath)
+	}
+	files["manifest.json"] = []byte(`[{"Config": "config.json", "Layers": ["` + strings.Join(layerPaths, `", "`) + `"]}]`)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range append(layerPaths, "manifest.json") {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name]))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	filenames := []string{"etc/test/raw", "etc/test/gzip", "etc/test/xz", "etc/test/zstd", "etc/test/bar"}
+	fileMap, err := DockerExtractor{}.ExtractFromFile(context.Background(), ioutil.NopCloser(&buf), filenames)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := MapFileMap{
+		"etc/test/raw":  []byte("ok\n"),
+		"etc/test/gzip": []byte("ok\n"),
+		"etc/test/xz":   []byte("ok\n"),
+		"etc/test/zstd": []byte("ok\n"),
+		"etc/test/bar":  []byte("etc/test/zstd\n"),
+	}
+	if !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+}
+
+func TestExtractFromFileWithMetadata(t *testing.T) {
+	var tests = map[string]struct {
+		file              string
+		expected          ImageMetadata
+		expectedCreatedBy []string
+	}{
+		"DockerSave": {
+			file: "testdata/image1.tar",
+			expected: ImageMetadata{
+				ID: "sha256:d1cea7b7e18c216254f32eb618a49e57943d7d85919dd882935748479816a784",
+				DiffIDs: []string{
+					"sha256:d9ff549177a94a413c425ffe14ae1cc0aa254bc9c7df781add08e7d2fba25d27",
+					"sha256:f75441026d68038ca80e92f342fb8f3c0f1faeec67b5a80c98f033a65beaef5a",
+					"sha256:a8b87ccf2f2f94b9e23308560800afa3f272aa6db5cc7d9b0119b6843889cff2",
+				},
+				// The layers are not compressed
+				Layers: []LayerDescriptor{
+					{Digest: "sha256:d9ff549177a94a413c425ffe14ae1cc0aa254bc9c7df781add08e7d2fba25d27", Size: 4670976, UncompressedSize: 4670976, FileCount: 476},
+					{Digest: "sha256:f75441026d68038ca80e92f342fb8f3c0f1faeec67b5a80c98f033a65beaef5a", Size: 3584, UncompressedSize: 3584, FileCount: 5},
+					{Digest: "sha256:a8b87ccf2f2f94b9e23308560800afa3f272aa6db5cc7d9b0119b6843889cff2", Size: 4608, UncompressedSize: 4608, FileCount: 6},
+				},
+				TotalSizeBytes: 4679168,
+			},
+			expectedCreatedBy: []string{
+				"/bin/sh -c #(nop) ADD file:38bc6b51693b13d84a63e281403e2f6d0218c44b1d7ff12157c4523f9f0ebb1e in / ",
+				`/bin/sh -c #(nop)  CMD ["/bin/sh"]`,
+				"/bin/sh -c mkdir /etc/test && touch /var/foo && touch /etc/test/test",
+				"/bin/sh -c rm /var/foo && rm -rf /etc/test && mkdir /etc/test && echo bar > /etc/test/bar",
+			},
+		},
+		"Containerd": {
+			file: "testdata/containerd.tar",
+			expected: ImageMetadata{
+				ID:      "sha256:519bbf4427ce65cd05f107f39ad9b781e44ef756339f8343303f898f41e110d0",
+				DiffIDs: []string{},
+				Layers: []LayerDescriptor{
+					{Digest: "sha256:e9b1909021e1375b4552859ecff74035949ccaf74a9e95088e9c18c83d83a7c9", Size: 162, UncompressedSize: 10240, FileCount: 5},
+					{Digest: "sha256:3df5235938f1680968e95fc28d098ea16eb1dd2717e4fa9094a57fa7e92a6094", Size: 112, UncompressedSize: 10240, FileCount: 2},
+				},
+				TotalSizeBytes: 274,
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.file)
+		if err != nil {
+			t.Fatalf("Open() error: %v", err)
+		}
+		d := DockerExtractor{}
+		_, metadata, err := d.ExtractFromFileWithMetadata(nil, f, []string{"etc/test/bar"})
+		f.Close()
+		if err != nil {
+			t.Errorf("[%s] catch the error : %v", testName, err)
+		}
+		var createdBy []string
+		for _, h := range metadata.History {
+			createdBy = append(createdBy, h.CreatedBy)
+		}
+		if !reflect.DeepEqual(createdBy, v.expectedCreatedBy) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expectedCreatedBy, createdBy)
+		}
+		metadata.History = nil
+		if !reflect.DeepEqual(metadata, v.expected) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, metadata)
+		}
+	}
+}
+
 func TestExtractFiles(t *testing.T) {
 	vectors := []struct {
 		file      string   // Test input file
@@ -74,14 +217,14 @@ func TestExtractFiles(t *testing.T) {
 		{
 			file:      "testdata/normal.tar",
 			filenames: []string{"var/foo"},
-			fileMap:   FileMap{"var/foo": []byte{}},
+			fileMap:   MapFileMap{"var/foo": []byte{}},
 			opqDirs:   []string{},
 			err:       nil,
 		},
 		{
 			file:      "testdata/opq.tar",
 			filenames: []string{"var/foo"},
-			fileMap: FileMap{
+			fileMap: MapFileMap{
 				"var/.wh.foo": []byte{},
 			},
 			opqDirs: []string{"etc/test"},
@@ -90,7 +233,27 @@ func TestExtractFiles(t *testing.T) {
 		{
 			file:      "testdata/opq2.tar",
 			filenames: []string{"var/foo", "etc/test/bar"},
-			fileMap: FileMap{
+			fileMap: MapFileMap{
+				"etc/test/bar": []byte("bar\n"),
+				"var/.wh.foo":  []byte{},
+			},
+			opqDirs: []string{"etc/test"},
+			err:     nil,
+		},
+		{
+			file:      "testdata/opq2.tar",
+			filenames: []string{"etc/test/"},
+			fileMap: MapFileMap{
+				"etc/test/bar": []byte("bar\n"),
+				"var/.wh.foo":  []byte{},
+			},
+			opqDirs: []string{"etc/test"},
+			err:     nil,
+		},
+		{
+			file:      "testdata/opq2.tar",
+			filenames: []string{"etc/*/bar"},
+			fileMap: MapFileMap{
 				"etc/test/bar": []byte("bar\n"),
 				"var/.wh.foo":  []byte{},
 			},
@@ -121,3 +284,842 @@ func TestExtractFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractFiles_HardLink(t *testing.T) {
+	f, err := os.Open("testdata/hardlink.tar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	filenames := []string{"etc/os-release", "etc/centos-release", "etc/redhat-release", "etc/alpine-release", "lib/apk/db/installed"}
+	fileMap, _, err := DockerExtractor{}.ExtractFiles(f, filenames)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// lib/apk/db/installed is not resolved as the target is in the lower layers
+	expected := MapFileMap{
+		"etc/os-release":     []byte("ID=alpine\n"),
+		"etc/centos-release": []byte("CentOS Linux release 7.6.1810 (Core)\n"),
+		"etc/redhat-release": []byte("CentOS Linux release 7.6.1810 (Core)\n"),
+		"etc/alpine-release": []byte("3.10.2\n"),
+	}
+	if !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+}
+
+func TestExtractFromFile_HardLinkAcrossLayers(t *testing.T) {
+	f, err := os.Open("testdata/hardlink2.tar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fileMap, err := DockerExtractor{}.ExtractFromFile(context.Background(), f, []string{"etc/os-release"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The link to usr/lib/os-release in the lower layer is not resolved
+	if expected := (MapFileMap{}); !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+}
+
+func TestExtractFromFile_Symlink(t *testing.T) {
+	f, err := os.Open("testdata/symlink.tar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	filenames := []string{"etc/os-release", "etc/fedora-release", "etc/redhat-release", "etc/lsb-release",
+		"etc/centos-release", "etc/alpine-release"}
+	fileMap, err := DockerExtractor{}.ExtractFromFile(context.Background(), f, filenames)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// usr/lib/os-release is updated in the last layer. etc/centos-release is dangling and etc/alpine-release is a loop.
+	expected := MapFileMap{
+		"etc/os-release":     []byte("ID=fedora\nVERSION_ID=32\n"),
+		"etc/fedora-release": []byte("Fedora release 31 (Thirty One)\n"),
+		"etc/redhat-release": []byte("Fedora release 31 (Thirty One)\n"),
+		"etc/lsb-release":    []byte("DISTRIB_ID=Fedora\n"),
+	}
+	if !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+}
+
+func TestResolveSymlink(t *testing.T) {
+	symlinks := map[string]symlink{
+		"lib":                {target: "usr/lib"},
+		"etc/os-release":     {target: "usr/lib/os-release"},
+		"etc/lsb-release":    {target: "lib/lsb-release"},
+		"etc/system-release": {target: "etc/redhat-release"},
+		"etc/redhat-release": {target: "etc/fedora-release"},
+		"etc/loop":           {target: "etc/loop"},
+	}
+	// etc/chain/0 -> etc/chain/1 -> ... -> etc/chain/41
+	for i := 0; i <= maxSymlinks; i++ {
+		symlinks[fmt.Sprintf("etc/chain/%d", i)] = symlink{target: fmt.Sprintf("etc/chain/%d", i+1)}
+	}
+	var tests = map[string]struct {
+		filePath string
+		expected string
+		ok       bool
+	}{
+		"NotLink":         {filePath: "usr/lib/os-release", expected: "usr/lib/os-release", ok: true},
+		"Link":            {filePath: "etc/os-release", expected: "usr/lib/os-release", ok: true},
+		"Chain":           {filePath: "etc/system-release", expected: "etc/fedora-release", ok: true},
+		"Directory":       {filePath: "lib/apk/db/installed", expected: "usr/lib/apk/db/installed", ok: true},
+		"LinkInDirectory": {filePath: "etc/lsb-release", expected: "usr/lib/lsb-release", ok: true},
+		"MaxDepth":        {filePath: "etc/chain/1", expected: "etc/chain/41", ok: true},
+		"TooDeep":         {filePath: "etc/chain/0"},
+		"Loop":            {filePath: "etc/loop"},
+	}
+	for testName, v := range tests {
+		actual, ok := resolveSymlink(v.filePath, symlinks)
+		if ok != v.ok || actual != v.expected {
+			t.Errorf("[%s]\nexpected : %s, %v\nactual : %s, %v", testName, v.expected, v.ok, actual, ok)
+		}
+	}
+}
+
+func TestSymlinkTarget(t *testing.T) {
+	var tests = map[string]struct {
+		linkPath string
+		linkname string
+		expected string
+	}{
+		"Relative":    {linkPath: "etc/os-release", linkname: "../usr/lib/os-release", expected: "usr/lib/os-release"},
+		"SameDir":     {linkPath: "etc/redhat-release", linkname: "fedora-release", expected: "etc/fedora-release"},
+		"Absolute":    {linkPath: "etc/os-release", linkname: "/usr/lib/os-release", expected: "usr/lib/os-release"},
+		"AboveRoot":   {linkPath: "etc/os-release", linkname: "../../../usr/lib/os-release", expected: "usr/lib/os-release"},
+		"RootDirLink": {linkPath: "lib", linkname: "usr/lib", expected: "usr/lib"},
+	}
+	for testName, v := range tests {
+		if actual := symlinkTarget(v.linkPath, v.linkname); actual != v.expected {
+			t.Errorf("[%s]\nexpected : %s\nactual : %s", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestExtractFromFile_Whiteout(t *testing.T) {
+	var tests = map[string]struct {
+		file     string
+		expected FileMap
+	}{
+		// var/lib/dpkg/status is removed in the last layer
+		"Removed": {
+			file:     "testdata/whiteout.tar",
+			expected: MapFileMap{"etc/debian_version": []byte("10.1\n")},
+		},
+		// var/lib/dpkg/status is removed and added again in the next layer
+		"ReAdded": {
+			file: "testdata/whiteout2.tar",
+			expected: MapFileMap{
+				"etc/debian_version":  []byte("10.1\n"),
+				"var/lib/dpkg/status": []byte("Package: dash\nStatus: install ok installed\nVersion: 0.5.10.2-5\n\n"),
+			},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open(v.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileMap, err := DockerExtractor{}.ExtractFromFile(context.Background(), f, []string{"etc/debian_version", "var/lib/dpkg/status"})
+		f.Close()
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.expected, fileMap) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, fileMap)
+		}
+	}
+}
+
+func TestApplyLayers(t *testing.T) {
+	lower := MapFileMap{
+		"etc/debian_version":     []byte("10.1\n"),
+		"var/lib/dpkg/status":    []byte("old\n"),
+		"var/lib/dpkg/available": []byte("old\n"),
+	}
+	var tests = map[string]struct {
+		upper    MapFileMap
+		opqDirs  opqDirs
+		expected FileMap
+	}{
+		"Whiteout": {
+			upper: MapFileMap{"var/lib/dpkg/.wh.status": []byte{}},
+			expected: MapFileMap{
+				"etc/debian_version":     []byte("10.1\n"),
+				"var/lib/dpkg/available": []byte("old\n"),
+			},
+		},
+		"DirectoryWhiteout": {
+			upper:    MapFileMap{"var/lib/.wh.dpkg": []byte{}},
+			expected: MapFileMap{"etc/debian_version": []byte("10.1\n")},
+		},
+		"NonexistentWhiteout": {
+			upper:    MapFileMap{"var/lib/rpm/.wh.Packages": []byte{}},
+			expected: lower,
+		},
+		"Opaque": {
+			upper:   MapFileMap{"var/lib/dpkg/status": []byte("new\n")},
+			opqDirs: opqDirs{"var/lib/dpkg"},
+			expected: MapFileMap{
+				"etc/debian_version":  []byte("10.1\n"),
+				"var/lib/dpkg/status": []byte("new\n"),
+			},
+		},
+		"RootOpaque": {
+			upper:    MapFileMap{"var/lib/dpkg/status": []byte("new\n")},
+			opqDirs:  opqDirs{"."},
+			expected: MapFileMap{"var/lib/dpkg/status": []byte("new\n")},
+		},
+	}
+	for testName, v := range tests {
+		layers := map[string]extractedLayer{
+			"lower": {files: lower},
+			"upper": {files: v.upper, opqDirs: v.opqDirs},
+		}
+		actual, err := applyLayers([]string{"lower", "upper"}, layers)
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.expected, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestApplyLayers_LinkTargets(t *testing.T) {
+	lower := extractedLayer{
+		files:       MapFileMap{},
+		symlinks:    map[string]symlink{"etc/os-release": {target: "usr/lib/os-release", required: true}},
+		linkTargets: MapFileMap{"usr/lib/os-release": []byte("ID=fedora\n"), "usr/share/os-release": []byte("unused\n")},
+	}
+	var tests = map[string]struct {
+		upper    extractedLayer
+		expected MapFileMap
+	}{
+		"Lower": {
+			upper:    extractedLayer{files: MapFileMap{}},
+			expected: MapFileMap{"etc/os-release": []byte("ID=fedora\n")},
+		},
+		"Updated": {
+			upper:    extractedLayer{files: MapFileMap{}, linkTargets: MapFileMap{"usr/lib/os-release": []byte("ID=fedora\nVERSION_ID=32\n")}},
+			expected: MapFileMap{"etc/os-release": []byte("ID=fedora\nVERSION_ID=32\n")},
+		},
+		"Removed": {
+			upper:    extractedLayer{files: MapFileMap{"usr/lib/.wh.os-release": nil}},
+			expected: MapFileMap{},
+		},
+	}
+	for testName, v := range tests {
+		layers := map[string]extractedLayer{"lower": lower, "upper": v.upper}
+		actual, err := applyLayers([]string{"lower", "upper"}, layers)
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.expected, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestCreateDockerClient(t *testing.T) {
+	origDockerSocket, origPodmanSocket := dockerSocket, rootfulPodmanSocket
+	defer func() {
+		dockerSocket, rootfulPodmanSocket = origDockerSocket, origPodmanSocket
+	}()
+
+	dir, err := ioutil.TempDir("", "socket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, socket := range []string{"docker.sock", "podman/podman.sock", "rootful/podman.sock"} {
+		path := filepath.Join(dir, socket)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err = ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vectors := []struct {
+		name         string
+		dockerHost   string // DockerOption.DockerHost
+		envHost      string // DOCKER_HOST
+		dockerSocket string
+		podmanSocket string
+		xdgRuntime   string // XDG_RUNTIME_DIR
+		expected     string // Expected daemon host
+	}{
+		{
+			name:     "default",
+			expected: "unix:///var/run/docker.sock",
+		},
+		{
+			name:     "env",
+			envHost:  "unix:///run/user/1000/podman/podman.sock",
+			expected: "unix:///run/user/1000/podman/podman.sock",
+		},
+		{
+			name:       "option",
+			dockerHost: "unix:///run/podman/podman.sock",
+			envHost:    "unix:///run/user/1000/podman/podman.sock",
+			expected:   "unix:///run/podman/podman.sock",
+		},
+		{
+			name:         "docker socket",
+			dockerSocket: filepath.Join(dir, "docker.sock"),
+			xdgRuntime:   dir,
+			expected:     "unix://" + filepath.Join(dir, "docker.sock"),
+		},
+		{
+			name:       "rootless podman",
+			xdgRuntime: dir,
+			expected:   "unix://" + filepath.Join(dir, "podman/podman.sock"),
+		},
+		{
+			name:         "rootful podman",
+			podmanSocket: filepath.Join(dir, "rootful/podman.sock"),
+			expected:     "unix://" + filepath.Join(dir, "rootful/podman.sock"),
+		},
+	}
+
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			for key, value := range map[string]string{"DOCKER_HOST": v.envHost, "XDG_RUNTIME_DIR": v.xdgRuntime} {
+				orig := os.Getenv(key)
+				defer os.Setenv(key, orig)
+				os.Setenv(key, value)
+			}
+			dockerSocket, rootfulPodmanSocket = filepath.Join(dir, "missing"), filepath.Join(dir, "missing")
+			if v.dockerSocket != "" {
+				dockerSocket = v.dockerSocket
+			}
+			if v.podmanSocket != "" {
+				rootfulPodmanSocket = v.podmanSocket
+			}
+
+			d := NewDockerExtractor(WithDockerOption(DockerOption{DockerHost: v.dockerHost}))
+			c, err := d.createDockerClient()
+			if err != nil {
+				t.Fatalf("createDockerClient() error: %v", err)
+			}
+			if c.DaemonHost() != v.expected {
+				t.Errorf("DaemonHost: got %v, want %v", c.DaemonHost(), v.expected)
+			}
+		})
+	}
+}
+
+func TestExtractFromFile_Podman(t *testing.T) {
+	// podman save writes the layers and the config at the top level
+	layer := writeTar(t, []tarEntry{{name: "etc/test/bar", mode: 0644, content: "bar\n"}})
+	image := writeTar(t, []tarEntry{
+		{name: "0123abcd.tar", mode: 0644, content: string(layer)},
+		{name: "d1cea7b7.json", mode: 0644, content: "{}"},
+		{name: "manifest.json", mode: 0644, content: `[{"Config":"d1cea7b7.json","RepoTags":["localhost/app:latest"],"Layers":["0123abcd.tar"]}]`},
+		{name: "repositories", mode: 0644, content: `{"localhost/app":{"latest":"0123abcd"}}`},
+	})
+
+	d := DockerExtractor{}
+	fileMap, metadata, err := d.ExtractFromFileWithMetadata(nil, ioutil.NopCloser(bytes.NewReader(image)), []string{"etc/test/bar"})
+	if err != nil {
+		t.Fatalf("catch the error : %v", err)
+	}
+	expected := MapFileMap{"etc/test/bar": []byte("bar\n")}
+	if !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+	if metadata.ID != "sha256:d1cea7b7" {
+		t.Errorf("ID: got %v, want %v", metadata.ID, "sha256:d1cea7b7")
+	}
+}
+
+type tarEntry struct {
+	name    string
+	mode    int64
+	content string
+}
+
+func writeTar(t *testing.T, entries []tarEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: e.mode, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractFromFileWithMetadata_Executables(t *testing.T) {
+	layer1 := writeTar(t, []tarEntry{
+		{name: "bin/sh", mode: 0755, content: "sh"},
+		{name: "etc/passwd", mode: 0644, content: "root"},
+		{name: "usr/bin/old", mode: 0700, content: "old"},
+		{name: "usr/local/bin/tool", mode: 0744, content: "tool"},
+	})
+	layer2 := writeTar(t, []tarEntry{
+		{name: "usr/bin/.wh.old", mode: 0644},
+		{name: "app/run", mode: 04755, content: "run"},
+	})
+	image := writeTar(t, []tarEntry{
+		{name: "manifest.json", mode: 0644, content: `[{"Config": "abc.json", "Layers": ["layer1/layer.tar", "layer2/layer.tar"]}]`},
+		{name: "layer1/layer.tar", mode: 0644, content: string(layer1)},
+		{name: "layer2/layer.tar", mode: 0644, content: string(layer2)},
+	})
+
+	d := DockerExtractor{Option: DockerOption{CollectExecutables: true}}
+	_, metadata, err := d.ExtractFromFileWithMetadata(nil, ioutil.NopCloser(bytes.NewReader(image)), nil)
+	if err != nil {
+		t.Fatalf("catch the error : %v", err)
+	}
+	expected := []ExecutableFile{
+		{Path: "app/run", Mode: os.ModeSetuid | 0755, Size: 3},
+		{Path: "bin/sh", Mode: 0755, Size: 2},
+		{Path: "usr/local/bin/tool", Mode: 0744, Size: 4},
+	}
+	if !reflect.DeepEqual(expected, metadata.Executables) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, metadata.Executables)
+	}
+}
+
+func TestExtractFromFileWithName(t *testing.T) {
+	appLayer := writeTar(t, []tarEntry{{name: "etc/os-release", mode: 0644, content: "ID=app\n"}})
+	baseLayer := writeTar(t, []tarEntry{{name: "etc/os-release", mode: 0644, content: "ID=base\n"}})
+	archive := writeTar(t, []tarEntry{
+		{name: "manifest.json", mode: 0644, content: `[
+			{"Config": "aaa.json", "RepoTags": ["app:latest", "registry.example.com/app:1.0"], "Layers": ["app/layer.tar"]},
+			{"Config": "bbb.json", "RepoTags": ["base:latest"], "Layers": ["base/layer.tar"]}
+		]`},
+		{name: "app/layer.tar", mode: 0644, content: string(appLayer)},
+		{name: "base/layer.tar", mode: 0644, content: string(baseLayer)},
+	})
+
+	vectors := []struct {
+		imageName string
+		expected  string // Expected ID in etc/os-release
+		err       string // Expected error message
+	}{
+		{imageName: "base", expected: "ID=base\n"},
+		{imageName: "docker.io/library/base:latest", expected: "ID=base\n"},
+		{imageName: "app:latest", expected: "ID=app\n"},
+		{imageName: "registry.example.com/app:1.0", expected: "ID=app\n"},
+		{imageName: "app:1.0", err: "available: app:latest, registry.example.com/app:1.0, base:latest"},
+	}
+	for _, v := range vectors {
+		t.Run(v.imageName, func(t *testing.T) {
+			d := DockerExtractor{}
+			fm, err := d.ExtractFromFileWithName(nil, ioutil.NopCloser(bytes.NewReader(archive)), v.imageName,
+				[]string{"etc/os-release"})
+			if v.err != "" {
+				if err == nil || !strings.Contains(err.Error(), v.err) {
+					t.Errorf("err: got %v, want %v", err, v.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractFromFileWithName() error: %v", err)
+			}
+			if content, _ := fm.Get("etc/os-release"); string(content) != v.expected {
+				t.Errorf("os-release: got %q, want %q", content, v.expected)
+			}
+		})
+	}
+}
+
+func TestExtractFromFile_Compressed(t *testing.T) {
+	filenames := []string{"var/foo", "etc/test/bar"}
+	f, err := os.Open("testdata/containerd.tar")
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer f.Close()
+	expected, err := DockerExtractor{}.ExtractFromFile(nil, f, filenames)
+	if err != nil {
+		t.Fatalf("ExtractFromFile() error: %v", err)
+	}
+
+	vectors := []struct {
+		file string
+	}{
+		{file: "testdata/containerd.tar.gz"},
+		{file: "testdata/containerd.tar.bz2"},
+		{file: "testdata/containerd.tar.xz"},
+		{file: "testdata/containerd.tar.zst"},
+	}
+	for _, v := range vectors {
+		t.Run(path.Base(v.file), func(t *testing.T) {
+			f, err := os.Open(v.file)
+			if err != nil {
+				t.Fatalf("Open() error: %v", err)
+			}
+			defer f.Close()
+
+			fm, err := DockerExtractor{}.ExtractFromFile(nil, f, filenames)
+			if err != nil {
+				t.Fatalf("ExtractFromFile() error: %v", err)
+			}
+			if !reflect.DeepEqual(fm, expected) {
+				t.Errorf("FilesMap: got %v, want %v", fm, expected)
+			}
+		})
+	}
+}
+
+func TestExtractWithMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{Name: "var/foo", Mode: 0644, Size: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("foo")); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	gw.Close()
+	layerDigest := digest.FromBytes(buf.Bytes())
+
+	configDigest := digest.FromString(testImageConfig)
+	manifest := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "` + configDigest.String() + `"},
+  "layers": [{"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": ` + strconv.Itoa(buf.Len()) + `, "digest": "` + layerDigest.String() + `"}]
+}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifest))
+		case "/v2/app/blobs/" + configDigest.String():
+			w.Write([]byte(testImageConfig))
+		case "/v2/app/blobs/" + layerDigest.String():
+			w.Write(buf.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	d := NewDockerExtractor(WithDockerOption(DockerOption{NonSSL: true, DockerHost: "unix:///nonexistent.sock"}), WithCache(nil))
+	fileMap, metadata, err := d.ExtractWithMetadata(context.Background(), strings.TrimPrefix(ts.URL, "http://")+"/app:1.0", []string{"var/foo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := (MapFileMap{"var/foo": []byte("foo")}); !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+	if metadata.Digest != digest.FromString(manifest).String() || metadata.ID != configDigest.String() {
+		t.Errorf("unexpected digest or ID: %+v", metadata)
+	}
+	expected := []string{
+		"sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc",
+		"sha256:dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd",
+	}
+	if !reflect.DeepEqual(expected, metadata.DiffIDs) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, metadata.DiffIDs)
+	}
+	// A header and a block of the content followed by two zero blocks
+	expectedLayers := []LayerDescriptor{{
+		Digest:           layerDigest.String(),
+		MediaType:        "application/vnd.docker.image.rootfs.diff.tar.gzip",
+		Size:             int64(buf.Len()),
+		UncompressedSize: 2048,
+		FileCount:        1,
+	}}
+	if !reflect.DeepEqual(expectedLayers, metadata.Layers) {
+		t.Errorf("\nexpected : %v\nactual : %v", expectedLayers, metadata.Layers)
+	}
+}
+
+// newLocalImageServers returns the daemon with the image of the config, whose var/foo is "local",
+// and the registry with the same image, whose var/foo is "registry". The image is "<registry host>/app:1.0".
+func newLocalImageServers(t *testing.T, localConfig string) (*httptest.Server, *httptest.Server) {
+	archive := writeTar(t, []tarEntry{
+		{name: "0/layer.tar", mode: 0644, content: string(writeTar(t, []tarEntry{{name: "var/foo", mode: 0644, content: "local"}}))},
+		{name: "config.json", mode: 0644, content: localConfig},
+		{name: "manifest.json", mode: 0644, content: `[{"Config":"config.json","RepoTags":null,"Layers":["0/layer.tar"]}]`},
+	})
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/images/get"):
+			w.Write(archive)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No such image"}`))
+		}
+	}))
+
+	layer := writeTar(t, []tarEntry{{name: "var/foo", mode: 0644, content: "registry"}})
+	layerDigest := digest.FromBytes(layer)
+	configDigest := digest.FromString(testImageConfig)
+	manifest := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "` + configDigest.String() + `"},
+  "layers": [{"mediaType": "application/vnd.docker.image.rootfs.diff.tar", "size": ` + strconv.Itoa(len(layer)) + `, "digest": "` + layerDigest.String() + `"}]
+}`
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifest))
+		case "/v2/app/blobs/" + configDigest.String():
+			w.Write([]byte(testImageConfig))
+		case "/v2/app/blobs/" + layerDigest.String():
+			w.Write(layer)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return daemon, registry
+}
+
+func TestExtractWithMetadata_LocalImage(t *testing.T) {
+	daemon, registry := newLocalImageServers(t, testImageConfig)
+	defer daemon.Close()
+	defer registry.Close()
+
+	var tests = map[string]struct {
+		localImage bool
+		platform   string
+		expected   string
+	}{
+		"registry by default": {expected: "registry"},
+		"local image":         {localImage: true, expected: "local"},
+		"platform":            {localImage: true, platform: "linux/amd64", expected: "registry"},
+	}
+	for testname, v := range tests {
+		option := DockerOption{NonSSL: true, DockerHost: "tcp://" + daemon.Listener.Addr().String(),
+			LocalImage: v.localImage, Platform: v.platform}
+		d := NewDockerExtractor(WithDockerOption(option), WithCache(nil))
+		fileMap, _, err := d.ExtractWithMetadata(context.Background(), strings.TrimPrefix(registry.URL, "http://")+"/app:1.0", []string{"var/foo"})
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testname, err)
+			continue
+		}
+		if content, _ := fileMap.Get("var/foo"); string(content) != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.expected, string(content))
+		}
+	}
+}
+
+func TestExtractWithMetadata_Containerd(t *testing.T) {
+	daemon, registry := newLocalImageServers(t, testImageConfig)
+	defer daemon.Close()
+	defer registry.Close()
+	imageName := strings.TrimPrefix(registry.URL, "http://") + "/app:1.0"
+
+	var tests = map[string]struct {
+		file     string
+		ref      string
+		expected string
+	}{
+		// etc/test/bar is in testdata/containerd.tar and var/foo is in the registry
+		"containerd":    {file: "testdata/containerd.tar", ref: imageName, expected: "etc/test/bar"},
+		"no ctr":        {expected: "var/foo"},
+		"no such image": {file: "testdata/containerd.tar", ref: "docker.io/library/test:latest", expected: "var/foo"},
+	}
+	for testname, v := range tests {
+		restore := fakeCtr(t, v.file, v.ref)
+		option := DockerOption{NonSSL: true, ContainerdAddress: "/nonexistent.sock"}
+		d := NewDockerExtractor(WithDockerOption(option), WithCache(nil))
+		fileMap, _, err := d.ExtractWithMetadata(context.Background(), imageName, []string{"etc/test/bar", "var/foo"})
+		restore()
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testname, err)
+			continue
+		}
+		if actual := fileMap.Paths(); !reflect.DeepEqual([]string{v.expected}, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.expected, actual)
+		}
+	}
+}
+
+func TestExtractWithMetadata_Zstd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	unpackLayout(t, "testdata/zstd.tar", dir)
+
+	// The manifest of the fixture with the gzip layer and the zstd layer
+	var m ociManifest
+	if err = readBlobJSON(dir, "sha256:c85b2d3d78329658c6a6a94f584d2c218f7e9f4d3facfc06999bb171f1adb866", &m); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "` + m.Config.Digest.String() + `"},
+  "layers": [
+    {"mediaType": "` + m.Layers[0].MediaType + `", "size": 100, "digest": "` + m.Layers[0].Digest.String() + `"},
+    {"mediaType": "` + m.Layers[1].MediaType + `", "size": 100, "digest": "` + m.Layers[1].Digest.String() + `"}
+  ]
+}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifest))
+		case strings.HasPrefix(r.URL.Path, "/v2/app/blobs/"):
+			f, err := openBlob(dir, digest.Digest(strings.TrimPrefix(r.URL.Path, "/v2/app/blobs/")))
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			defer f.Close()
+			io.Copy(w, f)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	d := NewDockerExtractor(WithDockerOption(DockerOption{NonSSL: true, DockerHost: "unix:///nonexistent.sock"}), WithCache(nil))
+	fileMap, _, err := d.ExtractWithMetadata(context.Background(), strings.TrimPrefix(ts.URL, "http://")+"/app:1.0", []string{"etc/test/foo", "etc/test/bar"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := MapFileMap{"etc/test/foo": []byte("foo\n"), "etc/test/bar": []byte("bar\n")}
+	if !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+}
+
+func TestExtractWithMetadata_Timeout(t *testing.T) {
+	// The layer stalls after the first part
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	content := bytes.Repeat([]byte("fanal"), 1<<20)
+	if err := tw.WriteHeader(&tar.Header{Name: "var/foo", Mode: 0644, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	gw.Close()
+	layerDigest := digest.FromBytes(buf.Bytes())
+
+	configDigest := digest.FromString(testImageConfig)
+	manifest := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "` + configDigest.String() + `"},
+  "layers": [{"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": ` + strconv.Itoa(buf.Len()) + `, "digest": "` + layerDigest.String() + `"}]
+}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifest))
+		case "/v2/app/blobs/" + configDigest.String():
+			w.Write([]byte(testImageConfig))
+		case "/v2/app/blobs/" + layerDigest.String():
+			w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+			w.Write(buf.Bytes()[:4096])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	timeout := 500 * time.Millisecond
+	d := NewDockerExtractor(WithDockerOption(DockerOption{NonSSL: true, DockerHost: "unix:///nonexistent.sock"}),
+		WithTimeout(timeout), WithCache(nil))
+	start := time.Now()
+	_, _, err := d.ExtractWithMetadata(context.Background(), strings.TrimPrefix(ts.URL, "http://")+"/app:1.0", []string{"var/foo"})
+	elapsed := time.Since(start)
+	if !xerrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the timeout, got %v", err)
+	}
+	if elapsed > 2*timeout {
+		t.Errorf("the timeout fired after %s", elapsed)
+	}
+}
+
+func TestExtractWithMetadata_CancelOnError(t *testing.T) {
+	// The download of the stalled layer is canceled when the other layer fails
+	failedDigest, stalledDigest := digest.FromString("failed"), digest.FromString("stalled")
+	configDigest := digest.FromString(testImageConfig)
+	manifest := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "` + configDigest.String() + `"},
+  "layers": [
+    {"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 100, "digest": "` + failedDigest.String() + `"},
+    {"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 100, "digest": "` + stalledDigest.String() + `"}
+  ]
+}`
+	stalled := make(chan struct{})
+	canceled := make(chan struct{})
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifest))
+		case "/v2/app/blobs/" + configDigest.String():
+			w.Write([]byte(testImageConfig))
+		case "/v2/app/blobs/" + failedDigest.String():
+			<-stalled
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/v2/app/blobs/" + stalledDigest.String():
+			close(stalled)
+			select {
+			case <-r.Context().Done():
+				close(canceled)
+			case <-done:
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	// Let the handler return before ts.Close if the download is not canceled
+	defer close(done)
+
+	d := NewDockerExtractor(WithDockerOption(DockerOption{NonSSL: true, DockerHost: "unix:///nonexistent.sock"}), WithCache(nil))
+	if _, _, err := d.ExtractWithMetadata(context.Background(), strings.TrimPrefix(ts.URL, "http://")+"/app:1.0", []string{"var/foo"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Error("the download of the stalled layer was not canceled")
+	}
+}
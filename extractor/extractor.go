@@ -3,6 +3,11 @@ package extractor
 import (
 	"context"
 	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -12,9 +17,168 @@ var (
 	ErrCouldNotExtract = errors.New("Could not extract the archive")
 )
 
-type FileMap map[string][]byte
+// FileMap is the files extracted from the image keyed by the slash-separated path, e.g. etc/os-release.
+// Analyzers read the files through it, so that the files don't have to be in memory.
+type FileMap interface {
+	// Get returns the content of the file.
+	Get(path string) ([]byte, bool)
+	// ForEach calls fn for each file in no particular order. It stops at the first error of fn and returns it.
+	ForEach(fn func(path string, content []byte) error) error
+	// Paths returns the sorted paths of the files.
+	Paths() []string
+	// Metadata returns the size and the MIME type of the file.
+	Metadata(path string) (FileMetadata, bool)
+}
+
+// MapFileMap is the FileMap in memory.
+type MapFileMap map[string][]byte
+
+// Get returns the content of the file.
+func (m MapFileMap) Get(path string) ([]byte, bool) {
+	content, ok := m[path]
+	return content, ok
+}
+
+// ForEach calls fn for each file in no particular order. It stops at the first error of fn and returns it.
+func (m MapFileMap) ForEach(fn func(path string, content []byte) error) error {
+	for path, content := range m {
+		if err := fn(path, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Paths returns the sorted paths of the files.
+func (m MapFileMap) Paths() []string {
+	paths := make([]string, 0, len(m))
+	for path := range m {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ImageMetadata describes the image the files were extracted from.
+type ImageMetadata struct {
+	// Digest is the digest of the manifest in the registry, e.g. "sha256:769f...". It can be empty.
+	Digest string
+	// ID is the digest of the image config, which is what "docker images" shows.
+	ID string
+	// DiffIDs is the digests of the uncompressed layers from the bottom, i.e. rootfs.diff_ids of the image config.
+	DiffIDs []string
+	// Executables is set only with DockerOption.CollectExecutables.
+	Executables []ExecutableFile
+	// Config is set only by FetchImageMetadata.
+	Config ImageConfig
+	// Layers is the layers of the image from the bottom. MediaType is empty for the archives of docker save.
+	Layers []LayerDescriptor
+	// TotalSizeBytes is the total size of the layer blobs in Layers, which are compressed in the registry.
+	TotalSizeBytes int64
+	// History is the history in the image config, where the entries with EmptyLayer don't have a layer.
+	History []HistoryEntry
+	// Platform is "os/arch[/variant]" of the image selected from the multi-arch image in the registry.
+	// Digest is the digest of the manifest of the platform. It is empty for single-arch images.
+	Platform string
+}
+
+// ExecutableFile is a regular file with any execute bit in the image.
+type ExecutableFile struct {
+	Path string
+	Mode os.FileMode
+	Size int64
+}
 
 type Extractor interface {
 	Extract(ctx context.Context, imageName string, filenames []string) (FileMap, error)
 	ExtractFromFile(ctx context.Context, r io.ReadCloser, filenames []string) (FileMap, error)
 }
+
+// Filter returns the files whose size is between minSize and maxSize and whose path matches pathGlob.
+// minSize and maxSize of 0 mean no limit, and the empty pathGlob matches any file.
+// pathGlob is matched in the same way as Match, e.g. "usr/lib/*.so", "**/*.so" or "*.jar".
+// The returned map shares the contents with the original one.
+func (m MapFileMap) Filter(minSize, maxSize int64, pathGlob string) MapFileMap {
+	filtered := MapFileMap{}
+	for filePath, content := range m {
+		size := int64(len(content))
+		if minSize > 0 && size < minSize {
+			continue
+		}
+		if maxSize > 0 && size > maxSize {
+			continue
+		}
+		if pathGlob != "" && !Match(pathGlob, filePath) {
+			continue
+		}
+		filtered[filePath] = content
+	}
+	return filtered
+}
+
+// Match reports whether the file path matches the pattern of required files.
+// The pattern without "/" is matched against the file name, e.g. "Gemfile.lock" and "*.deps.json".
+// The pattern with "/" is matched against the whole path, and "**" matches zero or more directories,
+// e.g. "etc/*-release" and "**/vendor/composer/installed.json".
+// The pattern ending with "/" matches all the files under the directory, e.g. "var/lib/dpkg/status.d/".
+func Match(pattern, filePath string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return matchElems(dirPatternElems(pattern), strings.Split(filePath, "/"))
+	}
+	if !strings.Contains(pattern, "/") {
+		filePath = filepath.Base(filePath)
+		if pattern == filePath {
+			return true
+		}
+		matched, err := path.Match(pattern, filePath)
+		return err == nil && matched
+	}
+	return matchElems(strings.Split(pattern, "/"), strings.Split(filePath, "/"))
+}
+
+// IsRequired reports whether the file matches any of the patterns and is not excluded.
+// The excluded paths apply only to the files matched by the file name such as "yarn.lock",
+// so that a pattern like "**/node_modules/*/package.json" can still ask for files in excluded directories.
+func IsRequired(filePath string, patterns, excludedPaths []string) bool {
+	excluded := MatchAny(excludedPaths, filePath)
+	for _, pattern := range patterns {
+		if !Match(pattern, filePath) {
+			continue
+		}
+		if !excluded || strings.Contains(pattern, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAny reports whether the file path matches any of the patterns.
+func MatchAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchElems(pattern, elems []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchElems(pattern[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], elems[0]); err != nil || !matched {
+			return false
+		}
+		pattern, elems = pattern[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
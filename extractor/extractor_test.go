@@ -0,0 +1,170 @@
+package extractor
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMapFileMap(t *testing.T) {
+	var fileMap FileMap = MapFileMap{
+		"etc/os-release":       []byte("ID=alpine\n"),
+		"etc/alpine-release":   []byte("3.9.4\n"),
+		"lib/apk/db/installed": []byte{},
+	}
+
+	if content, ok := fileMap.Get("etc/alpine-release"); !ok || string(content) != "3.9.4\n" {
+		t.Errorf("unexpected content: %q %v", content, ok)
+	}
+	if _, ok := fileMap.Get("etc/debian_version"); ok {
+		t.Error("etc/debian_version should not exist")
+	}
+
+	expected := []string{"etc/alpine-release", "etc/os-release", "lib/apk/db/installed"}
+	if actual := fileMap.Paths(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+
+	var size int
+	err := fileMap.ForEach(func(path string, content []byte) error {
+		size += len(content)
+		return nil
+	})
+	if err != nil || size != 16 {
+		t.Errorf("unexpected result: %d %v", size, err)
+	}
+
+	// ForEach stops at the first error
+	errStop := errors.New("stop")
+	var calls int
+	err = fileMap.ForEach(func(path string, content []byte) error {
+		calls++
+		return errStop
+	})
+	if err != errStop || calls != 1 {
+		t.Errorf("unexpected result: %d %v", calls, err)
+	}
+}
+
+func TestMapFileMap_Filter(t *testing.T) {
+	fileMap := MapFileMap{
+		"etc/alpine-release":         []byte("3.9.4"),
+		"app/lib/guava-27.1-jre.jar": make([]byte, 2048),
+		"app/lib/small.jar":          make([]byte, 16),
+		"usr/lib/libssl.so":          make([]byte, 4096),
+	}
+
+	var tests = map[string]struct {
+		minSize  int64
+		maxSize  int64
+		pathGlob string
+		expected []string
+	}{
+		"NoLimit": {
+			expected: []string{"app/lib/guava-27.1-jre.jar", "app/lib/small.jar", "etc/alpine-release", "usr/lib/libssl.so"},
+		},
+		"MinSize": {
+			minSize:  1024,
+			expected: []string{"app/lib/guava-27.1-jre.jar", "usr/lib/libssl.so"},
+		},
+		"MaxSize": {
+			maxSize:  16,
+			expected: []string{"app/lib/small.jar", "etc/alpine-release"},
+		},
+		"FileNameGlob": {
+			minSize:  1024,
+			pathGlob: "*.jar",
+			expected: []string{"app/lib/guava-27.1-jre.jar"},
+		},
+		"PathGlob": {
+			pathGlob: "usr/lib/*.so",
+			expected: []string{"usr/lib/libssl.so"},
+		},
+	}
+	for testName, v := range tests {
+		filtered := fileMap.Filter(v.minSize, v.maxSize, v.pathGlob)
+		expected := MapFileMap{}
+		for _, path := range v.expected {
+			expected[path] = fileMap[path]
+		}
+		if !reflect.DeepEqual(expected, filtered) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, filtered)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	var tests = map[string]struct {
+		pattern  string
+		filePath string
+		expected bool
+	}{
+		"ExactName":         {pattern: "Gemfile.lock", filePath: "app/Gemfile.lock", expected: true},
+		"NameGlob":          {pattern: "*.deps.json", filePath: "app/bin/app.deps.json", expected: true},
+		"NameMismatch":      {pattern: "Gemfile.lock", filePath: "app/Gemfile", expected: false},
+		"ExactPath":         {pattern: "etc/alpine-release", filePath: "etc/alpine-release", expected: true},
+		"PathNotSuffix":     {pattern: "etc/alpine-release", filePath: "foo/etc/alpine-release", expected: false},
+		"PathGlob":          {pattern: "etc/*-release", filePath: "etc/redhat-release", expected: true},
+		"DoubleStarRoot":    {pattern: "**/vendor/composer/installed.json", filePath: "vendor/composer/installed.json", expected: true},
+		"DoubleStarNested":  {pattern: "**/vendor/composer/installed.json", filePath: "var/www/app/vendor/composer/installed.json", expected: true},
+		"DoubleStarMiddle":  {pattern: "usr/**/*.so", filePath: "usr/lib/x86_64-linux-gnu/libssl.so", expected: true},
+		"DoubleStarNoMatch": {pattern: "**/node_modules/*/package.json", filePath: "app/package.json", expected: false},
+		"Directory":         {pattern: "var/lib/dpkg/status.d/", filePath: "var/lib/dpkg/status.d/base", expected: true},
+		"DirectoryNested":   {pattern: "var/lib/pacman/local/", filePath: "var/lib/pacman/local/bash-5.0/desc", expected: true},
+		"DirectoryItself":   {pattern: "var/lib/dpkg/status.d/", filePath: "var/lib/dpkg/status.d", expected: false},
+		"DirectoryPrefix":   {pattern: "var/lib/dpkg/status.d/", filePath: "var/lib/dpkg/status.d.bak/base", expected: false},
+		"DirectoryGlob":     {pattern: "root/buildinfo/*/", filePath: "root/buildinfo/content_manifests/ubi8.json", expected: true},
+	}
+	for testName, v := range tests {
+		if actual := Match(v.pattern, v.filePath); actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestIsRequired(t *testing.T) {
+	patterns := []string{"yarn.lock", "**/node_modules/*/package.json", "etc/os-release"}
+	excludedPaths := []string{"**/node_modules/**"}
+
+	var tests = map[string]struct {
+		filePath string
+		expected bool
+	}{
+		"FileName":               {filePath: "app/yarn.lock", expected: true},
+		"ExcludedFileName":       {filePath: "app/node_modules/foo/yarn.lock", expected: false},
+		"PathPatternInExcluded":  {filePath: "app/node_modules/foo/package.json", expected: true},
+		"NotRequired":            {filePath: "app/package.json", expected: false},
+		"PathPatternNotExcluded": {filePath: "etc/os-release", expected: true},
+	}
+	for testName, v := range tests {
+		if actual := IsRequired(v.filePath, patterns, excludedPaths); actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestDetectMIMEType(t *testing.T) {
+	tar := make([]byte, 512)
+	copy(tar[257:], "ustar\x0000")
+
+	var tests = map[string]struct {
+		content  []byte
+		expected string
+	}{
+		"Text":   {content: []byte("Django==2.2.1\nrequests==2.22.0\n"), expected: "text/plain"},
+		"JSON":   {content: []byte(`{"dependencies": {}}`), expected: "text/plain"},
+		"XML":    {content: []byte("\n  <?xml version=\"1.0\"?><project></project>"), expected: MIMETypeXML},
+		"ELF":    {content: []byte("\x7fELF\x02\x01\x01\x00"), expected: MIMETypeELF},
+		"ZIP":    {content: []byte("PK\x03\x04\x14\x00"), expected: MIMETypeZIP},
+		"Gzip":   {content: []byte("\x1f\x8b\x08\x00"), expected: MIMETypeGzip},
+		"Tar":    {content: tar, expected: MIMETypeTar},
+		"Binary": {content: []byte{0x00, 0x01, 0x02, 0xff}, expected: "application/octet-stream"},
+		"Empty":  {content: nil, expected: "text/plain"},
+	}
+	for testName, v := range tests {
+		actual := DetectMIMEType(v.content)
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package extractor
+
+import (
+	"path"
+	"strings"
+)
+
+// patternSet is the patterns of Match compiled for matching many files, e.g. all the entries of the layers.
+// The patterns without wildcards are looked up in the maps instead of being matched one by one.
+type patternSet struct {
+	// names are the patterns without "/" and wildcards matched against the file name, e.g. "Gemfile.lock"
+	names map[string]struct{}
+	// paths are the patterns with "/" and without wildcards, e.g. "etc/os-release"
+	paths map[string]struct{}
+	// prefixes are the directories without wildcards, e.g. "var/lib/dpkg/status.d/"
+	prefixes []string
+	// nameGlobs are the patterns without "/" with wildcards, e.g. "*.deps.json"
+	nameGlobs []string
+	// pathGlobs are the other patterns split by "/", e.g. "**/vendor/composer/installed.json"
+	pathGlobs [][]string
+}
+
+func compilePatterns(patterns []string) patternSet {
+	s := patternSet{names: map[string]struct{}{}, paths: map[string]struct{}{}}
+	for _, pattern := range patterns {
+		wildcard := strings.ContainsAny(pattern, `*?[\`)
+		switch {
+		case !strings.Contains(pattern, "/") && wildcard:
+			s.nameGlobs = append(s.nameGlobs, pattern)
+		case !strings.Contains(pattern, "/"):
+			s.names[pattern] = struct{}{}
+		case strings.HasSuffix(pattern, "/") && !wildcard:
+			s.prefixes = append(s.prefixes, pattern)
+		case strings.HasSuffix(pattern, "/"):
+			s.pathGlobs = append(s.pathGlobs, dirPatternElems(pattern))
+		case !wildcard:
+			s.paths[pattern] = struct{}{}
+		default:
+			s.pathGlobs = append(s.pathGlobs, strings.Split(pattern, "/"))
+		}
+	}
+	return s
+}
+
+// matchPath reports whether the file path matches any of the patterns with "/".
+func (s patternSet) matchPath(filePath string) bool {
+	if _, ok := s.paths[filePath]; ok {
+		return true
+	}
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(filePath, prefix) {
+			return true
+		}
+	}
+	if len(s.pathGlobs) == 0 {
+		return false
+	}
+	elems := strings.Split(filePath, "/")
+	for _, pattern := range s.pathGlobs {
+		if matchElems(pattern, elems) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchName reports whether the file path matches any of the patterns without "/" by the file name.
+func (s patternSet) matchName(filePath string) bool {
+	fileName := path.Base(filePath)
+	if _, ok := s.names[fileName]; ok {
+		return true
+	}
+	for _, pattern := range s.nameGlobs {
+		// The same as Match, the malformed pattern matches itself
+		if pattern == fileName {
+			return true
+		}
+		if matched, err := path.Match(pattern, fileName); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (s patternSet) match(filePath string) bool {
+	return s.matchPath(filePath) || s.matchName(filePath)
+}
+
+// dirPatternElems returns the split pattern matching the files under the directory pattern ending with "/",
+// e.g. "usr/lib/*/" is "usr/lib/*/*/**", which doesn't match the directory itself.
+func dirPatternElems(pattern string) []string {
+	return append(strings.Split(strings.TrimSuffix(pattern, "/"), "/"), "*", "**")
+}
+
+// fileFilter decides the files to extract in the same way as IsRequired. It is compiled once per extraction.
+type fileFilter struct {
+	required patternSet
+	excluded patternSet
+	// linkTargets are the file names of the required paths, whose files may be the targets of the links,
+	// e.g. "os-release" of "etc/os-release". The files matching the patterns without "/" are already required.
+	// See extractFiles.
+	linkTargets patternSet
+}
+
+func newFileFilter(filenames, excludedPaths []string) fileFilter {
+	var names []string
+	for _, filename := range filenames {
+		if strings.Contains(filename, "/") {
+			names = append(names, path.Base(filename))
+		}
+	}
+	return fileFilter{
+		required:    compilePatterns(filenames),
+		excluded:    compilePatterns(excludedPaths),
+		linkTargets: compilePatterns(names),
+	}
+}
+
+// isRequired is the same as IsRequired with the patterns of the filter.
+func (f fileFilter) isRequired(filePath string) bool {
+	if f.required.matchPath(filePath) {
+		return true
+	}
+	return f.required.matchName(filePath) && !f.excluded.match(filePath)
+}
+
+// isExactPath reports whether the file is required by a pattern without wildcards, e.g. "var/lib/dpkg/status".
+func (f fileFilter) isExactPath(filePath string) bool {
+	_, ok := f.required.paths[filePath]
+	return ok
+}
+
+// isLinkTarget reports whether the file has the same file name as any of the required paths
+// and is not in the excluded paths.
+func (f fileFilter) isLinkTarget(filePath string) bool {
+	return f.linkTargets.match(filePath) && !f.excluded.match(filePath)
+}
@@ -0,0 +1,56 @@
+package extractor
+
+import "testing"
+
+func TestFileFilter(t *testing.T) {
+	patterns := []string{"yarn.lock", "*.deps.json", "etc/os-release", "etc/*-release", "**/node_modules/*/package.json",
+		"var/lib/dpkg/status.d/", "root/buildinfo/*/", "a[b", "etc/a[b"}
+	excludedPaths := []string{"**/node_modules/**", "tmp/"}
+	filePaths := []string{
+		"app/yarn.lock",
+		"app/node_modules/foo/yarn.lock",
+		"tmp/yarn.lock",
+		"app/bin/app.deps.json",
+		"etc/os-release",
+		"foo/etc/os-release",
+		"etc/redhat-release",
+		"app/node_modules/foo/package.json",
+		"app/package.json",
+		"var/lib/dpkg/status.d/base",
+		"var/lib/dpkg/status.d",
+		"var/lib/dpkg/status.d.bak/base",
+		"root/buildinfo/content_manifests/ubi8.json",
+		"root/buildinfo/Dockerfile",
+		"a[b",
+		"etc/a[b",
+	}
+
+	// The compiled filter must be the same as IsRequired
+	filter := newFileFilter(patterns, excludedPaths)
+	for _, filePath := range filePaths {
+		if expected, actual := IsRequired(filePath, patterns, excludedPaths), filter.isRequired(filePath); expected != actual {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", filePath, expected, actual)
+		}
+	}
+}
+
+func TestFileFilter_IsLinkTarget(t *testing.T) {
+	filter := newFileFilter([]string{"etc/os-release", "etc/*-release", "var/lib/dpkg/status.d/", "package.json"},
+		[]string{"**/node_modules/**"})
+	var tests = map[string]struct {
+		filePath string
+		expected bool
+	}{
+		"SameName":      {filePath: "usr/lib/os-release", expected: true},
+		"NameGlob":      {filePath: "usr/lib/fedora-release", expected: true},
+		"DirectoryName": {filePath: "usr/share/status.d", expected: true},
+		"OtherName":     {filePath: "usr/lib/os-release.d/foo", expected: false},
+		"NamePattern":   {filePath: "app/package.json", expected: false},
+		"Excluded":      {filePath: "app/node_modules/etc/os-release", expected: false},
+	}
+	for testName, v := range tests {
+		if actual := filter.isLinkTarget(v.filePath); actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
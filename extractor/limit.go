@@ -0,0 +1,118 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// DefaultMaxFileSize is DockerOption.MaxFileSize by default.
+	DefaultMaxFileSize = 200 << 20
+	// DefaultMaxExtractSize is DockerOption.MaxExtractSize by default.
+	DefaultMaxExtractSize = 2 << 30
+)
+
+// The limits of SizeLimitError
+const (
+	limitMaxFileSize    = "MaxFileSize"
+	limitMaxExtractSize = "MaxExtractSize"
+)
+
+// SizeLimitError occurs when a file is larger than DockerOption.MaxFileSize or FileSizeLimits, or the extracted
+// files exceed DockerOption.MaxExtractSize. The files are held in memory, so the extraction doesn't read them instead of
+// running out of memory, e.g. with a decompression bomb. Only the files required by the exact paths such as
+// var/lib/dpkg/status fail the extraction, and the others matched by the globs such as *.jar are skipped,
+// see skipLargeFile.
+type SizeLimitError struct {
+	// Path is the file being extracted when the limit is exceeded
+	Path string
+	// Limit is the name of the limit, i.e. "MaxFileSize" or "MaxExtractSize", or the pattern of FileSizeLimits
+	Limit string
+	// Max is the limit in bytes
+	Max int64
+	// Size is the size of the file for MaxFileSize, and the total size with the file for MaxExtractSize
+	Size int64
+}
+
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("%s: %d bytes exceed the %s of %d bytes", e.Path, e.Size, e.Limit, e.Max)
+}
+
+// skipLargeFile reports whether the error is SizeLimitError of the file which is not required by an exact path,
+// or of FileSizeLimits. The file is skipped with a log, not to lose the OS and the packages for a large jar file.
+func skipLargeFile(err error, filter fileFilter, filePath string) bool {
+	var sizeErr *SizeLimitError
+	if !xerrors.As(err, &sizeErr) {
+		return false
+	}
+	if filter.isExactPath(filePath) && (sizeErr.Limit == limitMaxFileSize || sizeErr.Limit == limitMaxExtractSize) {
+		return false
+	}
+	log.Printf("skip %s", err)
+	return true
+}
+
+// sizeLimiter reads the files of an extraction within MaxFileSize, FileSizeLimits and MaxExtractSize.
+type sizeLimiter struct {
+	maxFileSize    int64
+	maxExtractSize int64
+	// patterns are the sorted patterns of fileSizeLimits, which are checked in this order
+	patterns       []string
+	fileSizeLimits map[string]int64
+	// total is the size of the files read so far
+	total int64
+}
+
+// newSizeLimiter returns the limiter of the option, where 0 is the default and a negative size is no limit.
+func newSizeLimiter(option DockerOption) *sizeLimiter {
+	l := &sizeLimiter{maxFileSize: option.MaxFileSize, maxExtractSize: option.MaxExtractSize,
+		fileSizeLimits: option.FileSizeLimits}
+	for pattern := range option.FileSizeLimits {
+		l.patterns = append(l.patterns, pattern)
+	}
+	sort.Strings(l.patterns)
+	if l.maxFileSize == 0 {
+		l.maxFileSize = DefaultMaxFileSize
+	}
+	if l.maxExtractSize == 0 {
+		l.maxExtractSize = DefaultMaxExtractSize
+	}
+	return l
+}
+
+// checkSize returns SizeLimitError if the file is larger than MaxFileSize or FileSizeLimits, before it is read.
+func (l *sizeLimiter) checkSize(filePath string, size int64) error {
+	if l.maxFileSize > 0 && size > l.maxFileSize {
+		return &SizeLimitError{Path: filePath, Limit: limitMaxFileSize, Max: l.maxFileSize, Size: size}
+	}
+	for _, pattern := range l.patterns {
+		if max := l.fileSizeLimits[pattern]; size > max && Match(pattern, filePath) {
+			return &SizeLimitError{Path: filePath, Limit: pattern, Max: max, Size: size}
+		}
+	}
+	return nil
+}
+
+// readFile reads the file of the declared size, e.g. the size in the tar header, and the content beyond the size
+// is not read. archive/tar fails for the entries shorter or longer than the size in the header.
+// SizeLimitError is returned for the file larger than MaxFileSize and the total size exceeding MaxExtractSize
+// without reading the file.
+func (l *sizeLimiter) readFile(filePath string, r io.Reader, size int64) ([]byte, error) {
+	if err := l.checkSize(filePath, size); err != nil {
+		return nil, err
+	}
+	if l.maxExtractSize > 0 && l.total+size > l.maxExtractSize {
+		return nil, &SizeLimitError{Path: filePath, Limit: limitMaxExtractSize, Max: l.maxExtractSize, Size: l.total + size}
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read file: %w", err)
+	}
+	l.total += int64(len(b))
+	return b, nil
+}
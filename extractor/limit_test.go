@@ -0,0 +1,165 @@
+package extractor
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/xerrors"
+)
+
+func TestSizeLimiter(t *testing.T) {
+	var tests = map[string]struct {
+		option   DockerOption
+		files    []string
+		sizes    []int64
+		expected *SizeLimitError
+	}{
+		"Default": {
+			files: []string{"etc/os-release", "app/package-lock.json"},
+			sizes: []int64{4, 4},
+		},
+		"MaxExtractSize": {
+			option:   DockerOption{MaxExtractSize: 6},
+			files:    []string{"etc/os-release", "app/package-lock.json"},
+			sizes:    []int64{4, 4},
+			expected: &SizeLimitError{Path: "app/package-lock.json", Limit: "MaxExtractSize", Max: 6, Size: 8},
+		},
+		"NoLimit": {
+			option: DockerOption{MaxExtractSize: -1},
+			files:  []string{"etc/os-release", "app/package-lock.json"},
+			sizes:  []int64{4, 4},
+		},
+	}
+	for testName, v := range tests {
+		limiter := newSizeLimiter(v.option)
+		var err error
+		for i, file := range v.files {
+			if _, err = limiter.readFile(file, strings.NewReader("test"), v.sizes[i]); err != nil {
+				break
+			}
+		}
+		if v.expected == nil {
+			if err != nil {
+				t.Errorf("[%s] unexpected error: %v", testName, err)
+			}
+			continue
+		}
+		var sizeErr *SizeLimitError
+		if !xerrors.As(err, &sizeErr) || !reflect.DeepEqual(v.expected, sizeErr) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, err)
+		}
+	}
+}
+
+func TestSizeLimiter_CheckSize(t *testing.T) {
+	var tests = map[string]struct {
+		maxFileSize    int64
+		fileSizeLimits map[string]int64
+		size           int64
+		expected       error
+	}{
+		"Default":      {size: DefaultMaxFileSize + 1, expected: &SizeLimitError{Path: "foo", Limit: "MaxFileSize", Max: DefaultMaxFileSize, Size: DefaultMaxFileSize + 1}},
+		"UnderDefault": {size: DefaultMaxFileSize},
+		"MaxFileSize":  {maxFileSize: 3, size: 4, expected: &SizeLimitError{Path: "foo", Limit: "MaxFileSize", Max: 3, Size: 4}},
+		"NoLimit":      {maxFileSize: -1, size: DefaultMaxFileSize + 1},
+		"FileSizeLimits": {
+			fileSizeLimits: map[string]int64{"*.jar": 2, "f*": 3},
+			size:           4,
+			expected:       &SizeLimitError{Path: "foo", Limit: "f*", Max: 3, Size: 4},
+		},
+		"UnderFileSizeLimits": {fileSizeLimits: map[string]int64{"f*": 4}, size: 4},
+	}
+	for testName, v := range tests {
+		option := DockerOption{MaxFileSize: v.maxFileSize, FileSizeLimits: v.fileSizeLimits}
+		actual := newSizeLimiter(option).checkSize("foo", v.size)
+		if !reflect.DeepEqual(v.expected, actual) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestExtractFiles_SizeLimit(t *testing.T) {
+	var tests = map[string]struct {
+		option    DockerOption
+		filenames []string
+		expected  FileMap
+		err       bool
+	}{
+		// etc/test/bar is 4 bytes
+		"UnderMaxFileSize": {
+			option:    DockerOption{MaxFileSize: 4},
+			filenames: []string{"etc/test/bar"},
+			expected:  MapFileMap{"etc/test/bar": []byte("bar\n"), "var/.wh.foo": []byte{}},
+		},
+		"MaxFileSize": {
+			option:    DockerOption{MaxFileSize: 3},
+			filenames: []string{"etc/test/bar"},
+			err:       true,
+		},
+		"MaxExtractSize": {
+			option:    DockerOption{MaxExtractSize: 3},
+			filenames: []string{"etc/test/bar"},
+			err:       true,
+		},
+		// The large files matched by the globs are skipped
+		"MaxFileSizeGlob": {
+			option:    DockerOption{MaxFileSize: 3},
+			filenames: []string{"etc/test/b*"},
+			expected:  MapFileMap{"var/.wh.foo": []byte{}},
+		},
+		// FileSizeLimits skips the files required by the exact paths as well
+		"FileSizeLimits": {
+			option:    DockerOption{FileSizeLimits: map[string]int64{"etc/test/*": 3}},
+			filenames: []string{"etc/test/bar"},
+			expected:  MapFileMap{"var/.wh.foo": []byte{}},
+		},
+		"MaxExtractSizeName": {
+			option:    DockerOption{MaxExtractSize: 3},
+			filenames: []string{"bar"},
+			expected:  MapFileMap{"var/.wh.foo": []byte{}},
+		},
+	}
+	for testName, v := range tests {
+		f, err := os.Open("testdata/opq2.tar")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileMap, _, err := NewDockerExtractor(WithDockerOption(v.option)).ExtractFiles(f, v.filenames)
+		f.Close()
+		if v.err {
+			var sizeErr *SizeLimitError
+			if !xerrors.As(err, &sizeErr) || sizeErr.Path != "etc/test/bar" {
+				t.Errorf("[%s] SizeLimitError of etc/test/bar is expected: %v", testName, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.expected, fileMap) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, fileMap)
+		}
+	}
+}
+
+func TestExtractFiles_TruncatedEntry(t *testing.T) {
+	// The header of etc/os-release declares 10 bytes, but the layer ends after 4 bytes of the content
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "etc/os-release", Mode: 0644, Size: 10, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("ID=alpine\n")); err != nil {
+		t.Fatal(err)
+	}
+	layer := buf.Bytes()[:512+4]
+
+	fileMap, _, err := DockerExtractor{}.ExtractFiles(bytes.NewReader(layer), []string{"etc/os-release"})
+	if err == nil {
+		t.Errorf("an error is expected for the truncated entry: %v", fileMap)
+	}
+}
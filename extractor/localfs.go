@@ -0,0 +1,183 @@
+package extractor
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// maxSymlinks is the limit of symlinks followed to resolve a path, which is the same as Linux.
+const maxSymlinks = 40
+
+// pseudoFileSystems are not walked when the root is the host filesystem.
+var pseudoFileSystems = []string{"proc", "sys", "dev"}
+
+// LocalFSExtractor extracts files from a root filesystem on the local disk,
+// e.g. a chroot, an unpacked VM image or "/" for the host itself.
+// Only ExcludedPaths, MaxFileSize and MaxExtractSize of Option are used to walk the root.
+type LocalFSExtractor struct {
+	Root   string
+	Option DockerOption
+}
+
+func NewLocalFSExtractor(root string) LocalFSExtractor {
+	return LocalFSExtractor{Root: root}
+}
+
+// Extract walks the root filesystem. imageName is ignored.
+// The files are matched in the same way as layers, and the unreadable files are skipped with a warning.
+// Symlinks are resolved relative to the root, so they never point to files outside the root.
+func (l LocalFSExtractor) Extract(ctx context.Context, _ string, filenames []string) (FileMap, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	root, err := filepath.Abs(l.Root)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid root: %w", err)
+	}
+	if fi, err := os.Stat(root); err != nil {
+		return nil, xerrors.Errorf("invalid root: %w", err)
+	} else if !fi.IsDir() {
+		return nil, xerrors.Errorf("root is not a directory: %s", root)
+	}
+
+	fileMap := MapFileMap{}
+	filter := newFileFilter(filenames, l.Option.ExcludedPaths)
+	limiter := newSizeLimiter(l.Option)
+	err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		select {
+		case <-ctx.Done():
+			return xerrors.Errorf("timeout: %w", ctx.Err())
+		default:
+		}
+
+		rel, relErr := filepath.Rel(root, p)
+		if relErr != nil {
+			return relErr
+		}
+		filePath := filepath.ToSlash(rel)
+		if err != nil {
+			log.Printf("skip %s: %s", p, err)
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			if root == string(filepath.Separator) && isPseudoFileSystem(filePath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() && info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+		if !filter.isRequired(filePath) {
+			return nil
+		}
+
+		b, err := l.readFile(root, filePath, limiter)
+		var sizeErr *SizeLimitError
+		if skipLargeFile(err, filter, filePath) {
+			return nil
+		} else if xerrors.As(err, &sizeErr) {
+			return err
+		} else if err != nil {
+			log.Printf("skip %s: %s", p, err)
+			return nil
+		}
+		if b != nil {
+			fileMap[filePath] = b
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to walk %s: %w", root, err)
+	}
+	return fileMap, nil
+}
+
+// readFile returns nil for the symlink to a non-regular file.
+func (l LocalFSExtractor) readFile(root, filePath string, limiter *sizeLimiter) ([]byte, error) {
+	resolved, err := resolveInRoot(root, filePath)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, nil
+	}
+	f, err := os.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return limiter.readFile(filePath, f, fi.Size())
+}
+
+func (l LocalFSExtractor) ExtractFromFile(ctx context.Context, r io.ReadCloser, filenames []string) (FileMap, error) {
+	return NewDockerExtractor(WithDockerOption(l.Option)).ExtractFromFile(ctx, r, filenames)
+}
+
+func isPseudoFileSystem(filePath string) bool {
+	for _, dir := range pseudoFileSystems {
+		if filePath == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveInRoot resolves the symlinks in the slash-separated path as if root were "/".
+// Absolute targets are relative to the root, and ".." stops at the root.
+func resolveInRoot(root, filePath string) (string, error) {
+	resolved := ""
+	pending := strings.Split(filePath, "/")
+	links := 0
+	for len(pending) > 0 {
+		elem := pending[0]
+		pending = pending[1:]
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if resolved = path.Dir(resolved); resolved == "." {
+				resolved = ""
+			}
+			continue
+		}
+
+		next := path.Join(resolved, elem)
+		fi, err := os.Lstat(filepath.Join(root, filepath.FromSlash(next)))
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		if links++; links > maxSymlinks {
+			return "", xerrors.Errorf("too many levels of symbolic links: %s", filePath)
+		}
+		target, err := os.Readlink(filepath.Join(root, filepath.FromSlash(next)))
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) {
+			resolved = ""
+		}
+		pending = append(strings.Split(target, "/"), pending...)
+	}
+	return filepath.Join(root, filepath.FromSlash(resolved)), nil
+}
@@ -0,0 +1,123 @@
+package extractor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLocalFSExtractor_Extract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The file outside the root must not be read through symlinks
+	if err = ioutil.WriteFile(filepath.Join(dir, "os-release"), []byte("ID=outside\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(dir, "rootfs")
+	files := map[string]string{
+		"usr/lib/os-release":                "ID=debian\n",
+		"var/lib/dpkg/status":               "Package: bash\n",
+		"app/Gemfile.lock":                  "GEM\n",
+		"app/node_modules/foo/package.json": "{}",
+		"etc/hostname":                      "localhost\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	symlinks := map[string]string{
+		"etc/os-release":     "../usr/lib/os-release",
+		"etc/lsb-release":    "/usr/lib/os-release",
+		"etc/redhat-release": "../../../os-release",
+		"etc/debian_version": "/etc/debian_version",
+		"etc/system-release": "/missing",
+		"lib":                "usr/lib",
+	}
+	for name, target := range symlinks {
+		if err = os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := NewLocalFSExtractor(root)
+	e.Option.ExcludedPaths = []string{"**/node_modules/**"}
+	actual, err := e.Extract(context.Background(), "", []string{
+		"etc/os-release", "etc/lsb-release", "etc/redhat-release", "etc/debian_version", "etc/system-release",
+		"usr/lib/os-release", "var/lib/dpkg/status", "Gemfile.lock", "package.json",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := MapFileMap{
+		"etc/os-release":      []byte("ID=debian\n"),
+		"etc/lsb-release":     []byte("ID=debian\n"),
+		"usr/lib/os-release":  []byte("ID=debian\n"),
+		"var/lib/dpkg/status": []byte("Package: bash\n"),
+		"app/Gemfile.lock":    []byte("GEM\n"),
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, actual)
+	}
+}
+
+func TestResolveInRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = os.MkdirAll(filepath.Join(dir, "usr/lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, target := range map[string]string{
+		"lib":       "usr/lib",
+		"abs":       "/usr/lib",
+		"escape":    "../../../../usr",
+		"usr/lib/x": "../../lib/x",
+	} {
+		if err = os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var tests = map[string]struct {
+		filePath string
+		expected string
+		wantErr  bool
+	}{
+		"relative":  {filePath: "lib", expected: "usr/lib"},
+		"absolute":  {filePath: "abs", expected: "usr/lib"},
+		"escape":    {filePath: "escape/lib", expected: "usr/lib"},
+		"dot dot":   {filePath: "../../usr/./lib", expected: "usr/lib"},
+		"loop":      {filePath: "usr/lib/x", wantErr: true},
+		"not exist": {filePath: "missing/foo", wantErr: true},
+	}
+	for testname, v := range tests {
+		actual, err := resolveInRoot(dir, v.filePath)
+		if v.wantErr {
+			if err == nil {
+				t.Errorf("[%s]\nexpected error but got nil", testname)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s]\nunexpected error: %v", testname, err)
+			continue
+		}
+		if expected := filepath.Join(dir, v.expected); actual != expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, expected, actual)
+		}
+	}
+}
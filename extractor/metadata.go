@@ -0,0 +1,165 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/genuinetools/reg/registry"
+	digest "github.com/opencontainers/go-digest"
+	"golang.org/x/xerrors"
+)
+
+// ImageConfig is the part of the image config describing how the container runs.
+type ImageConfig struct {
+	OS           string
+	Architecture string
+	Created      time.Time
+	User         string
+	Env          []string
+	Entrypoint   []string
+	Cmd          []string
+	WorkingDir   string
+	// ExposedPorts is e.g. "80/tcp".
+	ExposedPorts []string
+	Labels       map[string]string
+}
+
+// LayerDescriptor is a layer in the manifest.
+type LayerDescriptor struct {
+	// Digest is the digest of the layer blob. It is the diff ID for the layers of docker save, which are not blobs.
+	Digest    string
+	MediaType string
+	// Size is the size of the layer blob, which is compressed in the registry.
+	Size int64
+	// UncompressedSize is the size of the layer tar, and FileCount is the number of the entries in it including
+	// the directories and the whiteouts. They are 0 for FetchImageMetadata, which doesn't download the layers.
+	UncompressedSize int64
+	FileCount        int
+}
+
+// buildkitMediaTypePrefix is the prefix of the media types of BuildKit, e.g. application/vnd.buildkit.cacheconfig.v0.
+const buildkitMediaTypePrefix = "application/vnd.buildkit."
+
+// IsBuildkitCacheLayer reports whether the layer is a blob of the BuildKit cache, e.g. pushed with
+// "docker buildx build --cache-to=type=registry", which is skipped in the extraction.
+// The detection relies only on the media type: the cache config is application/vnd.buildkit.cacheconfig.v0
+// and the other BuildKit blobs are application/vnd.buildkit.* as well. The cached layers themselves have
+// the standard media types of layers, so they are extracted like the layers of images.
+func IsBuildkitCacheLayer(descriptor LayerDescriptor) bool {
+	return strings.HasPrefix(descriptor.MediaType, buildkitMediaTypePrefix)
+}
+
+// imageConfig is the JSON of the image config.
+// https://github.com/opencontainers/image-spec/blob/master/config.md
+type imageConfig struct {
+	History      []HistoryEntry `json:"history"`
+	OS           string         `json:"os"`
+	Architecture string         `json:"architecture"`
+	Created      time.Time      `json:"created"`
+	RootFS       struct {
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+	Config struct {
+		User         string              `json:"User"`
+		Env          []string            `json:"Env"`
+		Entrypoint   []string            `json:"Entrypoint"`
+		Cmd          []string            `json:"Cmd"`
+		WorkingDir   string              `json:"WorkingDir"`
+		ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+		Labels       map[string]string   `json:"Labels"`
+	} `json:"config"`
+}
+
+// FetchImageMetadata fetches the manifest and the config of the image in the registry without the layers,
+// e.g. to check the image before pulling it. Executables is not set.
+func FetchImageMetadata(ctx context.Context, imageName string, opts ...DockerExtractorOption) (ImageMetadata, error) {
+	d := NewDockerExtractor(opts...)
+	if d.Option.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Option.Timeout)
+		defer cancel()
+	}
+
+	r, image, m, metadata, err := d.fetchManifest(ctx, imageName)
+	if err != nil {
+		return ImageMetadata{}, err
+	}
+
+	config, err := fetchConfig(ctx, r, image, m)
+	if err != nil {
+		return ImageMetadata{}, err
+	}
+	metadata.Config = convertImageConfig(config)
+	metadata.DiffIDs = config.RootFS.DiffIDs
+	metadata.History = config.History
+	return metadata, nil
+}
+
+// fetchConfig fetches the image config of the manifest.
+func fetchConfig(ctx context.Context, r *registry.Registry, image registry.Image, m *schema2.DeserializedManifest) (imageConfig, error) {
+	rc, err := r.DownloadLayer(ctx, image.Path, m.Manifest.Config.Digest)
+	if err != nil {
+		return imageConfig{}, xerrors.Errorf("failed to download the config: %w", err)
+	}
+	defer rc.Close()
+	var config imageConfig
+	if err = json.NewDecoder(rc).Decode(&config); err != nil {
+		return imageConfig{}, xerrors.Errorf("invalid image config: %w", err)
+	}
+	return config, nil
+}
+
+// fetchManifest fetches the v2 manifest and returns the metadata with the layers.
+func (d DockerExtractor) fetchManifest(ctx context.Context, imageName string) (*registry.Registry, registry.Image, *schema2.DeserializedManifest, ImageMetadata, error) {
+	image, err := registry.ParseImage(imageName)
+	if err != nil {
+		return nil, registry.Image{}, nil, ImageMetadata{}, err
+	}
+	r, err := d.createRegistryClient(ctx, image.Domain)
+	if err != nil {
+		return nil, registry.Image{}, nil, ImageMetadata{}, err
+	}
+
+	m, platform, err := d.resolveManifest(ctx, r, image)
+	if err != nil {
+		return nil, registry.Image{}, nil, ImageMetadata{}, err
+	}
+	_, payload, err := m.Payload()
+	if err != nil {
+		return nil, registry.Image{}, nil, ImageMetadata{}, xerrors.Errorf("failed to get the manifest payload: %w", err)
+	}
+
+	metadata := ImageMetadata{
+		Digest:   string(digest.FromBytes(payload)),
+		ID:       string(m.Manifest.Config.Digest),
+		Platform: platform,
+	}
+	for _, l := range m.Manifest.Layers {
+		metadata.Layers = append(metadata.Layers, LayerDescriptor{Digest: string(l.Digest), MediaType: l.MediaType, Size: l.Size})
+		metadata.TotalSizeBytes += l.Size
+	}
+	return r, image, m, metadata, nil
+}
+
+func convertImageConfig(c imageConfig) ImageConfig {
+	config := ImageConfig{
+		OS:           c.OS,
+		Architecture: c.Architecture,
+		Created:      c.Created,
+		User:         c.Config.User,
+		Env:          c.Config.Env,
+		Entrypoint:   c.Config.Entrypoint,
+		Cmd:          c.Config.Cmd,
+		WorkingDir:   c.Config.WorkingDir,
+		Labels:       c.Config.Labels,
+	}
+	for port := range c.Config.ExposedPorts {
+		config.ExposedPorts = append(config.ExposedPorts, port)
+	}
+	sort.Strings(config.ExposedPorts)
+	return config
+}
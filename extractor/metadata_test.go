@@ -0,0 +1,192 @@
+package extractor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+const testImageConfig = `{
+  "architecture": "amd64",
+  "os": "linux",
+  "created": "2019-05-01T00:00:00Z",
+  "rootfs": {
+    "type": "layers",
+    "diff_ids": ["sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc", "sha256:dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd"]
+  },
+  "config": {
+    "User": "nobody",
+    "Env": ["PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"],
+    "Cmd": ["nginx", "-g", "daemon off;"],
+    "WorkingDir": "/app",
+    "ExposedPorts": {"80/tcp": {}, "443/tcp": {}},
+    "Labels": {"maintainer": "NGINX Docker Maintainers"}
+  }
+}`
+
+func TestFetchImageMetadata(t *testing.T) {
+	configDigest := digest.FromString(testImageConfig)
+	manifest := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "` + configDigest.String() + `"},
+  "layers": [
+    {"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 1000, "digest": "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+    {"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 234, "digest": "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}
+  ]
+}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifest))
+		case "/v2/app/blobs/" + configDigest.String():
+			w.Write([]byte(testImageConfig))
+		default:
+			// Layers must not be downloaded
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	imageName := strings.TrimPrefix(ts.URL, "http://") + "/app:1.0"
+	metadata, err := FetchImageMetadata(context.Background(), imageName,
+		WithDockerOption(DockerOption{NonSSL: true}), WithTimeout(10*time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := ImageMetadata{
+		Digest: digest.FromString(manifest).String(),
+		ID:     configDigest.String(),
+		DiffIDs: []string{
+			"sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc",
+			"sha256:dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd",
+		},
+		Config: ImageConfig{
+			OS:           "linux",
+			Architecture: "amd64",
+			Created:      time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC),
+			User:         "nobody",
+			Env:          []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"},
+			Cmd:          []string{"nginx", "-g", "daemon off;"},
+			WorkingDir:   "/app",
+			ExposedPorts: []string{"443/tcp", "80/tcp"},
+			Labels:       map[string]string{"maintainer": "NGINX Docker Maintainers"},
+		},
+		Layers: []LayerDescriptor{
+			{Digest: "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Size: 1000},
+			{Digest: "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Size: 234},
+		},
+		TotalSizeBytes: 1234,
+	}
+	if !reflect.DeepEqual(expected, metadata) {
+		t.Errorf("\nexpected : %+v\nactual : %+v", expected, metadata)
+	}
+}
+
+func TestIsBuildkitCacheLayer(t *testing.T) {
+	var tests = map[string]bool{
+		"application/vnd.buildkit.cacheconfig.v0":           true,
+		"application/vnd.docker.image.rootfs.diff.tar.gzip": false,
+		"application/vnd.oci.image.layer.v1.tar+gzip":       false,
+		"application/vnd.docker.container.image.v1+json":    false,
+		"": false,
+	}
+	for mediaType, expected := range tests {
+		if actual := IsBuildkitCacheLayer(LayerDescriptor{MediaType: mediaType}); actual != expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", mediaType, expected, actual)
+		}
+	}
+}
+
+func TestFetchImageMetadata_Platform(t *testing.T) {
+	configDigest := digest.FromString(testImageConfig)
+	manifests := map[string]string{}
+	for _, arch := range []string{"amd64", "arm64"} {
+		manifests[arch] = `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "` + configDigest.String() + `"},
+  "layers": [{"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 100, "digest": "sha256:` + strings.Repeat(arch[:1], 64) + `"}]
+}`
+	}
+	amd64Digest, arm64Digest := digest.FromString(manifests["amd64"]), digest.FromString(manifests["arm64"])
+	list := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.list.v2+json",
+  "manifests": [
+    {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "size": 100, "digest": "` + arm64Digest.String() + `", "platform": {"architecture": "arm64", "os": "linux", "variant": "v8"}},
+    {"mediaType": "application/vnd.docker.distribution.manifest.v2+json", "size": 100, "digest": "` + amd64Digest.String() + `", "platform": {"architecture": "amd64", "os": "linux"}}
+  ]
+}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.list.v2+json")
+			w.Write([]byte(list))
+		case "/v2/app/manifests/" + amd64Digest.String():
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifests["amd64"]))
+		case "/v2/app/manifests/" + arm64Digest.String():
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifests["arm64"]))
+		case "/v2/app/blobs/" + configDigest.String():
+			w.Write([]byte(testImageConfig))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	var tests = map[string]struct {
+		platform         string
+		expectedPlatform string
+		expectedDigest   digest.Digest
+		wantErr          string
+	}{
+		"default": {
+			expectedPlatform: "linux/amd64",
+			expectedDigest:   amd64Digest,
+		},
+		"arm64": {
+			platform:         "linux/arm64",
+			expectedPlatform: "linux/arm64/v8",
+			expectedDigest:   arm64Digest,
+		},
+		"unknown platform": {
+			platform: "linux/s390x",
+			wantErr:  "available platforms: linux/arm64/v8, linux/amd64",
+		},
+	}
+	for testname, v := range tests {
+		metadata, err := FetchImageMetadata(context.Background(), strings.TrimPrefix(ts.URL, "http://")+"/app:1.0",
+			WithDockerOption(DockerOption{NonSSL: true, Platform: v.platform}), WithTimeout(10*time.Second))
+		if v.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), v.wantErr) {
+				t.Errorf("[%s]\nexpected error : %s\nactual : %v", testname, v.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testname, err)
+			continue
+		}
+		if metadata.Platform != v.expectedPlatform || metadata.Digest != v.expectedDigest.String() {
+			t.Errorf("[%s]\nexpected : %s %s\nactual : %s %s", testname, v.expectedPlatform, v.expectedDigest, metadata.Platform, metadata.Digest)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package extractor
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// MIME types detected in addition to http.DetectContentType
+const (
+	MIMETypeELF  = "application/x-elf"
+	MIMETypeZIP  = "application/zip"
+	MIMETypeTar  = "application/x-tar"
+	MIMETypeGzip = "application/x-gzip"
+	MIMETypeXML  = "text/xml"
+)
+
+// FileMetadata is the metadata of a file in FileMap.
+type FileMetadata struct {
+	Size     int64
+	MIMEType string
+}
+
+// Metadata returns the metadata of the file.
+// The MIME type is detected from the content, so analyzers can skip binary files matching the required files.
+func (m MapFileMap) Metadata(filePath string) (FileMetadata, bool) {
+	content, ok := m[filePath]
+	if !ok {
+		return FileMetadata{}, false
+	}
+	return FileMetadata{Size: int64(len(content)), MIMEType: DetectMIMEType(content)}, true
+}
+
+// DetectMIMEType returns the MIME type of the content without parameters such as charset,
+// e.g. "text/plain", "application/zip" and "application/octet-stream".
+// ELF binaries, tar archives and XML with leading spaces are detected by the magic bytes,
+// which http.DetectContentType doesn't recognize.
+func DetectMIMEType(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte("\x7fELF")):
+		return MIMETypeELF
+	case bytes.HasPrefix(content, []byte("PK\x03\x04")), bytes.HasPrefix(content, []byte("PK\x05\x06")):
+		// The latter is an empty archive
+		return MIMETypeZIP
+	case bytes.HasPrefix(content, []byte("\x1f\x8b")):
+		return MIMETypeGzip
+	case len(content) >= 262 && bytes.Equal(content[257:262], []byte("ustar")):
+		return MIMETypeTar
+	case bytes.HasPrefix(bytes.TrimLeft(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), " \t\r\n"), []byte("<?xml")):
+		return MIMETypeXML
+	}
+
+	mimeType := http.DetectContentType(content)
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.TrimSpace(mimeType)
+}
+
+// IsText reports whether the MIME type is textual, e.g. "text/plain" and "text/xml".
+func IsText(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "text/")
+}
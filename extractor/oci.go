@@ -0,0 +1,238 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	"golang.org/x/xerrors"
+)
+
+const (
+	ociRefNameAnnotation    = "org.opencontainers.image.ref.name"
+	maxOCIIndexNestingLevel = 8
+)
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      digest.Digest     `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+		Variant      string `json:"variant"`
+	} `json:"platform"`
+}
+
+// ociIndex is index.json or an image index blob.
+type ociIndex struct {
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	Config ociDescriptor   `json:"config"`
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// ExtractFromOCILayout extracts files from the OCI image layout directory created by skopeo, buildah, crane, etc.
+// When index.json has multiple manifests, ref selects the one with the org.opencontainers.image.ref.name annotation,
+// e.g. "latest" for "oci:dir:latest" of skopeo. An empty ref selects the only image in the layout.
+// Multi-arch images are resolved to the manifest of Option.Platform.
+func (d DockerExtractor) ExtractFromOCILayout(ctx context.Context, dir, ref string, filenames []string) (FileMap, error) {
+	if _, err := os.Stat(filepath.Join(dir, "oci-layout")); err != nil {
+		return nil, xerrors.Errorf("invalid OCI image layout: %w", err)
+	}
+	var index ociIndex
+	if err := readJSON(filepath.Join(dir, "index.json"), &index); err != nil {
+		return nil, xerrors.Errorf("failed to read index.json: %w", err)
+	}
+
+	desc, err := d.selectOCIImage(index.Manifests, ref)
+	if err != nil {
+		return nil, err
+	}
+	fileMap, _, err := d.extractOCIImage(ctx, dir, desc.Digest, filenames)
+	return fileMap, err
+}
+
+// extractOCIImage extracts files of the image manifest or the image index in blobs/ of the directory.
+func (d DockerExtractor) extractOCIImage(ctx context.Context, dir string, dgst digest.Digest, filenames []string) (FileMap, ImageMetadata, error) {
+	m, err := d.resolveOCIManifest(dir, ociDescriptor{Digest: dgst}, 0)
+	if err != nil {
+		return nil, ImageMetadata{}, err
+	}
+
+	layerIDs := []string{}
+	extracted := make(map[string]extractedLayer)
+	filter := newFileFilter(filenames, d.Option.ExcludedPaths)
+	limiter := newSizeLimiter(d.Option)
+	var layers []LayerDescriptor
+	for _, l := range m.Layers {
+		descriptor := LayerDescriptor{Digest: string(l.Digest), MediaType: l.MediaType, Size: l.Size}
+		if IsBuildkitCacheLayer(descriptor) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ImageMetadata{}, xerrors.Errorf("timeout: %w", ctx.Err())
+		default:
+		}
+
+		layerID := string(l.Digest)
+		el, err := d.extractOCIBlob(dir, l, filter, limiter)
+		if err != nil {
+			return nil, ImageMetadata{}, xerrors.Errorf("failed to extract the layer(%s): %w", layerID, err)
+		}
+		descriptor.UncompressedSize = el.descriptor.UncompressedSize
+		descriptor.FileCount = el.descriptor.FileCount
+		layers = append(layers, descriptor)
+		layerIDs = append(layerIDs, layerID)
+		extracted[layerID] = el
+	}
+
+	fileMap, err := applyLayers(layerIDs, extracted)
+	if err != nil {
+		return nil, ImageMetadata{}, err
+	}
+	var config imageConfig
+	if err = readBlobJSON(dir, m.Config.Digest, &config); err != nil {
+		return nil, ImageMetadata{}, xerrors.Errorf("failed to read the config(%s): %w", m.Config.Digest, err)
+	}
+	metadata := ImageMetadata{
+		Digest:  string(dgst),
+		ID:      string(m.Config.Digest),
+		DiffIDs: config.RootFS.DiffIDs,
+		Layers:  layers,
+		History: config.History,
+	}
+	for _, l := range layers {
+		metadata.TotalSizeBytes += l.Size
+	}
+	if metadata.Executables, err = applyExecutables(layerIDs, extracted); err != nil {
+		return nil, ImageMetadata{}, err
+	}
+	return fileMap, metadata, nil
+}
+
+// selectOCIImage returns the manifest with the reference name.
+// Without the reference name, index.json must have only one image, possibly multi-arch.
+func (d DockerExtractor) selectOCIImage(descs []ociDescriptor, ref string) (ociDescriptor, error) {
+	if ref != "" {
+		for _, desc := range descs {
+			if desc.Annotations[ociRefNameAnnotation] == ref {
+				return desc, nil
+			}
+		}
+		return ociDescriptor{}, xerrors.Errorf("no image with the reference name: %s", ref)
+	}
+	if len(descs) == 1 {
+		return descs[0], nil
+	}
+	for _, desc := range descs {
+		if desc.Annotations[ociRefNameAnnotation] != "" {
+			return ociDescriptor{}, xerrors.New("multiple images in the OCI image layout, specify the reference name")
+		}
+	}
+	// e.g. crane writes the manifest of each platform into index.json
+	return selectPlatform(descs, d.Option.Platform)
+}
+
+// resolveOCIManifest follows nested indexes until the image manifest of the platform.
+// The media type is not trusted as it is often omitted, e.g. in index.json written by old tools.
+func (d DockerExtractor) resolveOCIManifest(dir string, desc ociDescriptor, level int) (ociManifest, error) {
+	if level > maxOCIIndexNestingLevel {
+		return ociManifest{}, xerrors.New("too deeply nested image index")
+	}
+
+	var blob struct {
+		ociIndex
+		ociManifest
+	}
+	if err := readBlobJSON(dir, desc.Digest, &blob); err != nil {
+		return ociManifest{}, xerrors.Errorf("failed to read the manifest(%s): %w", desc.Digest, err)
+	}
+	switch {
+	case len(blob.Manifests) > 0:
+		platform, err := selectPlatform(blob.Manifests, d.Option.Platform)
+		if err != nil {
+			return ociManifest{}, err
+		}
+		return d.resolveOCIManifest(dir, platform, level+1)
+	case blob.Config.Digest != "":
+		return blob.ociManifest, nil
+	}
+	return ociManifest{}, xerrors.Errorf("unsupported manifest: %s", desc.Digest)
+}
+
+// selectPlatform returns the manifest matching "os/arch[/variant]".
+func selectPlatform(descs []ociDescriptor, platform string) (ociDescriptor, error) {
+	if len(descs) == 0 {
+		return ociDescriptor{}, xerrors.New("empty image index")
+	}
+	if platform == "" {
+		return descs[0], nil
+	}
+	ss := strings.SplitN(platform, "/", 3)
+	for _, desc := range descs {
+		p := desc.Platform
+		if p == nil || len(ss) < 2 || p.OS != ss[0] || p.Architecture != ss[1] {
+			continue
+		}
+		if len(ss) == 3 && p.Variant != ss[2] {
+			continue
+		}
+		return desc, nil
+	}
+	var available []string
+	for _, desc := range descs {
+		if p := desc.platform(); p != "" {
+			available = append(available, p)
+		}
+	}
+	return ociDescriptor{}, xerrors.Errorf("no image for the platform %s, available platforms: %s", platform, strings.Join(available, ", "))
+}
+
+func (d DockerExtractor) extractOCIBlob(dir string, desc ociDescriptor, filter fileFilter, limiter *sizeLimiter) (extractedLayer, error) {
+	f, err := openBlob(dir, desc.Digest)
+	if err != nil {
+		return extractedLayer{}, err
+	}
+	defer f.Close()
+
+	r, err := decompressLayer(f, desc.MediaType)
+	if err != nil {
+		return extractedLayer{}, err
+	}
+	defer r.Close()
+	return d.extractLayer(r, filter, limiter)
+}
+
+// openBlob opens e.g. blobs/sha256/<hex>
+func openBlob(dir string, dgst digest.Digest) (*os.File, error) {
+	if err := dgst.Validate(); err != nil {
+		return nil, xerrors.Errorf("invalid digest: %w", err)
+	}
+	return os.Open(filepath.Join(dir, "blobs", dgst.Algorithm().String(), dgst.Hex()))
+}
+
+func readBlobJSON(dir string, dgst digest.Digest, v interface{}) error {
+	f, err := openBlob(dir, dgst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewDecoder(f).Decode(v)
+}
+
+func readJSON(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewDecoder(f).Decode(v)
+}
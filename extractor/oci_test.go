@@ -0,0 +1,261 @@
+package extractor
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+const containerdManifest = "sha256:e7a56131d409fa318e6f5570be244cee963176f3f90942298eefbf1ed3245488"
+
+// unpackLayout unpacks the OCI image layout in the tar file, e.g. exported by containerd
+func unpackLayout(t *testing.T, file, dir string) {
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, hdr.Name)
+		if hdr.Typeflag == tar.TypeDir {
+			os.MkdirAll(path, 0755)
+			continue
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExtractFromOCILayout(t *testing.T) {
+	// The index of the multi-arch image pointing to the same manifest
+	multiArch := []byte(`{"schemaVersion": 2, "manifests": [
+		{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:0000000000000000000000000000000000000000000000000000000000000000", "platform": {"os": "linux", "architecture": "amd64"}},
+		{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + containerdManifest + `", "platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}}
+	]}`)
+	multiArchDigest := digest.FromBytes(multiArch)
+
+	var tests = map[string]struct {
+		index    string
+		ref      string
+		platform string
+		expected FileMap
+		err      bool
+	}{
+		"Single": {
+			expected: MapFileMap{"etc/test/bar": []byte("bar\n")},
+		},
+		"Ref": {
+			index: `{"schemaVersion": 2, "manifests": [
+				{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:0000000000000000000000000000000000000000000000000000000000000000", "annotations": {"org.opencontainers.image.ref.name": "old"}},
+				{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + containerdManifest + `", "annotations": {"org.opencontainers.image.ref.name": "latest"}}
+			]}`,
+			ref:      "latest",
+			expected: MapFileMap{"etc/test/bar": []byte("bar\n")},
+		},
+		"NoRef": {
+			index: `{"schemaVersion": 2, "manifests": [
+				{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:0000000000000000000000000000000000000000000000000000000000000000", "annotations": {"org.opencontainers.image.ref.name": "old"}},
+				{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + containerdManifest + `", "annotations": {"org.opencontainers.image.ref.name": "latest"}}
+			]}`,
+			err: true,
+		},
+		"UnknownRef": {
+			ref: "unknown",
+			err: true,
+		},
+		"NestedIndex": {
+			index: `{"schemaVersion": 2, "manifests": [
+				{"mediaType": "application/vnd.oci.image.index.v1+json", "digest": "` + string(multiArchDigest) + `", "annotations": {"org.opencontainers.image.ref.name": "latest"}}
+			]}`,
+			platform: "linux/arm64/v8",
+			expected: MapFileMap{"etc/test/bar": []byte("bar\n")},
+		},
+		"UnknownPlatform": {
+			index: `{"schemaVersion": 2, "manifests": [
+				{"mediaType": "application/vnd.oci.image.index.v1+json", "digest": "` + string(multiArchDigest) + `"}
+			]}`,
+			platform: "linux/s390x",
+			err:      true,
+		},
+		"PlatformsInIndex": {
+			index:    string(multiArch),
+			platform: "linux/arm64",
+			expected: MapFileMap{"etc/test/bar": []byte("bar\n")},
+		},
+	}
+	for testName, v := range tests {
+		dir, err := ioutil.TempDir("", "oci")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		unpackLayout(t, "testdata/containerd.tar", dir)
+		err = ioutil.WriteFile(filepath.Join(dir, "blobs", "sha256", multiArchDigest.Hex()), multiArch, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.index != "" {
+			if err = ioutil.WriteFile(filepath.Join(dir, "index.json"), []byte(v.index), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		d := DockerExtractor{Option: DockerOption{Platform: v.platform}}
+		fileMap, err := d.ExtractFromOCILayout(context.Background(), dir, v.ref, []string{"etc/test/bar"})
+		if (err != nil) != v.err {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.expected, fileMap) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, fileMap)
+		}
+	}
+}
+
+func TestExtractFromOCILayout_BuildkitCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	unpackLayout(t, "testdata/containerd.tar", dir)
+
+	// The cache config, which is not a tar
+	cacheConfig := []byte(`{"layers": [], "records": []}`)
+	cacheDigest := digest.FromBytes(cacheConfig)
+	var m map[string]interface{}
+	if err = readJSON(filepath.Join(dir, "blobs", "sha256", digest.Digest(containerdManifest).Hex()), &m); err != nil {
+		t.Fatal(err)
+	}
+	m["layers"] = append(m["layers"].([]interface{}), map[string]interface{}{
+		"mediaType": "application/vnd.buildkit.cacheconfig.v0", "digest": cacheDigest, "size": len(cacheConfig),
+	})
+	manifest, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDigest := digest.FromBytes(manifest)
+	index := `{"schemaVersion": 2, "manifests": [{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + manifestDigest.String() + `"}]}`
+	for path, b := range map[string][]byte{
+		filepath.Join(dir, "blobs", "sha256", cacheDigest.Hex()):    cacheConfig,
+		filepath.Join(dir, "blobs", "sha256", manifestDigest.Hex()): manifest,
+		filepath.Join(dir, "index.json"):                            []byte(index),
+	} {
+		if err = ioutil.WriteFile(path, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fileMap, err := DockerExtractor{}.ExtractFromOCILayout(context.Background(), dir, "", []string{"etc/test/bar"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := MapFileMap{"etc/test/bar": []byte("bar\n")}
+	if !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+}
+
+func TestExtractFromOCILayout_Zstd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// The gzip layer and the zstd layer overwriting etc/test/bar
+	unpackLayout(t, "testdata/zstd.tar", dir)
+
+	fileMap, err := DockerExtractor{}.ExtractFromOCILayout(context.Background(), dir, "", []string{"etc/test/foo", "etc/test/bar"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := MapFileMap{"etc/test/foo": []byte("foo\n"), "etc/test/bar": []byte("bar\n")}
+	if !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+}
+
+func TestExtractFromOCILayout_MixedCompression(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeBlob := func(b []byte) digest.Digest {
+		dgst := digest.FromBytes(b)
+		if err := ioutil.WriteFile(filepath.Join(dir, "blobs", "sha256", dgst.Hex()), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return dgst
+	}
+
+	// Each layer overwrites etc/test/bar
+	var layers []map[string]interface{}
+	for _, l := range []struct {
+		compression string
+		mediaType   string
+		file        string
+	}{
+		{mediaType: "application/vnd.oci.image.layer.v1.tar", file: "etc/test/raw"},
+		{compression: "gzip", mediaType: "application/vnd.oci.image.layer.v1.tar+gzip", file: "etc/test/gzip"},
+		{compression: "xz", mediaType: "application/vnd.oci.image.layer.v1.tar+xz", file: "etc/test/xz"},
+		{compression: "zstd", mediaType: "application/vnd.oci.image.layer.v1.tar+zstd", file: "etc/test/zstd"},
+	} {
+		layer := compressLayer(t, tarLayer(t, map[string]string{l.file: "ok\n", "etc/test/bar": l.file + "\n"}), l.compression)
+		layers = append(layers, map[string]interface{}{"mediaType": l.mediaType, "digest": writeBlob(layer), "size": len(layer)})
+	}
+	config := []byte(`{"architecture": "amd64", "os": "linux", "rootfs": {"type": "layers", "diff_ids": []}}`)
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"config":        map[string]interface{}{"mediaType": "application/vnd.oci.image.config.v1+json", "digest": writeBlob(config), "size": len(config)},
+		"layers":        layers,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := `{"schemaVersion": 2, "manifests": [{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + writeBlob(manifest).String() + `"}]}`
+	for name, b := range map[string]string{"index.json": index, "oci-layout": `{"imageLayoutVersion": "1.0.0"}`} {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(b), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filenames := []string{"etc/test/raw", "etc/test/gzip", "etc/test/xz", "etc/test/zstd", "etc/test/bar"}
+	fileMap, err := DockerExtractor{}.ExtractFromOCILayout(context.Background(), dir, "", filenames)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := MapFileMap{
+		"etc/test/raw":  []byte("ok\n"),
+		"etc/test/gzip": []byte("ok\n"),
+		"etc/test/xz":   []byte("ok\n"),
+		"etc/test/zstd": []byte("ok\n"),
+		"etc/test/bar":  []byte("etc/test/zstd\n"),
+	}
+	if !reflect.DeepEqual(expected, fileMap) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, fileMap)
+	}
+}
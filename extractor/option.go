@@ -0,0 +1,130 @@
+package extractor
+
+import (
+	"crypto/tls"
+	"io"
+	"time"
+
+	"github.com/knqyf263/fanal/auth"
+	"github.com/knqyf263/fanal/cache"
+)
+
+// DockerExtractorOption configures DockerExtractor, e.g.
+//
+//	NewDockerExtractor(WithTimeout(10*time.Minute), WithCredentials(user, pass))
+type DockerExtractorOption func(*DockerExtractor)
+
+// Cache stores the layers downloaded from registries. Set returns the reader to be read instead of r,
+// which stores the layer while it is read. Get returns nil if the layer is not cached.
+type Cache interface {
+	Get(key string) io.Reader
+	Set(key string, r io.Reader) (io.Reader, error)
+}
+
+// ProgressReporter is notified of the progress of images in registries.
+type ProgressReporter interface {
+	// LayerExtracted is called when the files are extracted from a layer. done is between 1 and total.
+	LayerExtracted(layerID string, done, total int)
+}
+
+// fsCache is the default cache under the user cache directory.
+type fsCache struct{}
+
+func (fsCache) Get(key string) io.Reader {
+	return cache.Get(key)
+}
+
+func (fsCache) Set(key string, r io.Reader) (io.Reader, error) {
+	return cache.Set(key, r)
+}
+
+// WithDockerOption sets all the fields of DockerOption.
+//
+// Deprecated: DockerOption will be removed in the next major version, use the other options.
+func WithDockerOption(option DockerOption) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.Option = option
+	}
+}
+
+// WithLocalImage reads the image from the Docker-compatible daemon if it exists there before the registry.
+// See DockerOption.LocalImage.
+func WithLocalImage() DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.Option.LocalImage = true
+	}
+}
+
+// WithTimeout sets the timeout of extracting images from registries and daemons.
+// The timeout cancels the downloads in progress, e.g. stalled layers.
+func WithTimeout(timeout time.Duration) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.Option.Timeout = timeout
+	}
+}
+
+// WithCredentials sets the user name and the password of registries.
+func WithCredentials(userName, password string) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.Option.UserName = userName
+		d.Option.Password = password
+	}
+}
+
+// WithTLS sets the TLS configuration of registries, e.g. the certificates of private CAs.
+func WithTLS(config *tls.Config) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.tlsConfig = config
+	}
+}
+
+// WithCache sets the cache of layers. A nil cache disables caching.
+func WithCache(c Cache) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.cache = c
+		d.noCache = c == nil
+	}
+}
+
+// WithProgressReporter sets the reporter of the progress.
+func WithProgressReporter(p ProgressReporter) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.progress = p
+	}
+}
+
+// WithExcludedPaths sets the globs of files not to be extracted. See IsRequired.
+func WithExcludedPaths(excludedPaths []string) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.Option.ExcludedPaths = excludedPaths
+	}
+}
+
+// WithFileSizeLimits skips the files matching the patterns larger than the sizes in bytes.
+func WithFileSizeLimits(limits map[string]int64) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.Option.FileSizeLimits = limits
+	}
+}
+
+// WithCollectExecutables lists the files with any execute bit in ImageMetadata.Executables.
+func WithCollectExecutables() DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.Option.CollectExecutables = true
+	}
+}
+
+// WithCredentialProvider sets the provider of the credentials of registries, e.g. ecr.ECRCredentialProvider.
+// The provider is not used with WithCredentials.
+func WithCredentialProvider(p auth.CredentialProvider) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.credProvider = p
+	}
+}
+
+// WithKeychain sets the keychain consulted for each registry before the user name and the password.
+func WithKeychain(k auth.Keychain) DockerExtractorOption {
+	return func(d *DockerExtractor) {
+		d.Option.Keychain = k
+	}
+}
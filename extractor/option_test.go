@@ -0,0 +1,273 @@
+package extractor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knqyf263/fanal/auth"
+	digest "github.com/opencontainers/go-digest"
+)
+
+type mapCache map[string]io.Reader
+
+func (c mapCache) Get(key string) io.Reader {
+	return c[key]
+}
+
+func (c mapCache) Set(key string, r io.Reader) (io.Reader, error) {
+	c[key] = r
+	return r, nil
+}
+
+type nopProgressReporter struct{}
+
+func (nopProgressReporter) LayerExtracted(string, int, int) {}
+
+type staticCredentialProvider struct {
+	username, password string
+	hosts              *[]string
+}
+
+func (p staticCredentialProvider) Credentials(registryHost string) (string, string, error) {
+	*p.hosts = append(*p.hosts, registryHost)
+	return p.username, p.password, nil
+}
+
+func TestNewDockerExtractor(t *testing.T) {
+	tlsConfig := &tls.Config{}
+	c := mapCache{}
+	var tests = map[string]struct {
+		opts     []DockerExtractorOption
+		expected DockerExtractor
+		cache    Cache
+	}{
+		"default": {
+			expected: DockerExtractor{},
+			cache:    fsCache{},
+		},
+		"options": {
+			opts: []DockerExtractorOption{
+				WithTimeout(time.Minute),
+				WithCredentials("user", "pass"),
+				WithTLS(tlsConfig),
+				WithCache(c),
+				WithProgressReporter(nopProgressReporter{}),
+				WithExcludedPaths([]string{"**/node_modules/**"}),
+				WithCollectExecutables(),
+				WithLocalImage(),
+				WithFileSizeLimits(map[string]int64{"*.jar": 1024}),
+			},
+			expected: DockerExtractor{
+				Option: DockerOption{
+					Timeout:            time.Minute,
+					UserName:           "user",
+					Password:           "pass",
+					ExcludedPaths:      []string{"**/node_modules/**"},
+					CollectExecutables: true,
+					LocalImage:         true,
+					FileSizeLimits:     map[string]int64{"*.jar": 1024},
+				},
+				tlsConfig: tlsConfig,
+				cache:     c,
+				progress:  nopProgressReporter{},
+			},
+			cache: c,
+		},
+		"no cache": {
+			opts:     []DockerExtractorOption{WithCache(nil)},
+			expected: DockerExtractor{noCache: true},
+		},
+		"deprecated option": {
+			opts:     []DockerExtractorOption{WithDockerOption(DockerOption{Timeout: time.Second}), WithCredentials("user", "pass")},
+			expected: DockerExtractor{Option: DockerOption{Timeout: time.Second, UserName: "user", Password: "pass"}},
+			cache:    fsCache{},
+		},
+	}
+	for testname, v := range tests {
+		d := NewDockerExtractor(v.opts...)
+		if !reflect.DeepEqual(v.expected, d) {
+			t.Errorf("[%s]\nexpected : %+v\nactual : %+v", testname, v.expected, d)
+		}
+		if !reflect.DeepEqual(v.cache, d.layerCache()) {
+			t.Errorf("[%s]\nexpected cache : %v\nactual : %v", testname, v.cache, d.layerCache())
+		}
+	}
+}
+
+func TestCreateRegistryClient_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	domain := strings.TrimPrefix(ts.URL, "https://")
+
+	// The certificate of the test server is signed by an unknown CA
+	_, err := NewDockerExtractor().createRegistryClient(context.Background(), domain)
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	d := NewDockerExtractor(WithTLS(&tls.Config{RootCAs: pool}))
+	if _, err = d.createRegistryClient(context.Background(), domain); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCreateRegistryClient_CredentialProvider(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "AWS" || pass != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	domain := strings.TrimPrefix(ts.URL, "http://")
+
+	var hosts []string
+	p := staticCredentialProvider{username: "AWS", password: "token", hosts: &hosts}
+	d := NewDockerExtractor(WithDockerOption(DockerOption{NonSSL: true}), WithCredentialProvider(p))
+	if _, err := d.createRegistryClient(context.Background(), domain); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual([]string{domain}, hosts) {
+		t.Errorf("expected hosts %v, got %v", []string{domain}, hosts)
+	}
+
+	// The explicit credentials take precedence
+	hosts = nil
+	d = NewDockerExtractor(WithDockerOption(DockerOption{NonSSL: true}), WithCredentialProvider(p), WithCredentials("AWS", "token"))
+	if _, err := d.createRegistryClient(context.Background(), domain); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hosts) != 0 {
+		t.Errorf("the provider must not be called: %v", hosts)
+	}
+}
+
+func TestDockerExtractorKeychain(t *testing.T) {
+	var hosts []string
+	p := staticCredentialProvider{username: "provider", password: "provider-pass", hosts: &hosts}
+	keychain := auth.KeychainFunc(func(registry string) (string, string, error) {
+		if registry != "vault.example.com" {
+			return "", "", nil
+		}
+		return "vault", "vault-pass", nil
+	})
+	d := NewDockerExtractor(WithKeychain(keychain), WithCredentials("user", "pass"), WithCredentialProvider(p))
+
+	var tests = map[string]struct {
+		registry         string
+		expectedUsername string
+		expectedPassword string
+	}{
+		"keychain": {registry: "vault.example.com", expectedUsername: "vault", expectedPassword: "vault-pass"},
+		"static":   {registry: "registry.example.com", expectedUsername: "user", expectedPassword: "pass"},
+	}
+	for testname, v := range tests {
+		username, password, err := d.keychain().Resolve(v.registry)
+		if err != nil {
+			t.Fatalf("[%s] unexpected error: %v", testname, err)
+		}
+		if username != v.expectedUsername || password != v.expectedPassword {
+			t.Errorf("[%s]\nexpected : %s:%s\nactual : %s:%s", testname, v.expectedUsername, v.expectedPassword, username, password)
+		}
+	}
+	if len(hosts) != 0 {
+		t.Errorf("the provider must not be called: %v", hosts)
+	}
+}
+
+func TestCreateRegistryClient_InsecureRegistry(t *testing.T) {
+	configDigest := digest.FromString(testImageConfig)
+	manifest := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "` + configDigest.String() + `"},
+  "layers": []
+}`
+	// plain HTTP
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifest))
+		case "/v2/app/blobs/" + configDigest.String():
+			w.Write([]byte(testImageConfig))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "http://")
+
+	var tests = map[string]struct {
+		registries map[string]RegistryOption
+		wantErr    bool
+	}{
+		"default": {
+			wantErr: true,
+		},
+		"insecure registry": {
+			registries: map[string]RegistryOption{host: {InsecureRegistry: true}},
+		},
+		"other registry": {
+			registries: map[string]RegistryOption{"localhost:5000": {InsecureRegistry: true}},
+			wantErr:    true,
+		},
+		"skip verify": {
+			registries: map[string]RegistryOption{host: {SkipTLSVerify: true}},
+			wantErr:    true,
+		},
+	}
+	for testname, v := range tests {
+		metadata, err := FetchImageMetadata(context.Background(), host+"/app:1.0",
+			WithDockerOption(DockerOption{Registries: v.registries}), WithTimeout(10*time.Second))
+		if v.wantErr {
+			if err == nil {
+				t.Errorf("[%s] expected error but got nil", testname)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testname, err)
+			continue
+		}
+		if metadata.ID != configDigest.String() {
+			t.Errorf("[%s]\nexpected : %s\nactual : %s", testname, configDigest, metadata.ID)
+		}
+	}
+}
+
+func TestCreateRegistryClient_SkipTLSVerify(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	domain := strings.TrimPrefix(ts.URL, "https://")
+
+	for _, opts := range [][]DockerExtractorOption{
+		{WithDockerOption(DockerOption{Registries: map[string]RegistryOption{domain: {SkipTLSVerify: true}}})},
+		// with the TLS configuration without the certificate
+		{WithDockerOption(DockerOption{Registries: map[string]RegistryOption{domain: {SkipTLSVerify: true}}}), WithTLS(&tls.Config{})},
+	} {
+		if _, err := NewDockerExtractor(opts...).createRegistryClient(context.Background(), domain); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/genuinetools/reg/registry"
+	"golang.org/x/xerrors"
+)
+
+const (
+	mediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIIndex     = "application/vnd.oci.image.index.v1+json"
+	// defaultPlatform is selected from multi-arch images without Option.Platform, like the registries do.
+	defaultPlatform = "linux/amd64"
+)
+
+// resolveManifest returns the image manifest and its platform. A manifest list or an image index is resolved
+// to the manifest of Option.Platform. The platform is empty for single-arch images.
+func (d DockerExtractor) resolveManifest(ctx context.Context, r *registry.Registry, image registry.Image) (*schema2.DeserializedManifest, string, error) {
+	mediaType, body, err := getManifest(ctx, r, image.Path, image.Reference(),
+		mediaTypeManifestList, mediaTypeOCIIndex, schema2.MediaTypeManifest)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var platform string
+	if mediaType == mediaTypeManifestList || mediaType == mediaTypeOCIIndex {
+		var index ociIndex
+		if err = json.Unmarshal(body, &index); err != nil {
+			return nil, "", xerrors.Errorf("invalid manifest list: %w", err)
+		}
+		desc, err := d.selectManifestPlatform(index.Manifests)
+		if err != nil {
+			return nil, "", err
+		}
+		platform = desc.platform()
+		if mediaType, body, err = getManifest(ctx, r, image.Path, string(desc.Digest), schema2.MediaTypeManifest); err != nil {
+			return nil, "", err
+		}
+	}
+
+	m, _, err := distribution.UnmarshalManifest(mediaType, body)
+	if err != nil {
+		return nil, "", xerrors.Errorf("invalid manifest: %w", err)
+	}
+	manifest, ok := m.(*schema2.DeserializedManifest)
+	if !ok {
+		return nil, "", xerrors.Errorf("unsupported manifest: %s", mediaType)
+	}
+	return manifest, platform, nil
+}
+
+// selectManifestPlatform returns the manifest of Option.Platform, or defaultPlatform and then the first one
+// if Option.Platform is empty.
+func (d DockerExtractor) selectManifestPlatform(descs []ociDescriptor) (ociDescriptor, error) {
+	if d.Option.Platform != "" {
+		return selectPlatform(descs, d.Option.Platform)
+	}
+	if desc, err := selectPlatform(descs, defaultPlatform); err == nil {
+		return desc, nil
+	}
+	return selectPlatform(descs, "")
+}
+
+// getManifest returns the media type and the body of the manifest accepting the media types.
+func getManifest(ctx context.Context, r *registry.Registry, repository, ref string, mediaTypes ...string) (string, []byte, error) {
+	uri := fmt.Sprintf("%s/v2/%s/manifests/%s", strings.TrimSuffix(r.URL, "/"), repository, ref)
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, mt := range mediaTypes {
+		req.Header.Add("Accept", mt)
+	}
+
+	resp, err := r.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", nil, xerrors.Errorf("failed to get the manifest(%s): %w", ref, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, xerrors.Errorf("failed to get the manifest(%s): %s", ref, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, xerrors.Errorf("failed to read the manifest(%s): %w", ref, err)
+	}
+
+	// e.g. application/vnd.docker.distribution.manifest.v2+json; charset=utf-8
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType == "" || mediaType == "application/json" {
+		// Some registries don't return the media type of the manifest
+		var m struct {
+			MediaType string `json:"mediaType"`
+		}
+		if err = json.Unmarshal(body, &m); err == nil {
+			mediaType = m.MediaType
+		}
+	}
+	return mediaType, body, nil
+}
+
+// platform returns "os/arch[/variant]" of the manifest.
+func (d ociDescriptor) platform() string {
+	if d.Platform == nil {
+		return ""
+	}
+	platform := d.Platform.OS + "/" + d.Platform.Architecture
+	if d.Platform.Variant != "" {
+		platform += "/" + d.Platform.Variant
+	}
+	return platform
+}
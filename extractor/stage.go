@@ -0,0 +1,69 @@
+package extractor
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// HistoryEntry is an entry of "history" in the image config.
+// https://github.com/opencontainers/image-spec/blob/master/config.md
+type HistoryEntry struct {
+	Created    *time.Time `json:"created,omitempty"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	Author     string     `json:"author,omitempty"`
+	Comment    string     `json:"comment,omitempty"`
+	EmptyLayer bool       `json:"empty_layer,omitempty"`
+}
+
+// StageGroup is a set of layers introduced by the same build stage.
+type StageGroup struct {
+	StageIndex int
+	FromDigest string
+	Layers     []string
+}
+
+// ParseHistory returns the history in the image config JSON.
+func ParseHistory(config []byte) ([]HistoryEntry, error) {
+	var c imageConfig
+	if err := json.Unmarshal(config, &c); err != nil {
+		return nil, xerrors.Errorf("invalid image config: %w", err)
+	}
+	return c.History, nil
+}
+
+// DetectMultiStageGroups splits layers into build stages.
+// A stage starts where a root filesystem is added, e.g. "/bin/sh -c #(nop) ADD file:xxx in / ",
+// which is the first layer of an image built FROM scratch.
+// History entries with empty_layer are skipped because they don't have a layer.
+func DetectMultiStageGroups(layerDigests []string, history []HistoryEntry) []StageGroup {
+	var groups []StageGroup
+	var i int
+	for _, h := range history {
+		if h.EmptyLayer {
+			continue
+		}
+		if i >= len(layerDigests) {
+			break
+		}
+		digest := layerDigests[i]
+		i++
+
+		if len(groups) == 0 || isBaseLayer(h) {
+			groups = append(groups, StageGroup{
+				StageIndex: len(groups),
+				FromDigest: digest,
+			})
+		}
+		groups[len(groups)-1].Layers = append(groups[len(groups)-1].Layers, digest)
+	}
+	return groups
+}
+
+func isBaseLayer(h HistoryEntry) bool {
+	createdBy := strings.TrimPrefix(h.CreatedBy, "/bin/sh -c #(nop) ")
+	createdBy = strings.TrimSpace(createdBy)
+	return strings.HasPrefix(createdBy, "ADD file:") && strings.HasSuffix(createdBy, " in /")
+}
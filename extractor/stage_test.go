@@ -0,0 +1,51 @@
+package extractor
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestDetectMultiStageGroups(t *testing.T) {
+	vectors := []struct {
+		name         string
+		config       string       // Image config file
+		layerDigests []string     // Layers in the manifest
+		groups       []StageGroup // Expected output
+	}{
+		{
+			name:         "single stage",
+			config:       "testdata/config_single.json",
+			layerDigests: []string{"sha256:aaa", "sha256:bbb"},
+			groups: []StageGroup{
+				{StageIndex: 0, FromDigest: "sha256:aaa", Layers: []string{"sha256:aaa", "sha256:bbb"}},
+			},
+		},
+		{
+			name:         "multi stage",
+			config:       "testdata/config_multi.json",
+			layerDigests: []string{"sha256:aaa", "sha256:bbb", "sha256:ccc", "sha256:ddd"},
+			groups: []StageGroup{
+				{StageIndex: 0, FromDigest: "sha256:aaa", Layers: []string{"sha256:aaa", "sha256:bbb"}},
+				{StageIndex: 1, FromDigest: "sha256:ccc", Layers: []string{"sha256:ccc", "sha256:ddd"}},
+			},
+		},
+	}
+
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			b, err := ioutil.ReadFile(v.config)
+			if err != nil {
+				t.Fatalf("ReadFile() error: %v", err)
+			}
+			history, err := ParseHistory(b)
+			if err != nil {
+				t.Fatalf("ParseHistory() error: %v", err)
+			}
+			groups := DetectMultiStageGroups(v.layerDigests, history)
+			if !reflect.DeepEqual(groups, v.groups) {
+				t.Errorf("StageGroups: got %v, want %v", groups, v.groups)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package extractor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+)
+
+// registryTLSConfig returns the TLS configuration of WithTLS with the CA certificates and the client certificate
+// of DockerOption. It returns nil if none of them is set, i.e. the default of the registry client.
+func (d DockerExtractor) registryTLSConfig() (*tls.Config, error) {
+	if d.Option.CACertPath == "" && d.Option.ClientCert == "" && d.Option.ClientKey == "" {
+		return d.tlsConfig, nil
+	}
+
+	config := &tls.Config{}
+	if d.tlsConfig != nil {
+		config = d.tlsConfig.Clone()
+	}
+	if d.Option.CACertPath != "" {
+		pool, err := loadCACerts(d.Option.CACertPath)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	if d.Option.ClientCert != "" || d.Option.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(d.Option.ClientCert, d.Option.ClientKey)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load the client certificate: %w", err)
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+	return config, nil
+}
+
+// loadCACerts returns the system pool with the PEM certificates in the file or all the files in the directory.
+func loadCACerts(path string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		// e.g. Windows before Go 1.18
+		pool = x509.NewCertPool()
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read the CA certificates: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		infos, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read the CA certificates: %w", err)
+		}
+		files = nil
+		for _, fi := range infos {
+			if fi.Mode().IsRegular() {
+				files = append(files, filepath.Join(path, fi.Name()))
+			}
+		}
+		if len(files) == 0 {
+			return nil, xerrors.Errorf("no CA certificates in %s", path)
+		}
+	}
+
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to read the CA certificate: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, xerrors.Errorf("invalid CA certificate, no PEM certificates in %s", f)
+		}
+	}
+	return pool, nil
+}
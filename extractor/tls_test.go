@@ -0,0 +1,127 @@
+package extractor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeClientCert writes the self-signed client certificate and the key.
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fanal"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	if err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestCreateRegistryClient_Certificates(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+	domain := strings.TrimPrefix(ts.URL, "https://")
+
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caDir := filepath.Join(dir, "ca")
+	if err = os.Mkdir(caDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(caDir, "ca.pem")
+	if err = ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err = ioutil.WriteFile(invalidFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := writeClientCert(t, dir)
+
+	var tests = map[string]struct {
+		option  DockerOption
+		wantErr string
+	}{
+		"CA file": {
+			option: DockerOption{CACertPath: caFile, ClientCert: certFile, ClientKey: keyFile},
+		},
+		"CA directory": {
+			option: DockerOption{CACertPath: caDir, ClientCert: certFile, ClientKey: keyFile},
+		},
+		"no client certificate": {
+			option:  DockerOption{CACertPath: caFile},
+			wantErr: "tls",
+		},
+		"unknown CA": {
+			option:  DockerOption{ClientCert: certFile, ClientKey: keyFile},
+			wantErr: "certificate",
+		},
+		"invalid CA": {
+			option:  DockerOption{CACertPath: invalidFile},
+			wantErr: "invalid CA certificate",
+		},
+		"missing CA": {
+			option:  DockerOption{CACertPath: filepath.Join(dir, "missing.pem")},
+			wantErr: "failed to read the CA certificates",
+		},
+		"missing client key": {
+			option:  DockerOption{CACertPath: caFile, ClientCert: certFile},
+			wantErr: "failed to load the client certificate",
+		},
+	}
+	for testname, v := range tests {
+		v.option.Timeout = 10 * time.Second
+		_, err := NewDockerExtractor(WithDockerOption(v.option)).createRegistryClient(context.Background(), domain)
+		if v.wantErr == "" {
+			if err != nil {
+				t.Errorf("[%s] unexpected error: %v", testname, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), v.wantErr) {
+			t.Errorf("[%s]\nexpected error : %s\nactual : %v", testname, v.wantErr, err)
+		}
+	}
+}
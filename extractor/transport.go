@@ -0,0 +1,57 @@
+package extractor
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/xerrors"
+)
+
+type proxyFunc func(*http.Request) (*url.URL, error)
+
+// registryProxy returns the proxy of the requests to registries. ProxyURL overrides HTTP_PROXY and HTTPS_PROXY,
+// and NoProxy overrides NO_PROXY. The user name and the password in the URL are sent to the proxy,
+// including CONNECT for HTTPS registries.
+func (d DockerExtractor) registryProxy() (proxyFunc, error) {
+	if d.Option.ProxyURL == "" && d.Option.NoProxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	config := httpproxy.FromEnvironment()
+	if d.Option.ProxyURL != "" {
+		u, err := url.Parse(d.Option.ProxyURL)
+		if err != nil || u.Host == "" {
+			// The error may contain the password
+			return nil, xerrors.New("invalid proxy URL")
+		}
+		config.HTTPProxy = d.Option.ProxyURL
+		config.HTTPSProxy = d.Option.ProxyURL
+	}
+	if d.Option.NoProxy != "" {
+		config.NoProxy = d.Option.NoProxy
+	}
+	proxy := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}, nil
+}
+
+// newTransport returns the transport with the same timeouts as http.DefaultTransport.
+func newTransport(proxy proxyFunc, tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       tlsConfig,
+	}
+}
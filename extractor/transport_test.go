@@ -0,0 +1,149 @@
+package extractor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newForwardProxy returns the proxy with basic auth which forwards all the requests to the backend.
+func newForwardProxy(t *testing.T, backend string) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.Host)
+		mu.Unlock()
+		if r.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")) {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+
+		if r.Method == http.MethodConnect {
+			upstream, err := net.Dial("tcp", backend)
+			if err != nil {
+				t.Error(err)
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer upstream.Close()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+			go io.Copy(upstream, conn)
+			io.Copy(conn, upstream)
+			return
+		}
+
+		r.URL.Scheme, r.URL.Host, r.RequestURI = "http", backend, ""
+		r.Header.Del("Proxy-Authorization")
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		for k, v := range resp.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	return ts, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestCreateRegistryClient_Proxy(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		w.WriteHeader(http.StatusOK)
+	})
+	httpsRegistry := httptest.NewTLSServer(handler)
+	defer httpsRegistry.Close()
+	httpRegistry := httptest.NewServer(handler)
+	defer httpRegistry.Close()
+
+	httpsProxy, httpsRequests := newForwardProxy(t, httpsRegistry.Listener.Addr().String())
+	defer httpsProxy.Close()
+	httpProxy, httpRequests := newForwardProxy(t, httpRegistry.Listener.Addr().String())
+	defer httpProxy.Close()
+	withAuth := func(proxyURL, password string) string {
+		return strings.Replace(proxyURL, "http://", "http://user:"+password+"@", 1)
+	}
+
+	// The certificate of httptest is valid for example.com
+	pool := x509.NewCertPool()
+	pool.AddCert(httpsRegistry.Certificate())
+
+	var tests = map[string]struct {
+		domain           string
+		option           DockerOption
+		requests         func() []string
+		expectedRequests []string
+		wantErr          bool
+	}{
+		"CONNECT": {
+			domain:           "example.com",
+			option:           DockerOption{ProxyURL: withAuth(httpsProxy.URL, "pass")},
+			requests:         httpsRequests,
+			expectedRequests: []string{"CONNECT example.com:443"},
+		},
+		"HTTP": {
+			domain:           "registry.example.com",
+			option:           DockerOption{ProxyURL: withAuth(httpProxy.URL, "pass"), NonSSL: true},
+			requests:         httpRequests,
+			expectedRequests: []string{"GET registry.example.com"},
+		},
+		"wrong password": {
+			domain:   "example.com",
+			option:   DockerOption{ProxyURL: withAuth(httpsProxy.URL, "wrong")},
+			requests: httpsRequests,
+			wantErr:  true,
+		},
+		"no proxy": {
+			domain:   "registry.invalid",
+			option:   DockerOption{ProxyURL: withAuth(httpProxy.URL, "pass"), NoProxy: ".invalid", NonSSL: true},
+			requests: httpRequests,
+			wantErr:  true,
+		},
+		"invalid proxy": {
+			domain:   "registry.example.com",
+			option:   DockerOption{ProxyURL: "://user:pass@proxy"},
+			requests: httpRequests,
+			wantErr:  true,
+		},
+	}
+	for testname, v := range tests {
+		before := len(v.requests())
+		v.option.Timeout = 5 * time.Second
+		d := NewDockerExtractor(WithDockerOption(v.option), WithTLS(&tls.Config{RootCAs: pool}))
+		_, err := d.createRegistryClient(context.Background(), v.domain)
+		if (err != nil) != v.wantErr {
+			t.Errorf("[%s] unexpected error: %v", testname, err)
+		}
+		if v.wantErr {
+			continue
+		}
+		requests := v.requests()[before:]
+		if len(requests) == 0 || requests[0] != v.expectedRequests[0] {
+			t.Errorf("[%s]\nexpected requests : %v\nactual : %v", testname, v.expectedRequests, requests)
+		}
+	}
+}
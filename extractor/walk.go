@@ -0,0 +1,154 @@
+package extractor
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	"golang.org/x/xerrors"
+)
+
+// FileInfo describes a file passed to WalkFunc.
+type FileInfo struct {
+	// Layer is the digest of the layer, e.g. "sha256:...", and LayerIndex is the index in ImageMetadata.Layers.
+	// They are empty for WalkLayer.
+	Layer      string
+	LayerIndex int
+	// Size is the size of the content in bytes. It is 0 for the links and the whiteouts.
+	Size int64
+	// Mode has os.ModeSymlink for the symbolic links.
+	Mode os.FileMode
+	// Linkname is the target of the symbolic link or the hard link relative to the root, e.g. usr/lib/os-release.
+	// The links are not resolved as the targets may be in the other layers, and their content is empty.
+	// All the symbolic links are passed whether or not they are required, as the links of the directories,
+	// e.g. lib -> usr/lib, change the paths of the required files.
+	Linkname string
+	// Whiteout is set for the whiteouts of the lower layers, e.g. etc/.wh.os-release deleting etc/os-release
+	// and etc/.wh..wh..opq deleting all the files in etc. They are passed whether or not they are required.
+	Whiteout bool
+}
+
+// WalkFunc is called for each file by Walk and WalkLayer. r reads the content from the layer while fn runs,
+// and the rest which is not read is skipped, so that fn can buffer, hash or discard the content as it likes.
+// The walk stops at the first error of fn and returns it.
+type WalkFunc func(filePath string, info FileInfo, r io.Reader) error
+
+// Walk calls fn for each required file in the layers of the image in the registry, without holding the files
+// in memory like Extract. The layers are walked one by one from the bottom, and the files of each layer are
+// passed in the order of the tar as they are read. The files are not merged: the same path may be passed for
+// several layers, where the upper one wins, and the whiteouts and the links are passed for the caller to
+// resolve them in the same way as Extract. The whiteouts and the symbolic links are passed even if they are
+// not required.
+//
+// The files larger than DockerOption.MaxFileSize are skipped in the same way as Extract, and MaxExtractSize
+// doesn't apply.
+// Unlike Extract, the images in the local daemon are not used, as the archive of docker save has the manifest
+// after the layers. Extract reads the layers with the same walkTar instead of a WalkFunc, as it also needs
+// the entries which are not passed to fn: the executables, the targets of the links and the number of the entries.
+func (d DockerExtractor) Walk(ctx context.Context, imageName string, filenames []string, fn WalkFunc) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if d.Option.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d.Option.Timeout)
+		defer cancel()
+	}
+
+	r, image, _, metadata, err := d.fetchManifest(ctx, imageName)
+	if err != nil {
+		return err
+	}
+	var total int
+	for _, l := range metadata.Layers {
+		if !IsBuildkitCacheLayer(l) {
+			total++
+		}
+	}
+
+	filter := newFileFilter(filenames, d.Option.ExcludedPaths)
+	limiter := newSizeLimiter(d.Option)
+	var done int
+	for i, l := range metadata.Layers {
+		// Skip the blobs of BuildKit, which are not filesystems
+		if IsBuildkitCacheLayer(l) {
+			continue
+		}
+		content, err := d.openLayer(ctx, r, image, digest.Digest(l.Digest), l.MediaType)
+		if err != nil {
+			return err
+		}
+		err = d.walkLayer(content, filter, limiter, FileInfo{Layer: l.Digest, LayerIndex: i}, fn)
+		content.Close()
+		if ctx.Err() != nil {
+			return xerrors.Errorf("timeout: %w", ctx.Err())
+		} else if err != nil {
+			return err
+		}
+		done++
+		if d.progress != nil {
+			d.progress.LayerExtracted(l.Digest, done, total)
+		}
+	}
+	return nil
+}
+
+// WalkLayer is the same as Walk for a layer or a filesystem, e.g. the archive of "docker export".
+// The files are passed in the same way as ExtractFiles except that the links are not resolved.
+func (d DockerExtractor) WalkLayer(layer io.Reader, filenames []string, fn WalkFunc) error {
+	return d.walkLayer(layer, newFileFilter(filenames, d.Option.ExcludedPaths), newSizeLimiter(d.Option), FileInfo{}, fn)
+}
+
+// walkLayer calls fn for the required files, all the whiteouts and all the symbolic links in the layer
+// with the info of the layer.
+func (d DockerExtractor) walkLayer(layer io.Reader, filter fileFilter, limiter *sizeLimiter, layerInfo FileInfo, fn WalkFunc) error {
+	return walkTar(layer, func(filePath string, hdr *tar.Header, r io.Reader) error {
+		info := layerInfo
+		info.Mode = hdr.FileInfo().Mode()
+		if strings.HasPrefix(filepath.Base(filePath), wh) {
+			info.Whiteout = true
+			return fn(filePath, info, r)
+		}
+		if hdr.Typeflag == tar.TypeSymlink {
+			info.Linkname = symlinkTarget(filePath, hdr.Linkname)
+			return fn(filePath, info, r)
+		}
+		if !filter.isRequired(filePath) {
+			return nil
+		}
+		switch hdr.Typeflag {
+		case tar.TypeLink:
+			info.Linkname = hardLinkTarget(hdr.Linkname)
+		case tar.TypeReg:
+			if err := limiter.checkSize(filePath, hdr.Size); skipLargeFile(err, filter, filePath) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			info.Size = hdr.Size
+		default:
+			return nil
+		}
+		return fn(filePath, info, r)
+	})
+}
+
+// walkTar calls fn for each entry of the tar with the cleaned path. r reads the content of the entry.
+// Both extractFiles and walkLayer read the layers through it.
+func walkTar(layer io.Reader, fn func(filePath string, hdr *tar.Header, r io.Reader) error) error {
+	tr := tar.NewReader(layer)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return ErrCouldNotExtract
+		}
+		if err = fn(filepath.Clean(hdr.Name), hdr, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package extractor
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"golang.org/x/xerrors"
+)
+
+type walkedFile struct {
+	path    string
+	info    FileInfo
+	content string
+}
+
+func walkCollector(walked *[]walkedFile) WalkFunc {
+	return func(filePath string, info FileInfo, r io.Reader) error {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		*walked = append(*walked, walkedFile{path: filePath, info: info, content: string(b)})
+		return nil
+	}
+}
+
+func TestWalkLayer(t *testing.T) {
+	var tests = map[string]struct {
+		file      string
+		filenames []string
+		expected  []walkedFile
+	}{
+		"whiteouts": {
+			file:      "testdata/opq2.tar",
+			filenames: []string{"etc/test/bar"},
+			expected: []walkedFile{
+				{path: "etc/test/.wh..wh..opq", info: FileInfo{Mode: 0755, Whiteout: true}},
+				{path: "etc/test/bar", info: FileInfo{Mode: 0644, Size: 4}, content: "bar\n"},
+				{path: "var/.wh.foo", info: FileInfo{Mode: 0600, Whiteout: true}},
+			},
+		},
+		"hard links": {
+			file:      "testdata/hardlink.tar",
+			filenames: []string{"etc/os-release", "etc/redhat-release"},
+			expected: []walkedFile{
+				{path: "etc/os-release", info: FileInfo{Mode: 0644, Linkname: "usr/lib/os-release"}},
+				{path: "etc/redhat-release", info: FileInfo{Mode: 0644, Linkname: "etc/centos-release"}},
+			},
+		},
+	}
+	for testname, v := range tests {
+		f, err := os.Open(v.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var walked []walkedFile
+		err = NewDockerExtractor().WalkLayer(f, v.filenames, walkCollector(&walked))
+		f.Close()
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testname, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.expected, walked) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testname, v.expected, walked)
+		}
+	}
+}
+
+func TestWalkLayer_Symlinks(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range []tar.Header{
+		{Name: "lib", Typeflag: tar.TypeSymlink, Linkname: "usr/lib", Mode: 0777},
+		{Name: "etc/os-release", Typeflag: tar.TypeSymlink, Linkname: "../usr/lib/os-release", Mode: 0777},
+		{Name: "usr/lib/os-release", Typeflag: tar.TypeReg, Mode: 0644},
+	} {
+		hdr := hdr
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+
+	// lib is passed though it isn't required, and usr/lib/os-release is not
+	var walked []walkedFile
+	if err := NewDockerExtractor().WalkLayer(&buf, []string{"etc/os-release"}, walkCollector(&walked)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []walkedFile{
+		{path: "lib", info: FileInfo{Mode: os.ModeSymlink | 0777, Linkname: "usr/lib"}},
+		{path: "etc/os-release", info: FileInfo{Mode: os.ModeSymlink | 0777, Linkname: "usr/lib/os-release"}},
+	}
+	if !reflect.DeepEqual(expected, walked) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, walked)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	type entry struct {
+		hdr     tar.Header
+		content string
+	}
+	layerBlob := func(entries []entry) []byte {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		for _, e := range entries {
+			e.hdr.Size = int64(len(e.content))
+			if err := tw.WriteHeader(&e.hdr); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tw.Close()
+		gw.Close()
+		return buf.Bytes()
+	}
+	// The upper layer deletes var/foo and overrides etc/os-release
+	lower := layerBlob([]entry{
+		{hdr: tar.Header{Name: "etc/os-release", Mode: 0644}, content: "foo"},
+		{hdr: tar.Header{Name: "var/foo", Mode: 0644}, content: "foo"},
+	})
+	upper := layerBlob([]entry{
+		{hdr: tar.Header{Name: "var/.wh.foo", Mode: 0600}},
+		{hdr: tar.Header{Name: "etc/os-release", Mode: 0644}, content: "bar"},
+	})
+	lowerDigest, upperDigest := digest.FromBytes(lower), digest.FromBytes(upper)
+
+	configDigest := digest.FromString(testImageConfig)
+	manifest := `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+  "config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 100, "digest": "` + configDigest.String() + `"},
+  "layers": [
+    {"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 100, "digest": "` + lowerDigest.String() + `"},
+    {"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 100, "digest": "` + upperDigest.String() + `"}
+  ]
+}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/1.0":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Write([]byte(manifest))
+		case "/v2/app/blobs/" + configDigest.String():
+			w.Write([]byte(testImageConfig))
+		case "/v2/app/blobs/" + lowerDigest.String():
+			w.Write(lower)
+		case "/v2/app/blobs/" + upperDigest.String():
+			w.Write(upper)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	imageName := strings.TrimPrefix(ts.URL, "http://") + "/app:1.0"
+
+	d := NewDockerExtractor(WithDockerOption(DockerOption{NonSSL: true, DockerHost: "unix:///nonexistent.sock"}), WithCache(nil))
+	var walked []walkedFile
+	if err := d.Walk(context.Background(), imageName, []string{"etc/os-release"}, walkCollector(&walked)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []walkedFile{
+		{path: "etc/os-release", info: FileInfo{Layer: lowerDigest.String(), LayerIndex: 0, Mode: 0644, Size: 3}, content: "foo"},
+		{path: "var/.wh.foo", info: FileInfo{Layer: upperDigest.String(), LayerIndex: 1, Mode: 0600, Whiteout: true}},
+		{path: "etc/os-release", info: FileInfo{Layer: upperDigest.String(), LayerIndex: 1, Mode: 0644, Size: 3}, content: "bar"},
+	}
+	if !reflect.DeepEqual(expected, walked) {
+		t.Errorf("\nexpected : %v\nactual : %v", expected, walked)
+	}
+
+	// The walk stops at the error of the callback, and the rest of the content is skipped
+	errStop := xerrors.New("stop")
+	var count int
+	err := d.Walk(context.Background(), imageName, []string{"etc/os-release"}, func(string, FileInfo, io.Reader) error {
+		count++
+		return errStop
+	})
+	if !xerrors.Is(err, errStop) || count != 1 {
+		t.Errorf("unexpected error or count: %v, %d", err, count)
+	}
+}
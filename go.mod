@@ -1,23 +1,28 @@
-go 1.12
+go 1.15
 
 require (
 	cloud.google.com/go v0.37.4 // indirect
+	github.com/BurntSushi/toml v0.3.1
 	github.com/GoogleCloudPlatform/docker-credential-gcr v1.5.0
 	github.com/aws/aws-sdk-go v1.19.11
 	github.com/coreos/clair v0.0.0-20180919182544-44ae4bc9590a
 	github.com/d4l3k/messagediff v1.2.1
-	github.com/deckarep/golang-set v1.7.1
 	github.com/docker/distribution v0.0.0-20180920194744-16128bbac47f
 	github.com/docker/docker v0.0.0-20180924202107-a9c061deec0f
 	github.com/docker/go-connections v0.4.0 // indirect
 	github.com/genuinetools/reg v0.16.1
+	github.com/klauspost/compress v1.13.6
 	github.com/knqyf263/go-dep-parser v0.0.0-20190429154931-c377a5391790
 	github.com/knqyf263/go-rpmdb v0.0.0-20190501070121-10a1c42a10dc
 	github.com/knqyf263/nested v0.0.1
 	github.com/opencontainers/go-digest v0.0.0-20180430190053-c9281466c8b2
 	github.com/pkg/errors v0.8.1
+	github.com/ulikunitz/xz v0.5.11
 	golang.org/x/crypto v0.0.0-20190404164418-38d8ce5564a5
+	golang.org/x/net v0.0.0-20190311183353-d8887717615a
+	golang.org/x/oauth2 v0.0.0-20190226205417-e64efc72b421
 	golang.org/x/xerrors v0.0.0-20190410155217-1f06c39b4373
+	gopkg.in/yaml.v2 v2.2.2
 )
 
 replace github.com/genuinetools/reg => github.com/tomoyamachi/reg v0.16.1
@@ -0,0 +1,248 @@
+// Package osv converts the analysis results into queries of the OSV API.
+// https://ossf.github.io/osv-schema/
+package osv
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/fanal/analyzer/os"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+// OSVQuery is the body of POST /v1/query and an element of POST /v1/querybatch.
+type OSVQuery struct {
+	Version string     `json:"version,omitempty"`
+	Package OSVPackage `json:"package"`
+}
+
+// OSVPackage identifies a package either by the name and the ecosystem or by the Package URL.
+// The ecosystem is empty when OSV has no ecosystem for the package.
+type OSVPackage struct {
+	Name      string `json:"name,omitempty"`
+	Ecosystem string `json:"ecosystem,omitempty"`
+	PURL      string `json:"purl,omitempty"`
+}
+
+// Ecosystems defined in https://ossf.github.io/osv-schema/#affectedpackage-field
+const (
+	EcosystemNpm         = "npm"
+	EcosystemPyPI        = "PyPI"
+	EcosystemRubyGems    = "RubyGems"
+	EcosystemCratesIO    = "crates.io"
+	EcosystemPackagist   = "Packagist"
+	EcosystemMaven       = "Maven"
+	EcosystemNuGet       = "NuGet"
+	EcosystemHex         = "Hex"
+	EcosystemPub         = "Pub"
+	EcosystemConanCenter = "ConanCenter"
+	EcosystemAlpine      = "Alpine"
+	EcosystemDebian      = "Debian"
+	EcosystemUbuntu      = "Ubuntu"
+	EcosystemRedHat      = "Red Hat"
+	EcosystemOpenSUSE    = "openSUSE"
+	EcosystemSUSE        = "SUSE"
+	EcosystemRocky       = "Rocky Linux"
+	EcosystemAlma        = "AlmaLinux"
+)
+
+// purlTypes maps the ecosystems to the Package URL types.
+// https://github.com/package-url/purl-spec/blob/master/PURL-TYPES.rst
+var purlTypes = map[string]string{
+	EcosystemNpm:         "npm",
+	EcosystemPyPI:        "pypi",
+	EcosystemRubyGems:    "gem",
+	EcosystemCratesIO:    "cargo",
+	EcosystemPackagist:   "composer",
+	EcosystemMaven:       "maven",
+	EcosystemNuGet:       "nuget",
+	EcosystemHex:         "hex",
+	EcosystemPub:         "pub",
+	EcosystemConanCenter: "conan",
+}
+
+// PackageToOSVQuery maps the package type to the ecosystem.
+// Binary and source packages depend on the distribution, so use OSPackageToOSVQuery for them.
+// Snap, Flatpak and Windows Store apps have no ecosystem in OSV and only the name is set.
+func PackageToOSVQuery(p analyzer.Package) OSVQuery {
+	return OSVQuery{
+		Version: p.VersionString(),
+		Package: OSVPackage{Name: p.Name},
+	}
+}
+
+// OSPackageToOSVQuery sets the ecosystem of the distribution, e.g. "Alpine:v3.9" and "Debian:10".
+func OSPackageToOSVQuery(o analyzer.OS, p analyzer.Package) OSVQuery {
+	q := PackageToOSVQuery(p)
+	if p.Type != "" && p.Type != analyzer.TypeBinary && p.Type != analyzer.TypeSource {
+		return q
+	}
+	q.Package.Ecosystem = OSEcosystem(o)
+
+	var purlType, namespace string
+	switch o.Family {
+	case os.Alpine:
+		purlType, namespace = "apk", "alpine"
+	case os.Debian, os.Ubuntu:
+		purlType, namespace = "deb", o.Family
+	case os.RedHat, os.CentOS, os.Fedora, os.Amazon, os.Oracle, os.Rocky, os.Alma, os.SUSE, os.OpenSUSE, os.OpenSUSELeap, os.OpenSUSETumbleweed:
+		purlType, namespace = "rpm", o.Family
+	default:
+		return q
+	}
+	version := q.Version
+	qualifiers := url.Values{}
+	if p.Arch != "" {
+		qualifiers.Set("arch", p.Arch)
+	}
+	// The epoch of RPM is a qualifier
+	if purlType == "rpm" && p.Epoch != 0 {
+		version = analyzer.Package{Version: p.Version, Release: p.Release}.VersionString()
+		qualifiers.Set("epoch", fmt.Sprint(p.Epoch))
+	}
+	q.Package.PURL = fmt.Sprintf("pkg:%s/%s/%s@%s", purlType, namespace, escape(p.Name), escape(version))
+	if len(qualifiers) > 0 {
+		q.Package.PURL += "?" + qualifiers.Encode()
+	}
+	return q
+}
+
+// OSEcosystem returns the ecosystem of the distribution, or empty if OSV doesn't cover it.
+func OSEcosystem(o analyzer.OS) string {
+	switch o.Family {
+	case os.Alpine:
+		// e.g. 3.9.4 => Alpine:v3.9
+		v := strings.SplitN(o.Name, ".", 3)
+		if len(v) < 2 {
+			return EcosystemAlpine
+		}
+		return fmt.Sprintf("%s:v%s.%s", EcosystemAlpine, v[0], v[1])
+	case os.Debian:
+		// e.g. 10.1 => Debian:10
+		return fmt.Sprintf("%s:%s", EcosystemDebian, strings.SplitN(o.Name, ".", 2)[0])
+	case os.Ubuntu:
+		return fmt.Sprintf("%s:%s", EcosystemUbuntu, o.Name)
+	case os.RedHat:
+		return EcosystemRedHat
+	case os.OpenSUSE, os.OpenSUSELeap, os.OpenSUSETumbleweed:
+		return EcosystemOpenSUSE
+	case os.SUSE:
+		return EcosystemSUSE
+	case os.Rocky, os.Alma:
+		// e.g. 8.4 => Rocky Linux:8
+		ecosystem := EcosystemRocky
+		if o.Family == os.Alma {
+			ecosystem = EcosystemAlma
+		}
+		return fmt.Sprintf("%s:%s", ecosystem, strings.SplitN(o.Name, ".", 2)[0])
+	}
+	return ""
+}
+
+// LibraryToOSVQuery guesses the ecosystem from the file name such as package-lock.json.
+// The ecosystem is empty for the directories of installed packages except site-packages and vendor,
+// and for CocoaPods and Swift which OSV doesn't cover, but the Package URL is still set for CocoaPods.
+func LibraryToOSVQuery(fp analyzer.FilePath, l types.Library) OSVQuery {
+	q := OSVQuery{
+		Version: l.Version,
+		Package: OSVPackage{Name: l.Name},
+	}
+
+	base := filepath.Base(string(fp))
+	if base == "Podfile.lock" {
+		q.Package.PURL = fmt.Sprintf("pkg:cocoapods/%s@%s", escape(l.Name), escape(l.Version))
+		return q
+	}
+
+	ecosystem := LibraryEcosystem(fp)
+	if ecosystem == "" {
+		return q
+	}
+	q.Package.Ecosystem = ecosystem
+	q.Package.PURL = libraryPURL(ecosystem, l)
+	return q
+}
+
+// LibraryEcosystem returns the ecosystem of the libraries in the file.
+func LibraryEcosystem(fp analyzer.FilePath) string {
+	base := filepath.Base(string(fp))
+	switch base {
+	case "package-lock.json", "yarn.lock":
+		return EcosystemNpm
+	case "Pipfile.lock", "poetry.lock", "site-packages", "dist-packages":
+		return EcosystemPyPI
+	case "Gemfile.lock":
+		return EcosystemRubyGems
+	case "Cargo.lock":
+		return EcosystemCratesIO
+	case "composer.lock", "vendor":
+		return EcosystemPackagist
+	case "pom.xml", "gradle.lockfile":
+		return EcosystemMaven
+	case "packages.lock.json":
+		return EcosystemNuGet
+	case "mix.lock":
+		return EcosystemHex
+	case "pubspec.lock":
+		return EcosystemPub
+	case "conan.lock":
+		return EcosystemConanCenter
+	}
+
+	switch {
+	case matchAny(base, "requirements*.txt"):
+		return EcosystemPyPI
+	case matchAny(base, "*.jar", "*.war", "*.ear", "*.lockfile"):
+		return EcosystemMaven
+	case matchAny(base, "*.deps.json"):
+		return EcosystemNuGet
+	}
+	return ""
+}
+
+func matchAny(name string, patterns ...string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// libraryPURL returns the Package URL, or empty if the name doesn't fit, e.g. Java archives identified by the file name.
+func libraryPURL(ecosystem string, l types.Library) string {
+	name := l.Name
+	var namespace string
+	switch ecosystem {
+	case EcosystemNpm, EcosystemPackagist:
+		// e.g. @babel/core, laravel/framework
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			namespace, name = name[:i], name[i+1:]
+		}
+	case EcosystemPyPI:
+		// https://www.python.org/dev/peps/pep-0503/#normalized-names
+		name = strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+	case EcosystemMaven:
+		// e.g. org.apache.commons:commons-lang3
+		parts := strings.Split(name, ":")
+		if len(parts) != 2 {
+			return ""
+		}
+		namespace, name = parts[0], parts[1]
+	}
+
+	purl := "pkg:" + purlTypes[ecosystem] + "/"
+	if namespace != "" {
+		purl += escape(namespace) + "/"
+	}
+	return purl + escape(name) + "@" + escape(l.Version)
+}
+
+// escape percent-encodes a segment of the Package URL. "@" must be encoded as well, e.g. %40babel/core.
+func escape(s string) string {
+	return strings.Replace(url.PathEscape(s), "@", "%40", -1)
+}
@@ -0,0 +1,98 @@
+package osv
+
+import (
+	"testing"
+
+	"github.com/knqyf263/fanal/analyzer"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+func TestLibraryToOSVQuery(t *testing.T) {
+	var tests = map[string]struct {
+		filePath analyzer.FilePath
+		lib      types.Library
+		expected OSVQuery
+	}{
+		"NpmScoped": {
+			filePath: "app/package-lock.json",
+			lib:      types.Library{Name: "@babel/core", Version: "7.4.5"},
+			expected: OSVQuery{Version: "7.4.5", Package: OSVPackage{Name: "@babel/core", Ecosystem: "npm", PURL: "pkg:npm/%40babel/core@7.4.5"}},
+		},
+		"PyPISitePackages": {
+			filePath: "usr/local/lib/python3.7/site-packages",
+			lib:      types.Library{Name: "Flask_Cors", Version: "3.0.8"},
+			expected: OSVQuery{Version: "3.0.8", Package: OSVPackage{Name: "Flask_Cors", Ecosystem: "PyPI", PURL: "pkg:pypi/flask-cors@3.0.8"}},
+		},
+		"Requirements": {
+			filePath: "app/requirements-dev.txt",
+			lib:      types.Library{Name: "pytest", Version: "7.4.2"},
+			expected: OSVQuery{Version: "7.4.2", Package: OSVPackage{Name: "pytest", Ecosystem: "PyPI", PURL: "pkg:pypi/pytest@7.4.2"}},
+		},
+		"Maven": {
+			filePath: "app/lib/commons-lang3-3.9.jar",
+			lib:      types.Library{Name: "org.apache.commons:commons-lang3", Version: "3.9"},
+			expected: OSVQuery{Version: "3.9", Package: OSVPackage{Name: "org.apache.commons:commons-lang3", Ecosystem: "Maven", PURL: "pkg:maven/org.apache.commons/commons-lang3@3.9"}},
+		},
+		"MavenFileName": {
+			filePath: "app/lib/foo-1.0.jar",
+			lib:      types.Library{Name: "foo", Version: "1.0"},
+			expected: OSVQuery{Version: "1.0", Package: OSVPackage{Name: "foo", Ecosystem: "Maven"}},
+		},
+		"ComposerVendor": {
+			filePath: "app/vendor",
+			lib:      types.Library{Name: "laravel/framework", Version: "v5.8.17"},
+			expected: OSVQuery{Version: "v5.8.17", Package: OSVPackage{Name: "laravel/framework", Ecosystem: "Packagist", PURL: "pkg:composer/laravel/framework@v5.8.17"}},
+		},
+		"CocoaPods": {
+			filePath: "app/Podfile.lock",
+			lib:      types.Library{Name: "Alamofire", Version: "5.4.3"},
+			expected: OSVQuery{Version: "5.4.3", Package: OSVPackage{Name: "Alamofire", PURL: "pkg:cocoapods/Alamofire@5.4.3"}},
+		},
+		"Unknown": {
+			filePath: "app",
+			lib:      types.Library{Name: "express", Version: "4.17.1"},
+			expected: OSVQuery{Version: "4.17.1", Package: OSVPackage{Name: "express"}},
+		},
+	}
+	for testName, v := range tests {
+		actual := LibraryToOSVQuery(v.filePath, v.lib)
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
+
+func TestOSPackageToOSVQuery(t *testing.T) {
+	var tests = map[string]struct {
+		os       analyzer.OS
+		pkg      analyzer.Package
+		expected OSVQuery
+	}{
+		"Alpine": {
+			os:       analyzer.OS{Family: "alpine", Name: "3.9.4"},
+			pkg:      analyzer.Package{Name: "musl", Version: "1.1.20-r4", Arch: "x86_64"},
+			expected: OSVQuery{Version: "1.1.20-r4", Package: OSVPackage{Name: "musl", Ecosystem: "Alpine:v3.9", PURL: "pkg:apk/alpine/musl@1.1.20-r4?arch=x86_64"}},
+		},
+		"DebianSource": {
+			os:       analyzer.OS{Family: "debian", Name: "10.1"},
+			pkg:      analyzer.Package{Name: "zlib", Epoch: 1, Version: "1.2.11.dfsg-1", Type: analyzer.TypeSource},
+			expected: OSVQuery{Version: "1:1.2.11.dfsg-1", Package: OSVPackage{Name: "zlib", Ecosystem: "Debian:10", PURL: "pkg:deb/debian/zlib@1:1.2.11.dfsg-1"}},
+		},
+		"CentOS": {
+			os:       analyzer.OS{Family: "centos", Name: "7.6.1810"},
+			pkg:      analyzer.Package{Name: "vim-minimal", Epoch: 2, Version: "7.4.160", Release: "5.el7", Arch: "x86_64"},
+			expected: OSVQuery{Version: "2:7.4.160-5.el7", Package: OSVPackage{Name: "vim-minimal", PURL: "pkg:rpm/centos/vim-minimal@7.4.160-5.el7?arch=x86_64&epoch=2"}},
+		},
+		"Snap": {
+			os:       analyzer.OS{Family: "ubuntu", Name: "18.04"},
+			pkg:      analyzer.Package{Name: "core18", Version: "20190508", Type: analyzer.TypeSnap},
+			expected: OSVQuery{Version: "20190508", Package: OSVPackage{Name: "core18"}},
+		},
+	}
+	for testName, v := range tests {
+		actual := OSPackageToOSVQuery(v.os, v.pkg)
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package resolver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knqyf263/fanal/osv"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+const (
+	DefaultNpmRegistry  = "https://registry.npmjs.org"
+	DefaultPyPIRegistry = "https://pypi.org"
+)
+
+// e.g. "requests (>=2.0)", "urllib3<3,>=1.21.1", "PySocks!=1.5.7,>=1.5.6; extra == \"socks\""
+var requiresDistRegexp = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*\(?([^;)]*)\)?\s*(?:;(.*))?$`)
+
+// RegistryResolver resolves the dependencies with the registry API of npm or PyPI.
+// The dependency is resolved to the highest version satisfying the constraint.
+// The responses are cached, so the resolver should be reused for the same image.
+type RegistryResolver struct {
+	// Ecosystem is osv.EcosystemNpm or osv.EcosystemPyPI
+	Ecosystem string
+	// BaseURL is the registry, e.g. DefaultNpmRegistry
+	BaseURL string
+	Client  *http.Client
+
+	mu    sync.Mutex
+	cache map[string]interface{}
+}
+
+// NewRegistryResolver returns the resolver using the public registry of the ecosystem.
+func NewRegistryResolver(ecosystem string) (*RegistryResolver, error) {
+	baseURL := ""
+	switch ecosystem {
+	case osv.EcosystemNpm:
+		baseURL = DefaultNpmRegistry
+	case osv.EcosystemPyPI:
+		baseURL = DefaultPyPIRegistry
+	default:
+		return nil, xerrors.Errorf("unsupported ecosystem: %s", ecosystem)
+	}
+	return &RegistryResolver{
+		Ecosystem: ecosystem,
+		BaseURL:   baseURL,
+		Client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+type npmPackage struct {
+	Versions map[string]struct {
+		Dependencies map[string]string `json:"dependencies"`
+	} `json:"versions"`
+}
+
+type pypiPackage struct {
+	Info struct {
+		RequiresDist []string `json:"requires_dist"`
+	} `json:"info"`
+	Releases map[string]json.RawMessage `json:"releases"`
+}
+
+func (r *RegistryResolver) Resolve(name, version string) ([]types.Library, error) {
+	switch r.Ecosystem {
+	case osv.EcosystemNpm:
+		return r.resolveNpm(name, version)
+	case osv.EcosystemPyPI:
+		return r.resolvePyPI(name, version)
+	}
+	return nil, xerrors.Errorf("unsupported ecosystem: %s", r.Ecosystem)
+}
+
+// resolveNpm resolves "dependencies" in the packument. Optional and peer dependencies are not included.
+func (r *RegistryResolver) resolveNpm(name, version string) ([]types.Library, error) {
+	pkg, err := r.npmPackage(name)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := pkg.Versions[version]
+	if !ok {
+		return nil, xerrors.Errorf("unknown version: %s", version)
+	}
+
+	var libs []types.Library
+	for depName, depRange := range v.Dependencies {
+		constraint, ok := parseNpmRange(depRange)
+		if !ok {
+			// e.g. git URLs and tags such as "latest"
+			continue
+		}
+		dep, err := r.npmPackage(depName)
+		if err != nil {
+			return nil, err
+		}
+		var candidates []string
+		for v := range dep.Versions {
+			candidates = append(candidates, v)
+		}
+		if resolved, ok := maxSatisfying(candidates, constraint); ok {
+			libs = append(libs, types.Library{Name: depName, Version: resolved})
+		}
+	}
+	sortLibraries(libs)
+	return libs, nil
+}
+
+func (r *RegistryResolver) npmPackage(name string) (*npmPackage, error) {
+	// e.g. @babel/core => @babel%2Fcore
+	path := "/" + url.PathEscape(name)
+	if cached, ok := r.load(path); ok {
+		return cached.(*npmPackage), nil
+	}
+	pkg := &npmPackage{}
+	if err := r.get(path, pkg); err != nil {
+		return nil, err
+	}
+	r.store(path, pkg)
+	return pkg, nil
+}
+
+func (r *RegistryResolver) pypiPackage(path string) (*pypiPackage, error) {
+	if cached, ok := r.load(path); ok {
+		return cached.(*pypiPackage), nil
+	}
+	pkg := &pypiPackage{}
+	if err := r.get(path, pkg); err != nil {
+		return nil, err
+	}
+	r.store(path, pkg)
+	return pkg, nil
+}
+
+// resolvePyPI resolves "requires_dist" of the release. Dependencies of extras are not included,
+// and the other environment markers are ignored.
+func (r *RegistryResolver) resolvePyPI(name, version string) ([]types.Library, error) {
+	release, err := r.pypiPackage("/pypi/" + url.PathEscape(name) + "/" + url.PathEscape(version) + "/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var libs []types.Library
+	for _, requirement := range release.Info.RequiresDist {
+		m := requiresDistRegexp.FindStringSubmatch(strings.TrimSpace(requirement))
+		if m == nil || strings.Contains(m[3], "extra") {
+			continue
+		}
+		constraint, ok := parsePEP440(m[2])
+		if !ok {
+			continue
+		}
+
+		dep, err := r.pypiPackage("/pypi/" + url.PathEscape(m[1]) + "/json")
+		if err != nil {
+			return nil, err
+		}
+		var candidates []string
+		for v := range dep.Releases {
+			candidates = append(candidates, v)
+		}
+		if resolved, ok := maxSatisfying(candidates, constraint); ok {
+			libs = append(libs, types.Library{Name: m[1], Version: resolved})
+		}
+	}
+	sortLibraries(libs)
+	return libs, nil
+}
+
+func (r *RegistryResolver) load(path string) (interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.cache[path]
+	return v, ok
+}
+
+func (r *RegistryResolver) store(path string, v interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cache == nil {
+		r.cache = map[string]interface{}{}
+	}
+	r.cache[path] = v
+}
+
+// get decodes the JSON response into v.
+func (r *RegistryResolver) get(path string, v interface{}) error {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(strings.TrimSuffix(r.BaseURL, "/") + path)
+	if err != nil {
+		return xerrors.Errorf("failed to request %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("unexpected status code of %s: %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return xerrors.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func sortLibraries(libs []types.Library) {
+	sort.Slice(libs, func(i, j int) bool {
+		return libs[i].Name < libs[j].Name
+	})
+}
@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/fanal/osv"
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+)
+
+var registryResponses = map[string]string{
+	"/express":     `{"versions": {"4.17.1": {"dependencies": {"debug": "2.6.9", "@types/qs": "^6.9.0", "foo": "git+https://example.com/foo.git"}}}}`,
+	"/debug":       `{"versions": {"2.6.8": {}, "2.6.9": {}, "3.0.0": {}}}`,
+	"/@types%2Fqs": `{"versions": {"6.9.0": {}, "6.9.3": {}, "7.0.0": {}}}`,
+	"/pypi/requests/2.22.0/json": `{"info": {"requires_dist": [
+		"chardet (<3.1.0,>=3.0.2)",
+		"idna",
+		"PySocks (!=1.5.7,>=1.5.6) ; extra == 'socks'"
+	]}}`,
+	"/pypi/chardet/json": `{"releases": {"3.0.1": [], "3.0.4": [], "3.1.0": []}}`,
+	"/pypi/idna/json":    `{"releases": {"2.8": [], "2.9": [], "3.0rc1": []}}`,
+}
+
+func TestRegistryResolver_Resolve(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := registryResponses[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	var tests = map[string]struct {
+		ecosystem string
+		name      string
+		version   string
+		expected  []types.Library
+		err       bool
+	}{
+		"npm": {
+			ecosystem: osv.EcosystemNpm,
+			name:      "express",
+			version:   "4.17.1",
+			expected: []types.Library{
+				{Name: "@types/qs", Version: "6.9.3"},
+				{Name: "debug", Version: "2.6.9"},
+			},
+		},
+		"PyPI": {
+			ecosystem: osv.EcosystemPyPI,
+			name:      "requests",
+			version:   "2.22.0",
+			expected: []types.Library{
+				{Name: "chardet", Version: "3.0.4"},
+				{Name: "idna", Version: "2.9"},
+			},
+		},
+		"UnknownVersion": {
+			ecosystem: osv.EcosystemNpm,
+			name:      "express",
+			version:   "5.0.0",
+			err:       true,
+		},
+		"NotFound": {
+			ecosystem: osv.EcosystemPyPI,
+			name:      "flask",
+			version:   "1.0.3",
+			err:       true,
+		},
+	}
+	for testName, v := range tests {
+		r := &RegistryResolver{Ecosystem: v.ecosystem, BaseURL: ts.URL}
+		libs, err := r.Resolve(v.name, v.version)
+		if (err != nil) != v.err {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.expected, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, libs)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package resolver expands the direct dependencies into the transitive ones
+// for files which don't have a lockfile such as requirements.txt.
+package resolver
+
+import (
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+// DependencyResolver returns the dependencies of the library with their resolved versions.
+type DependencyResolver interface {
+	Resolve(name, version string) ([]types.Library, error)
+}
+
+// NullResolver resolves nothing, so only the direct dependencies are returned.
+type NullResolver struct{}
+
+func (r NullResolver) Resolve(name, version string) ([]types.Library, error) {
+	return nil, nil
+}
+
+// ExpandTransitiveDeps returns the direct dependencies followed by the transitive ones in the breadth-first order.
+// Each library appears once even if the dependencies have cycles.
+func ExpandTransitiveDeps(direct []types.Library, resolver DependencyResolver) ([]types.Library, error) {
+	var libs []types.Library
+	seen := map[types.Library]struct{}{}
+	queue := append([]types.Library{}, direct...)
+	for len(queue) > 0 {
+		lib := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[lib]; ok {
+			continue
+		}
+		seen[lib] = struct{}{}
+		libs = append(libs, lib)
+
+		deps, err := resolver.Resolve(lib.Name, lib.Version)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to resolve %s@%s: %w", lib.Name, lib.Version, err)
+		}
+		queue = append(queue, deps...)
+	}
+	return libs, nil
+}
@@ -0,0 +1,71 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/knqyf263/go-dep-parser/pkg/types"
+	"golang.org/x/xerrors"
+)
+
+type mapResolver map[types.Library][]types.Library
+
+func (r mapResolver) Resolve(name, version string) ([]types.Library, error) {
+	deps, ok := r[types.Library{Name: name, Version: version}]
+	if !ok {
+		return nil, xerrors.New("not found")
+	}
+	return deps, nil
+}
+
+func TestExpandTransitiveDeps(t *testing.T) {
+	flask := types.Library{Name: "flask", Version: "1.0.3"}
+	werkzeug := types.Library{Name: "werkzeug", Version: "0.15.4"}
+	jinja2 := types.Library{Name: "jinja2", Version: "2.10.1"}
+	markupsafe := types.Library{Name: "markupsafe", Version: "1.1.1"}
+
+	var tests = map[string]struct {
+		direct   []types.Library
+		resolver DependencyResolver
+		expected []types.Library
+		err      bool
+	}{
+		"Null": {
+			direct:   []types.Library{flask, jinja2},
+			resolver: NullResolver{},
+			expected: []types.Library{flask, jinja2},
+		},
+		"Transitive": {
+			direct: []types.Library{flask, markupsafe},
+			resolver: mapResolver{
+				flask:      {werkzeug, jinja2},
+				werkzeug:   nil,
+				jinja2:     {markupsafe},
+				markupsafe: nil,
+			},
+			expected: []types.Library{flask, markupsafe, werkzeug, jinja2},
+		},
+		"Cycle": {
+			direct: []types.Library{flask},
+			resolver: mapResolver{
+				flask:    {werkzeug},
+				werkzeug: {flask},
+			},
+			expected: []types.Library{flask, werkzeug},
+		},
+		"Error": {
+			direct:   []types.Library{flask},
+			resolver: mapResolver{},
+			err:      true,
+		},
+	}
+	for testName, v := range tests {
+		libs, err := ExpandTransitiveDeps(v.direct, v.resolver)
+		if (err != nil) != v.err {
+			t.Errorf("[%s] unexpected error: %v", testName, err)
+		}
+		if !reflect.DeepEqual(v.expected, libs) {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, libs)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package resolver
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version is a simplified version of SemVer and PEP 440.
+// Only the numeric release segments are compared precisely, and anything after them is a pre-release.
+type version struct {
+	release []int
+	pre     string
+}
+
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	end := 0
+	for end < len(s) && (s[end] == '.' || ('0' <= s[end] && s[end] <= '9')) {
+		end++
+	}
+	release := strings.TrimSuffix(s[:end], ".")
+	if release == "" {
+		return version{}, false
+	}
+
+	var v version
+	for _, part := range strings.Split(release, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return version{}, false
+		}
+		v.release = append(v.release, n)
+	}
+	// Build metadata such as "+build.1" and local versions such as "+ubuntu" don't affect the precedence
+	rest := s[end:]
+	if i := strings.Index(rest, "+"); i >= 0 {
+		rest = rest[:i]
+	}
+	v.pre = strings.TrimLeft(rest, "-.")
+	return v, true
+}
+
+func (v version) compare(other version) int {
+	for i := 0; i < len(v.release) || i < len(other.release); i++ {
+		a, b := v.segment(i), other.segment(i)
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.pre == other.pre:
+		return 0
+	case v.pre == "":
+		return 1
+	case other.pre == "":
+		return -1
+	case v.pre < other.pre:
+		return -1
+	}
+	return 1
+}
+
+func (v version) segment(i int) int {
+	if i < len(v.release) {
+		return v.release[i]
+	}
+	return 0
+}
+
+// bump returns the version incrementing the i-th segment and dropping the rest, e.g. bump(1.2.3, 0) = 2
+func (v version) bump(i int) version {
+	release := make([]int, i+1)
+	for j := 0; j < i; j++ {
+		release[j] = v.segment(j)
+	}
+	release[i] = v.segment(i) + 1
+	return version{release: release}
+}
+
+// lower returns the lowest version of the next one, e.g. 2.0.0-0 for 2, so that pre-releases of it don't match.
+func (v version) lower() version {
+	return version{release: v.release, pre: "0"}
+}
+
+type comparator func(version) bool
+
+func between(min, max version) comparator {
+	return func(v version) bool {
+		return v.compare(min) >= 0 && v.compare(max.lower()) < 0
+	}
+}
+
+// parseWildcard parses "1.2", "1.2.x" and "1.2.*" into the version and the number of the given segments.
+func parseWildcard(s string) (version, int, bool) {
+	var parts []string
+	for _, part := range strings.Split(s, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return version{}, 0, true
+	}
+	v, ok := parseVersion(strings.Join(parts, "."))
+	return v, len(parts), ok
+}
+
+// prefix matches the versions starting with the given segments, e.g. 1.2 matches 1.2.0 and 1.2.9.
+func prefix(s string) (comparator, bool) {
+	v, n, ok := parseWildcard(s)
+	if !ok {
+		return nil, false
+	}
+	if n == 0 {
+		return func(version) bool { return true }, true
+	}
+	if v.pre != "" || n >= 3 {
+		return func(other version) bool { return other.compare(v) == 0 }, true
+	}
+	return between(v, v.bump(n-1)), true
+}
+
+func compareWith(op string, s string) (comparator, bool) {
+	v, ok := parseVersion(s)
+	if !ok {
+		return nil, false
+	}
+	switch op {
+	case ">=":
+		return func(other version) bool { return other.compare(v) >= 0 }, true
+	case ">":
+		return func(other version) bool { return other.compare(v) > 0 }, true
+	case "<=":
+		return func(other version) bool { return other.compare(v) <= 0 }, true
+	case "<":
+		return func(other version) bool { return other.compare(v) < 0 }, true
+	}
+	return nil, false
+}
+
+// parseNpmRange supports the ranges of node-semver such as "^1.2.3", "~1.2", ">=1.0.0 <2.0.0", "1.x || 2.x" and "1.0 - 2.0".
+// The returned alternatives are ORed and the comparators in each are ANDed.
+func parseNpmRange(s string) ([][]comparator, bool) {
+	var alternatives [][]comparator
+	for _, alt := range strings.Split(s, "||") {
+		var comparators []comparator
+		fields := strings.Fields(alt)
+		if len(fields) == 3 && fields[1] == "-" {
+			fields = []string{">=" + fields[0], "<=" + fields[2]}
+		}
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			// e.g. ">= 1.0.0"
+			if strings.Trim(field, "<>=~^") == "" && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+			c, ok := parseNpmComparator(field)
+			if !ok {
+				return nil, false
+			}
+			comparators = append(comparators, c)
+		}
+		if len(comparators) == 0 {
+			comparators = []comparator{func(version) bool { return true }}
+		}
+		alternatives = append(alternatives, comparators)
+	}
+	return alternatives, true
+}
+
+func parseNpmComparator(s string) (comparator, bool) {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(s, op) {
+			return compareWith(op, strings.TrimPrefix(s, op))
+		}
+	}
+	switch {
+	case strings.HasPrefix(s, "^"):
+		v, n, ok := parseWildcard(strings.TrimPrefix(s, "^"))
+		if !ok || n == 0 {
+			return func(version) bool { return true }, ok
+		}
+		// The first non-zero segment can't be changed, e.g. ^0.2.3 := >=0.2.3 <0.3.0
+		i := 0
+		for i < n-1 && v.segment(i) == 0 {
+			i++
+		}
+		return between(v, v.bump(i)), true
+	case strings.HasPrefix(s, "~"):
+		v, n, ok := parseWildcard(strings.TrimPrefix(s, "~"))
+		if !ok || n == 0 {
+			return func(version) bool { return true }, ok
+		}
+		if n == 1 {
+			return between(v, v.bump(0)), true
+		}
+		return between(v, v.bump(1)), true
+	}
+	return prefix(strings.TrimPrefix(s, "="))
+}
+
+// parsePEP440 supports the version specifiers such as ">=2.0,<3", "~=1.4.5", "==1.2.*" and "!=1.3".
+func parsePEP440(s string) ([][]comparator, bool) {
+	var comparators []comparator
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		var c comparator
+		var ok bool
+		switch {
+		case spec == "":
+			continue
+		case strings.HasPrefix(spec, "==="):
+			c, ok = prefix(strings.TrimPrefix(spec, "==="))
+		case strings.HasPrefix(spec, "=="):
+			c, ok = prefix(strings.TrimSpace(strings.TrimPrefix(spec, "==")))
+		case strings.HasPrefix(spec, "!="):
+			c, ok = prefix(strings.TrimSpace(strings.TrimPrefix(spec, "!=")))
+			if ok {
+				eq := c
+				c = func(v version) bool { return !eq(v) }
+			}
+		case strings.HasPrefix(spec, "~="):
+			// e.g. ~=1.4.5 := >=1.4.5, ==1.4.*
+			var v version
+			v, ok = parseVersion(strings.TrimPrefix(spec, "~="))
+			if ok && len(v.release) >= 2 {
+				c = between(v, v.bump(len(v.release)-2))
+			} else {
+				ok = false
+			}
+		default:
+			for _, op := range []string{">=", "<=", ">", "<"} {
+				if strings.HasPrefix(spec, op) {
+					c, ok = compareWith(op, strings.TrimSpace(strings.TrimPrefix(spec, op)))
+					break
+				}
+			}
+		}
+		if !ok {
+			return nil, false
+		}
+		comparators = append(comparators, c)
+	}
+	if len(comparators) == 0 {
+		comparators = []comparator{func(version) bool { return true }}
+	}
+	return [][]comparator{comparators}, true
+}
+
+// maxSatisfying returns the highest version satisfying the constraint.
+// Pre-releases are chosen only if no stable version satisfies it.
+func maxSatisfying(candidates []string, constraint [][]comparator) (string, bool) {
+	var best string
+	var bestVersion version
+	for _, stableOnly := range []bool{true, false} {
+		for _, candidate := range candidates {
+			v, ok := parseVersion(candidate)
+			if !ok || stableOnly && v.pre != "" || !satisfies(v, constraint) {
+				continue
+			}
+			if best == "" || v.compare(bestVersion) > 0 {
+				best, bestVersion = candidate, v
+			}
+		}
+		if best != "" {
+			return best, true
+		}
+	}
+	return "", false
+}
+
+func satisfies(v version, constraint [][]comparator) bool {
+	for _, comparators := range constraint {
+		matched := true
+		for _, c := range comparators {
+			if !c(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package resolver
+
+import "testing"
+
+func TestMaxSatisfying(t *testing.T) {
+	npmVersions := []string{"0.2.3", "0.2.9", "0.3.0", "1.1.0", "1.2.3", "1.2.9", "1.3.0", "1.9.9", "2.0.0-beta.1", "2.0.0", "2.1.0"}
+	pypiVersions := []string{"1.4.4", "1.4.5", "1.4.9", "1.5.0", "2.0", "2.2", "2.9.1", "3.0", "3.1a1"}
+
+	var tests = map[string]struct {
+		npm        bool
+		constraint string
+		expected   string
+	}{
+		"NpmCaret":       {npm: true, constraint: "^1.2.3", expected: "1.9.9"},
+		"NpmCaretZero":   {npm: true, constraint: "^0.2.3", expected: "0.2.9"},
+		"NpmTilde":       {npm: true, constraint: "~1.2.3", expected: "1.2.9"},
+		"NpmTildeMajor":  {npm: true, constraint: "~1", expected: "1.9.9"},
+		"NpmExact":       {npm: true, constraint: "1.2.3", expected: "1.2.3"},
+		"NpmX":           {npm: true, constraint: "1.x", expected: "1.9.9"},
+		"NpmAny":         {npm: true, constraint: "*", expected: "2.1.0"},
+		"NpmRange":       {npm: true, constraint: ">= 1.2.0 <1.3.0", expected: "1.2.9"},
+		"NpmHyphen":      {npm: true, constraint: "1.1.0 - 1.3.0", expected: "1.3.0"},
+		"NpmOr":          {npm: true, constraint: "0.2.x || 1.1.x", expected: "1.1.0"},
+		"NpmPreRelease":  {npm: true, constraint: "2.0.0-beta.1", expected: "2.0.0-beta.1"},
+		"NpmNoMatch":     {npm: true, constraint: "^3.0.0"},
+		"PEP440Range":    {constraint: ">=2.0,<3", expected: "2.9.1"},
+		"PEP440Compat":   {constraint: "~=1.4.5", expected: "1.4.9"},
+		"PEP440Compat2":  {constraint: "~=2.2", expected: "2.9.1"},
+		"PEP440Wildcard": {constraint: "==1.4.*", expected: "1.4.9"},
+		"PEP440Exclude":  {constraint: ">=1.4,!=1.5.0,<2", expected: "1.4.9"},
+		"PEP440Any":      {constraint: "", expected: "3.0"},
+	}
+	for testName, v := range tests {
+		candidates := pypiVersions
+		parse := parsePEP440
+		if v.npm {
+			candidates, parse = npmVersions, parseNpmRange
+		}
+		constraint, ok := parse(v.constraint)
+		if !ok {
+			t.Errorf("[%s] failed to parse %s", testName, v.constraint)
+			continue
+		}
+		actual, _ := maxSatisfying(candidates, constraint)
+		if actual != v.expected {
+			t.Errorf("[%s]\nexpected : %v\nactual : %v", testName, v.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package token
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// acrUsername is the user name for ACR refresh tokens.
+	acrUsername = "00000000-0000-0000-0000-000000000000"
+	// acrRefreshMargin is how long before the expiry the refresh token is exchanged again.
+	// ACR refresh tokens are valid for 3 hours.
+	acrRefreshMargin = 5 * time.Minute
+	// imdsTimeout is short because the metadata server is not reachable outside of Azure.
+	imdsTimeout = 2 * time.Second
+)
+
+// azureCloud is the endpoints of the public and the sovereign clouds.
+type azureCloud struct {
+	registrySuffix string
+	authorityHost  string
+	resource       string
+}
+
+var azureClouds = []azureCloud{
+	{registrySuffix: ".azurecr.io", authorityHost: "login.microsoftonline.com", resource: "https://management.azure.com/"},
+	{registrySuffix: ".azurecr.us", authorityHost: "login.microsoftonline.us", resource: "https://management.usgovcloudapi.net/"},
+	{registrySuffix: ".azurecr.cn", authorityHost: "login.chinacloudapi.cn", resource: "https://management.chinacloudapi.cn/"},
+}
+
+var (
+	acrTokensMu sync.Mutex
+	acrTokens   = map[string]acrToken{}
+)
+
+type acrToken struct {
+	refreshToken string
+	expiresAt    time.Time
+}
+
+// ACR exchanges an Azure Active Directory token for a refresh token of Azure Container Registry.
+type ACR struct {
+	// Host is the host of the registry, e.g. myregistry.azurecr.io.
+	Host   string
+	Client *http.Client
+	// AADToken returns an AAD access token of the resource. If nil, the default credential chain is used,
+	// i.e. AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET, the managed identity and the Azure CLI.
+	AADToken func(ctx context.Context, resource string) (string, error)
+	// Tenant is the tenant of the AAD token. If empty, the tenant in the token or AZURE_TENANT_ID is used.
+	Tenant string
+
+	cloud azureCloud
+}
+
+func NewACR(serverAddress string) *ACR {
+	host := hostname(serverAddress)
+	cloud, _ := acrCloud(host)
+	return &ACR{
+		Host:   host,
+		Client: http.DefaultClient,
+		cloud:  cloud,
+	}
+}
+
+// IsACR reports whether the server address is Azure Container Registry, e.g. myregistry.azurecr.io.
+func IsACR(serverAddress string) bool {
+	_, ok := acrCloud(hostname(serverAddress))
+	return ok
+}
+
+func acrCloud(host string) (azureCloud, bool) {
+	for _, c := range azureClouds {
+		if strings.HasSuffix(host, c.registrySuffix) {
+			return c, true
+		}
+	}
+	return azureCloud{}, false
+}
+
+// GetCredential returns the refresh token as the password of the null GUID.
+// The refresh token is cached until shortly before it expires.
+func (a *ACR) GetCredential(ctx context.Context) (username, password string, err error) {
+	acrTokensMu.Lock()
+	defer acrTokensMu.Unlock()
+	if t, ok := acrTokens[a.Host]; ok && now().Add(acrRefreshMargin).Before(t.expiresAt) {
+		return acrUsername, t.refreshToken, nil
+	}
+
+	getAADToken := a.AADToken
+	if getAADToken == nil {
+		getAADToken = a.defaultAADToken
+	}
+	accessToken, err := getAADToken(ctx, a.cloud.resource)
+	if err != nil {
+		return "", "", xerrors.Errorf("failed to get the AAD token: %w", err)
+	}
+
+	refreshToken, err := a.exchange(ctx, accessToken)
+	if err != nil {
+		return "", "", xerrors.Errorf("failed to exchange the AAD token for the ACR refresh token: %w", err)
+	}
+	if claims, err := parseJWTClaims(refreshToken); err == nil && claims.Exp > 0 {
+		acrTokens[a.Host] = acrToken{refreshToken: refreshToken, expiresAt: time.Unix(claims.Exp, 0)}
+	}
+	return acrUsername, refreshToken, nil
+}
+
+// exchange requests POST /oauth2/exchange of the registry.
+func (a *ACR) exchange(ctx context.Context, accessToken string) (string, error) {
+	form := url.Values{
+		"grant_type":   {"access_token"},
+		"service":      {a.Host},
+		"access_token": {accessToken},
+	}
+	// The tenant of the token, which may differ from the home tenant of the user
+	tenant := a.Tenant
+	if tenant == "" {
+		tenant = os.Getenv("AZURE_TENANT_ID")
+		if claims, err := parseJWTClaims(accessToken); err == nil && claims.Tid != "" {
+			tenant = claims.Tid
+		}
+	}
+	if tenant != "" {
+		form.Set("tenant", tenant)
+	}
+
+	var resp struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := postForm(ctx, a.Client, "https://"+a.Host+"/oauth2/exchange", form, &resp); err != nil {
+		return "", err
+	}
+	if resp.RefreshToken == "" {
+		return "", xerrors.New("empty refresh token")
+	}
+	return resp.RefreshToken, nil
+}
+
+// defaultAADToken tries the credentials in the order of the default credential chain of the Azure SDKs.
+func (a *ACR) defaultAADToken(ctx context.Context, resource string) (string, error) {
+	var errs []string
+	for _, f := range []func(context.Context, string) (string, error){
+		a.environmentAADToken, a.managedIdentityAADToken, cliAADToken,
+	} {
+		t, err := f(ctx, resource)
+		if err == nil {
+			return t, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return "", xerrors.Errorf("no Azure credentials: %s", strings.Join(errs, "; "))
+}
+
+// environmentAADToken uses the service principal in AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET.
+func (a *ACR) environmentAADToken(ctx context.Context, resource string) (string, error) {
+	tenantID, clientID, secret := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET")
+	if tenantID == "" || clientID == "" || secret == "" {
+		return "", xerrors.New("environment: AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET are not set")
+	}
+	t, err := a.ClientSecretAADToken(ctx, os.Getenv("AZURE_AUTHORITY_HOST"), tenantID, clientID, secret, resource)
+	if err != nil {
+		return "", xerrors.Errorf("environment: %w", err)
+	}
+	return t, nil
+}
+
+// ClientSecretAADToken returns the AAD access token of the service principal. If authorityHost is empty,
+// the authority of the cloud of the registry is used, e.g. login.microsoftonline.us for *.azurecr.us.
+func (a *ACR) ClientSecretAADToken(ctx context.Context, authorityHost, tenantID, clientID, secret, resource string) (string, error) {
+	if authorityHost == "" {
+		authorityHost = a.cloud.authorityHost
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {clientID},
+		"client_secret": {secret},
+		"resource":      {resource},
+	}
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	u := "https://" + hostname(authorityHost) + "/" + url.PathEscape(tenantID) + "/oauth2/token"
+	if err := postForm(ctx, a.Client, u, form, &resp); err != nil {
+		return "", err
+	}
+	return resp.AccessToken, nil
+}
+
+// managedIdentityAADToken requests the token of the managed identity to the instance metadata service.
+// AZURE_CLIENT_ID selects one of the user-assigned identities.
+func (a *ACR) managedIdentityAADToken(ctx context.Context, resource string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+
+	q := url.Values{"api-version": {"2018-02-01"}, "resource": {resource}}
+	if clientID := os.Getenv("AZURE_CLIENT_ID"); clientID != "" {
+		q.Set("client_id", clientID)
+	}
+	req, err := http.NewRequest(http.MethodGet, "http://169.254.169.254/metadata/identity/oauth2/token?"+q.Encode(), nil)
+	if err != nil {
+		return "", xerrors.Errorf("managed identity: %w", err)
+	}
+	req.Header.Set("Metadata", "true")
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = doJSON(a.Client, req.WithContext(ctx), &resp); err != nil {
+		return "", xerrors.Errorf("managed identity: %w", err)
+	}
+	return resp.AccessToken, nil
+}
+
+// cliAADToken uses the account logged in with "az login".
+func cliAADToken(ctx context.Context, resource string) (string, error) {
+	out, err := exec.CommandContext(ctx, "az", "account", "get-access-token", "--resource", resource, "--output", "json").Output()
+	if err != nil {
+		return "", xerrors.Errorf("azure cli: %w", err)
+	}
+	var resp struct {
+		AccessToken string `json:"accessToken"`
+	}
+	if err = json.Unmarshal(out, &resp); err != nil {
+		return "", xerrors.Errorf("azure cli: invalid output: %w", err)
+	}
+	return resp.AccessToken, nil
+}
+
+func postForm(ctx context.Context, client *http.Client, u string, form url.Values, v interface{}) error {
+	req, err := http.NewRequest(http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doJSON(client, req.WithContext(ctx), v)
+}
+
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("%s %s: %s", req.Method, req.URL.Host+req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+type jwtClaims struct {
+	Tid string `json:"tid"`
+	Exp int64  `json:"exp"`
+}
+
+// parseJWTClaims decodes the claims of the token without verifying the signature.
+func parseJWTClaims(token string) (jwtClaims, error) {
+	var claims jwtClaims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, xerrors.New("invalid JWT format")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return claims, xerrors.Errorf("invalid JWT format: %w", err)
+	}
+	if err = json.Unmarshal(b, &claims); err != nil {
+		return claims, xerrors.Errorf("invalid JWT format: %w", err)
+	}
+	return claims, nil
+}
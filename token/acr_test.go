@@ -0,0 +1,98 @@
+package token
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testJWT(t *testing.T, claims jwtClaims) string {
+	b, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(b) + ".sig"
+}
+
+func TestACRGetCredential(t *testing.T) {
+	defer func() { now = time.Now }()
+	issuedAt := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	accessToken := testJWT(t, jwtClaims{Tid: "tenant-id"})
+	refreshToken := testJWT(t, jwtClaims{Exp: issuedAt.Add(3 * time.Hour).Unix()})
+
+	var calls int
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/oauth2/exchange" {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range map[string]string{
+			"grant_type":   "access_token",
+			"service":      r.Host,
+			"tenant":       "tenant-id",
+			"access_token": accessToken,
+		} {
+			if r.PostForm.Get(k) != v {
+				t.Errorf("%s: expected %q, got %q", k, v, r.PostForm.Get(k))
+			}
+		}
+		w.Write([]byte(`{"refresh_token": "` + refreshToken + `"}`))
+	}))
+	defer ts.Close()
+
+	a := &ACR{
+		Host:   strings.TrimPrefix(ts.URL, "https://"),
+		Client: ts.Client(),
+		AADToken: func(ctx context.Context, resource string) (string, error) {
+			return accessToken, nil
+		},
+	}
+	cases := []struct {
+		now           time.Time
+		expectedCalls int
+	}{
+		{now: issuedAt, expectedCalls: 1},
+		{now: issuedAt.Add(2 * time.Hour), expectedCalls: 1},
+		// shortly before the expiry
+		{now: issuedAt.Add(3*time.Hour - time.Minute), expectedCalls: 2},
+	}
+	for i, c := range cases {
+		now = func() time.Time { return c.now }
+		username, password, err := a.GetCredential(context.Background())
+		if err != nil {
+			t.Fatalf("%d, unexpected error: %v", i, err)
+		}
+		if username != acrUsername || password != refreshToken {
+			t.Fatalf("%d, unexpected credential: %s:%s", i, username, password)
+		}
+		if calls != c.expectedCalls {
+			t.Fatalf("%d, calls: expected %d, got %d", i, c.expectedCalls, calls)
+		}
+	}
+}
+
+func TestIsACR(t *testing.T) {
+	cases := map[string]bool{
+		"myregistry.azurecr.io":          true,
+		"https://myregistry.azurecr.io/": true,
+		"myregistry.azurecr.us":          true,
+		"myregistry.azurecr.cn":          true,
+		"azurecr.io":                     false,
+		"myregistry.azurecr.io.example":  false,
+	}
+	for serverAddress, expected := range cases {
+		if actual := IsACR(serverAddress); actual != expected {
+			t.Errorf("%s: expected %v, got %v", serverAddress, expected, actual)
+		}
+	}
+}
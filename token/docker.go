@@ -1,14 +1,184 @@
 package token
 
-import "context"
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
+	"golang.org/x/xerrors"
+)
+
+// dockerHubServer is the key of Docker Hub in the docker config, which is not the host of the registry.
+const dockerHubServer = "https://index.docker.io/v1/"
+
+// Docker reads the credentials saved by "docker login" from the docker config,
+// i.e. $DOCKER_CONFIG/config.json or ~/.docker/config.json.
 type Docker struct {
+	ServerAddress string
+	// ConfigDir is the directory of config.json. If empty, $DOCKER_CONFIG or ~/.docker is used.
+	ConfigDir string
+}
+
+type dockerConfig struct {
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredsStore  string                `json:"credsStore"`
+	CredHelpers map[string]string     `json:"credHelpers"`
+}
+
+type dockerAuth struct {
+	Auth     string `json:"auth"`
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
-func NewDocker() *Docker {
-	return &Docker{}
+// helperCredential is the output of "docker-credential-<helper> get".
+type helperCredential struct {
+	Username string `json:"Username"`
+	Secret   string `json:"Secret"`
 }
 
+func NewDocker(serverAddress string) *Docker {
+	return &Docker{ServerAddress: serverAddress}
+}
+
+// GetCredential returns the credential of the registry in the credential helper or the auths of the docker config.
+// It returns no credential without the docker config. If the helper fails, the auths are used instead.
 func (d *Docker) GetCredential(ctx context.Context) (username, password string, err error) {
-	return "", "", nil
+	cfg, err := d.loadConfig()
+	if os.IsNotExist(xerrors.Unwrap(err)) {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", err
+	}
+
+	keys := dockerConfigKeys(d.ServerAddress)
+	if helper := cfg.helper(keys); helper != "" {
+		username, password, err = runCredentialHelper(ctx, helper, keys[0])
+		if err != nil {
+			log.Printf("failed to get the credential from docker-credential-%s: %s", helper, err)
+		} else if username != "" || password != "" {
+			return username, password, nil
+		}
+	}
+
+	auth, ok := cfg.auth(keys)
+	if !ok {
+		return "", "", nil
+	}
+	if auth.Auth == "" {
+		return auth.Username, auth.Password, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(auth.Auth)
+	if err != nil {
+		return "", "", xerrors.Errorf("invalid auth of %s: %w", keys[0], err)
+	}
+	// e.g. user:password
+	split := strings.SplitN(string(b), ":", 2)
+	if len(split) != 2 {
+		return "", "", xerrors.Errorf("invalid auth of %s", keys[0])
+	}
+	return split[0], split[1], nil
+}
+
+func (d *Docker) loadConfig() (dockerConfig, error) {
+	dir := d.ConfigDir
+	if dir == "" {
+		dir = os.Getenv("DOCKER_CONFIG")
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return dockerConfig{}, xerrors.Errorf("failed to find the home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	var cfg dockerConfig
+	b, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return cfg, xerrors.Errorf("failed to read the docker config: %w", err)
+	}
+	if err = json.Unmarshal(b, &cfg); err != nil {
+		return cfg, xerrors.Errorf("invalid docker config format: %w", err)
+	}
+	return cfg, nil
+}
+
+// helper returns the credential helper of the registry, which is credHelpers or the default credsStore.
+func (c dockerConfig) helper(keys []string) string {
+	for _, k := range keys {
+		if h, ok := c.CredHelpers[k]; ok {
+			return h
+		}
+	}
+	return c.CredsStore
+}
+
+func (c dockerConfig) auth(keys []string) (dockerAuth, bool) {
+	for _, k := range keys {
+		if a, ok := c.Auths[k]; ok {
+			return a, true
+		}
+	}
+	// e.g. https://registry.example.com/v1/
+	for k, a := range c.Auths {
+		if registryHost(k) == keys[0] {
+			return a, true
+		}
+	}
+	return dockerAuth{}, false
+}
+
+// dockerConfigKeys returns the keys of the registry in the docker config in the order of priority.
+// The first key is passed to the credential helpers.
+func dockerConfigKeys(serverAddress string) []string {
+	host := registryHost(serverAddress)
+	switch host {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return []string{dockerHubServer, "index.docker.io", "docker.io", "registry-1.docker.io"}
+	}
+	return []string{host, "https://" + host, "http://" + host}
+}
+
+// registryHost returns the host and the port without the scheme and the path,
+// e.g. "https://localhost:5000/v2/" => "localhost:5000"
+func registryHost(serverAddress string) string {
+	host := serverAddress
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	return strings.SplitN(host, "/", 2)[0]
+}
+
+// runCredentialHelper runs "docker-credential-<helper> get" with the server URL in stdin.
+func runCredentialHelper(ctx context.Context, helper, serverURL string) (username, password string, err error) {
+	cmd := exec.CommandContext(ctx, "docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err = cmd.Run(); err != nil {
+		// The helpers print the error in stdout
+		msg := strings.TrimSpace(stdout.String())
+		if strings.Contains(msg, "credentials not found") {
+			return "", "", nil
+		}
+		return "", "", xerrors.Errorf("%s: %w", msg, err)
+	}
+
+	var cred helperCredential
+	if err = json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return "", "", xerrors.Errorf("invalid output format: %w", err)
+	}
+	// Identity tokens need the OAuth2 flow, which is not supported by the registry client
+	if cred.Username == "<token>" {
+		return "", "", xerrors.New("identity tokens are not supported")
+	}
+	return cred.Username, cred.Secret, nil
 }
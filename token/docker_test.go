@@ -0,0 +1,110 @@
+package token
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDockerConfig = `{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "aHViOmh1Yi1wYXNz"},
+    "https://registry.example.com/v1/": {"auth": "ZXhhbXBsZTpleGFtcGxlLXBhc3M="},
+    "localhost:5000": {"username": "local", "password": "local-pass"},
+    "broken.example.com": {"auth": "aGVscGVyLWZhbGxiYWNr"},
+    "fallback.example.com": {"auth": "ZmFsbGJhY2s6ZmFsbGJhY2stcGFzcw=="}
+  },
+  "credHelpers": {
+    "helper.example.com": "test",
+    "fallback.example.com": "missing"
+  }
+}`
+
+// The helper echoes the server URL as the user name
+const testCredentialHelper = `#!/bin/sh
+read server
+echo "{\"ServerURL\": \"$server\", \"Username\": \"$server\", \"Secret\": \"helper-pass\"}"
+`
+
+func TestDockerGetCredential(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(testDockerConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "docker-credential-test"), []byte(testCredentialHelper), 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var tests = map[string]struct {
+		serverAddress    string
+		configDir        string
+		expectedUsername string
+		expectedPassword string
+		wantErr          bool
+	}{
+		"docker hub": {
+			serverAddress:    "https://registry-1.docker.io",
+			expectedUsername: "hub",
+			expectedPassword: "hub-pass",
+		},
+		"path in the key": {
+			serverAddress:    "registry.example.com",
+			expectedUsername: "example",
+			expectedPassword: "example-pass",
+		},
+		"port": {
+			serverAddress:    "http://localhost:5000",
+			expectedUsername: "local",
+			expectedPassword: "local-pass",
+		},
+		"credential helper": {
+			serverAddress:    "helper.example.com",
+			expectedUsername: "helper.example.com",
+			expectedPassword: "helper-pass",
+		},
+		"missing credential helper": {
+			serverAddress:    "fallback.example.com",
+			expectedUsername: "fallback",
+			expectedPassword: "fallback-pass",
+		},
+		"no credential": {
+			serverAddress: "gcr.io",
+		},
+		"no config": {
+			serverAddress: "registry.example.com",
+			configDir:     filepath.Join(dir, "not-found"),
+		},
+		"invalid auth": {
+			serverAddress: "broken.example.com",
+			wantErr:       true,
+		},
+	}
+	for testname, v := range tests {
+		d := &Docker{ServerAddress: v.serverAddress, ConfigDir: dir}
+		if v.configDir != "" {
+			d.ConfigDir = v.configDir
+		}
+		username, password, err := d.GetCredential(context.Background())
+		if v.wantErr {
+			if err == nil {
+				t.Errorf("[%s] expected error but got nil", testname)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", testname, err)
+			continue
+		}
+		if username != v.expectedUsername || password != v.expectedPassword {
+			t.Errorf("[%s]\nexpected : %s:%s\nactual : %s:%s", testname, v.expectedUsername, v.expectedPassword, username, password)
+		}
+	}
+}
@@ -3,18 +3,48 @@ package token
 import (
 	"context"
 	"encoding/base64"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
 	"golang.org/x/xerrors"
 )
 
+// ecrRefreshMargin is how long before the expiry the token is refreshed. ECR tokens are valid for 12 hours.
+const ecrRefreshMargin = 30 * time.Minute
+
+// e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com, 123456789012.dkr.ecr-fips.us-gov-west-1.amazonaws.com
+// and 123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn
+var ecrHostRegexp = regexp.MustCompile(`^([0-9]{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+var (
+	ecrTokensMu sync.Mutex
+	ecrTokens   = map[string]ecrToken{}
+	now         = time.Now
+)
+
+type ecrToken struct {
+	username  string
+	password  string
+	expiresAt time.Time
+}
+
 type ECR struct {
 	Client ecriface.ECRAPI
+	// RegistryID is the account ID of the registry, required for registries in other accounts.
+	// If empty, the token of the default registry of the account is returned.
+	RegistryID string
+	// Region is the region of the registry. Tokens are cached per region and registry.
+	Region string
 }
 
+// NewECR returns ECR using the default credential chain, i.e. environment variables,
+// the shared config and the IAM role.
 func NewECR() *ECR {
 	sess := session.Must(session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
@@ -25,8 +55,53 @@ func NewECR() *ECR {
 	}
 }
 
+// NewECRForRegistry returns ECR for the registry in the region and the account of the host,
+// e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com.
+func NewECRForRegistry(serverAddress string) *ECR {
+	registryID, region, ok := ParseECRHost(serverAddress)
+	if !ok {
+		return NewECR()
+	}
+	sess := session.Must(session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            aws.Config{Region: aws.String(region)},
+	}))
+	return &ECR{
+		Client:     ecr.New(sess),
+		RegistryID: registryID,
+		Region:     region,
+	}
+}
+
+// isECR reports whether the server address is a private registry of ECR.
+func isECR(serverAddress string) bool {
+	_, _, ok := ParseECRHost(serverAddress)
+	return ok
+}
+
+// ParseECRHost returns the account ID and the region in the host of the private registry of ECR.
+func ParseECRHost(serverAddress string) (registryID, region string, ok bool) {
+	m := ecrHostRegexp.FindStringSubmatch(hostname(serverAddress))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// GetCredential returns the user name and the password decoded from the authorization token.
+// The token is cached until shortly before it expires.
 func (e *ECR) GetCredential(ctx context.Context) (username, password string, err error) {
+	ecrTokensMu.Lock()
+	defer ecrTokensMu.Unlock()
+	key := e.Region + "/" + e.RegistryID
+	if t, ok := ecrTokens[key]; ok && now().Add(ecrRefreshMargin).Before(t.expiresAt) {
+		return t.username, t.password, nil
+	}
+
 	input := &ecr.GetAuthorizationTokenInput{}
+	if e.RegistryID != "" {
+		input.RegistryIds = []*string{aws.String(e.RegistryID)}
+	}
 
 	result, err := e.Client.GetAuthorizationTokenWithContext(ctx, input)
 	if err != nil {
@@ -41,6 +116,9 @@ func (e *ECR) GetCredential(ctx context.Context) (username, password string, err
 		// e.g. AWS:eyJwYXlsb2...
 		split := strings.SplitN(string(b), ":", 2)
 		if len(split) == 2 {
+			if data.ExpiresAt != nil {
+				ecrTokens[key] = ecrToken{username: split[0], password: split[1], expiresAt: *data.ExpiresAt}
+			}
 			return split[0], split[1], nil
 		}
 	}
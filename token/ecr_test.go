@@ -2,7 +2,9 @@ package token
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/request"
 
@@ -71,3 +73,76 @@ func TestECRGetCredential(t *testing.T) {
 		}
 	}
 }
+
+type countingECR struct {
+	ecriface.ECRAPI
+	calls       int
+	registryIDs []string
+	expiresAt   time.Time
+}
+
+func (m *countingECR) GetAuthorizationTokenWithContext(ctx context.Context, input *ecr.GetAuthorizationTokenInput, options ...request.Option) (*ecr.GetAuthorizationTokenOutput, error) {
+	m.calls++
+	m.registryIDs = aws.StringValueSlice(input.RegistryIds)
+	return &ecr.GetAuthorizationTokenOutput{
+		AuthorizationData: []*ecr.AuthorizationData{
+			{AuthorizationToken: aws.String("QVdTOnBhc3N3b3Jk"), ExpiresAt: aws.Time(m.expiresAt)},
+		},
+	}, nil
+}
+
+func TestECRGetCredential_Cache(t *testing.T) {
+	defer func() { now = time.Now }()
+	issuedAt := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	m := &countingECR{expiresAt: issuedAt.Add(12 * time.Hour)}
+	e := ECR{Client: m, RegistryID: "123456789012"}
+
+	cases := []struct {
+		now           time.Time
+		expectedCalls int
+	}{
+		{now: issuedAt, expectedCalls: 1},
+		{now: issuedAt.Add(11 * time.Hour), expectedCalls: 1},
+		// shortly before the expiry
+		{now: issuedAt.Add(11*time.Hour + 45*time.Minute), expectedCalls: 2},
+	}
+	for i, c := range cases {
+		now = func() time.Time { return c.now }
+		username, password, err := e.GetCredential(context.Background())
+		if err != nil {
+			t.Fatalf("%d, unexpected error: %v", i, err)
+		}
+		if username != "AWS" || password != "password" {
+			t.Fatalf("%d, unexpected credential: %s:%s", i, username, password)
+		}
+		if m.calls != c.expectedCalls {
+			t.Fatalf("%d, calls: expected %d, got %d", i, c.expectedCalls, m.calls)
+		}
+		if !reflect.DeepEqual(m.registryIDs, []string{"123456789012"}) {
+			t.Fatalf("%d, registryIds: expected %v, got %v", i, []string{"123456789012"}, m.registryIDs)
+		}
+	}
+}
+
+func TestParseECRHost(t *testing.T) {
+	cases := []struct {
+		serverAddress      string
+		expectedRegistryID string
+		expectedRegion     string
+		expectedOK         bool
+	}{
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com", "123456789012", "us-east-1", true},
+		{"https://123456789012.dkr.ecr.ap-northeast-1.amazonaws.com/v2/", "123456789012", "ap-northeast-1", true},
+		{"123456789012.dkr.ecr-fips.us-gov-west-1.amazonaws.com", "123456789012", "us-gov-west-1", true},
+		{"123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn", "123456789012", "cn-north-1", true},
+		{"public.ecr.aws", "", "", false},
+		{"s3.amazonaws.com", "", "", false},
+	}
+	for _, c := range cases {
+		registryID, region, ok := ParseECRHost(c.serverAddress)
+		if registryID != c.expectedRegistryID || region != c.expectedRegion || ok != c.expectedOK {
+			t.Errorf("%s: expected (%s, %s, %v), got (%s, %s, %v)", c.serverAddress,
+				c.expectedRegistryID, c.expectedRegion, c.expectedOK, registryID, region, ok)
+		}
+	}
+}
@@ -2,18 +2,37 @@ package token
 
 import (
 	"context"
-	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/docker/docker/api/types"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/xerrors"
 
 	"github.com/GoogleCloudPlatform/docker-credential-gcr/config"
 	"github.com/GoogleCloudPlatform/docker-credential-gcr/credhelper"
 	"github.com/GoogleCloudPlatform/docker-credential-gcr/store"
 )
 
+const (
+	// gcrUsername is the user name for OAuth2 access tokens accepted by GCR and Artifact Registry.
+	gcrUsername = "oauth2accesstoken"
+	gcrScope    = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+var (
+	defaultTokenSourceOnce sync.Once
+	defaultTokenSource     oauth2.TokenSource
+	defaultTokenSourceErr  error
+)
+
 type GCR struct {
 	Store store.GCRCredStore
 	Auth  types.AuthConfig
+	// TokenSource returns access tokens. If nil, the application default credentials are used,
+	// i.e. GOOGLE_APPLICATION_CREDENTIALS, the credentials of gcloud and the metadata server.
+	TokenSource oauth2.TokenSource
 }
 
 func NewGCR(auth types.AuthConfig, credPath string) *GCR {
@@ -26,21 +45,59 @@ func NewGCR(auth types.AuthConfig, credPath string) *GCR {
 	return &GCR{Auth: auth}
 }
 
+// IsGCR reports whether the server address is GCR or Artifact Registry,
+// e.g. gcr.io, asia.gcr.io and us-central1-docker.pkg.dev.
+func IsGCR(serverAddress string) bool {
+	host := hostname(serverAddress)
+	return host == gcrURL || strings.HasSuffix(host, "."+gcrURL) || strings.HasSuffix(host, "-docker.pkg.dev")
+}
+
+// GetCredential returns an access token as the password of "oauth2accesstoken".
+// The token is reused until shortly before it expires and then refreshed.
+// With the credential store of docker-credential-gcr, the credential is read from the store instead.
 func (g *GCR) GetCredential(ctx context.Context) (username, password string, err error) {
-	var credStore store.GCRCredStore
-	if g.Store == nil {
-		credStore, err = store.DefaultGCRCredStore()
-		if err != nil {
-			return "", "", err
+	if g.Store != nil {
+		return g.getStoredCredential(g.Store)
+	}
+
+	ts := g.TokenSource
+	if ts == nil {
+		if ts, err = applicationDefaultTokenSource(); err != nil {
+			// e.g. only "gcloud auth login" without the application default credentials
+			credStore, storeErr := store.DefaultGCRCredStore()
+			if storeErr != nil {
+				return "", "", xerrors.Errorf("failed to find the default credentials: %w", err)
+			}
+			return g.getStoredCredential(credStore)
 		}
-	} else {
-		credStore = g.Store
 	}
+	t, err := ts.Token()
+	if err != nil {
+		return "", "", xerrors.Errorf("failed to get the access token: %w", err)
+	}
+	return gcrUsername, t.AccessToken, nil
+}
+
+func (g *GCR) getStoredCredential(credStore store.GCRCredStore) (username, password string, err error) {
 	userCfg, err := config.LoadUserConfig()
 	if err != nil {
 		return "", "", err
 	}
-	fmt.Printf("%v, %v \n", credStore, userCfg)
 	helper := credhelper.NewGCRCredentialHelper(credStore, userCfg)
 	return helper.Get(g.Auth.ServerAddress)
 }
+
+// applicationDefaultTokenSource returns the token source shared by all the registries,
+// so that the token is not requested for each image.
+func applicationDefaultTokenSource() (oauth2.TokenSource, error) {
+	defaultTokenSourceOnce.Do(func() {
+		// The token source outlives the context of each call
+		creds, err := google.FindDefaultCredentials(context.Background(), gcrScope)
+		if err != nil {
+			defaultTokenSourceErr = err
+			return
+		}
+		defaultTokenSource = oauth2.ReuseTokenSource(nil, creds.TokenSource)
+	})
+	return defaultTokenSource, defaultTokenSourceErr
+}
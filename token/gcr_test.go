@@ -0,0 +1,64 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+type countingTokenSource struct {
+	calls int
+}
+
+func (c *countingTokenSource) Token() (*oauth2.Token, error) {
+	c.calls++
+	if c.calls > 1 {
+		return nil, errors.New("unexpected call")
+	}
+	return &oauth2.Token{AccessToken: "ya29.token"}, nil
+}
+
+func TestGCRGetCredential(t *testing.T) {
+	g := GCR{TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "ya29.token"})}
+	username, password, err := g.GetCredential(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if username != "oauth2accesstoken" {
+		t.Errorf("username: expected %s, got %s", "oauth2accesstoken", username)
+	}
+	if password != "ya29.token" {
+		t.Errorf("password: expected %s, got %s", "ya29.token", password)
+	}
+}
+
+func TestGCRGetCredential_Reuse(t *testing.T) {
+	// A token without the expiry is valid forever
+	ts := &countingTokenSource{}
+	g := GCR{TokenSource: oauth2.ReuseTokenSource(nil, ts)}
+	for i := 0; i < 2; i++ {
+		if _, _, err := g.GetCredential(context.Background()); err != nil {
+			t.Fatalf("%d, unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestIsGCR(t *testing.T) {
+	cases := map[string]bool{
+		"gcr.io":                           true,
+		"https://asia.gcr.io/v2/":          true,
+		"us-central1-docker.pkg.dev":       true,
+		"europe-docker.pkg.dev:443":        true,
+		"evilgcr.io":                       false,
+		"docker.pkg.dev":                   false,
+		"index.docker.io":                  false,
+		"123456789012.dkr.ecr.us-east-1.x": false,
+	}
+	for serverAddress, expected := range cases {
+		if actual := IsGCR(serverAddress); actual != expected {
+			t.Errorf("%s: expected %v, got %v", serverAddress, expected, actual)
+		}
+	}
+}
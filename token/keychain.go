@@ -0,0 +1,51 @@
+package token
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/knqyf263/fanal/auth"
+)
+
+// DockerConfigKeychain resolves the credentials saved by "docker login". See Docker.
+type DockerConfigKeychain struct {
+	// ConfigDir is the directory of config.json. If empty, $DOCKER_CONFIG or ~/.docker is used.
+	ConfigDir string
+}
+
+func (k DockerConfigKeychain) Resolve(registry string) (string, string, error) {
+	d := &Docker{ServerAddress: registry, ConfigDir: k.ConfigDir}
+	return d.GetCredential(context.Background())
+}
+
+// CloudKeychain resolves the credentials of ECR, GCR, Artifact Registry and ACR from the environment.
+// The other registries get no credentials.
+type CloudKeychain struct {
+	// Credential is the credential store of docker-credential-gcr.
+	Credential string
+}
+
+func (k CloudKeychain) Resolve(registry string) (string, string, error) {
+	return cloudCredential(context.Background(), registry, k.Credential)
+}
+
+func cloudCredential(ctx context.Context, serverAddress, credPath string) (string, string, error) {
+	var registry Registry
+	switch {
+	case isECR(serverAddress):
+		registry = NewECRForRegistry(serverAddress)
+	case IsGCR(serverAddress):
+		registry = NewGCR(types.AuthConfig{ServerAddress: serverAddress}, credPath)
+	case IsACR(serverAddress):
+		registry = NewACR(serverAddress)
+	default:
+		return "", "", nil
+	}
+	return registry.GetCredential(ctx)
+}
+
+// DefaultKeychain returns the keychain used without any credentials, which prefers the docker config to the clouds.
+func DefaultKeychain(credPath string) auth.Keychain {
+	return auth.MultiKeychain(DockerConfigKeychain{}, CloudKeychain{Credential: credPath})
+}
@@ -9,7 +9,6 @@ import (
 )
 
 const (
-	ecrURL = "amazonaws.com"
 	gcrURL = "gcr.io"
 )
 
@@ -21,19 +20,30 @@ func GetToken(ctx context.Context, auth types.AuthConfig, credPath string) types
 	if auth.Username != "" || auth.Password != "" {
 		return auth
 	}
-	var registry Registry
-	switch {
-	case strings.HasSuffix(auth.ServerAddress, ecrURL):
-		registry = NewECR()
-	case strings.HasSuffix(auth.ServerAddress, gcrURL):
-		registry = NewGCR(auth, credPath)
-	default:
-		registry = NewDocker()
-	}
+	// The credentials of "docker login" take precedence over the credentials of the clouds
 	var err error
-	auth.Username, auth.Password, err = registry.GetCredential(ctx)
+	auth.Username, auth.Password, err = NewDocker(auth.ServerAddress).GetCredential(ctx)
+	if err != nil {
+		log.Printf("failed to get the credential from the docker config: %s", err)
+	}
+	if auth.Username != "" || auth.Password != "" {
+		return auth
+	}
+
+	auth.Username, auth.Password, err = cloudCredential(ctx, auth.ServerAddress, credPath)
 	if err != nil {
 		log.Printf("failed to get token: %s", err)
 	}
 	return auth
 }
+
+// hostname returns the host without the scheme, the port and the path,
+// e.g. "https://gcr.io/v2/" => "gcr.io"
+func hostname(serverAddress string) string {
+	host := serverAddress
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	host = strings.SplitN(host, "/", 2)[0]
+	return strings.SplitN(host, ":", 2)[0]
+}